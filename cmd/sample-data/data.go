@@ -4,23 +4,35 @@ import (
 	sushi "github.com/sergiorra/sushi-api-go/pkg"
 )
 
+// Sushis is the sample menu, made of the ingredients of dietary.Ingredients
 var Sushis = map[string]sushi.Sushi{
 	"01D3XZ38KDR": sushi.Sushi{
 		ID:    "01D3XZ38KDR",
 		ImageNumber:  "1",
 		Name:   "California Roll",
-		Ingredients: []string {"Crab", "Avocado", "Cucumber", "Sesame seeds"},
+		Ingredients: []string {"crab", "avocado", "cucumber", "sesame_seeds"},
+		Pricing: sushi.Pricing{
+			Price:  &sushi.Money{Amount: 850, Currency: "EUR"},
+			Prices: map[string]sushi.Money{"tokyo": {Amount: 1200, Currency: "JPY"}},
+		},
 	},
 	"01D3XZ38TRE": sushi.Sushi{
 		ID:    "01D3XZ38TRE",
 		ImageNumber:  "2",
 		Name:  "Tiger Roll",
-		Ingredients: []string {"Avocado", "Cucumber", "Tobiko", "Shrimp tempura"},
+		Ingredients: []string {"avocado", "cucumber", "tobiko", "shrimp_tempura"},
+		Pricing: sushi.Pricing{
+			Price: &sushi.Money{Amount: 1150, Currency: "EUR"},
+		},
 	},
 	"01D3XZ38KLE": sushi.Sushi{
 		ID:    "01D3XZ38KLE",
 		ImageNumber:  "3",
 		Name:   "Crunch Roll",
-		Ingredients: []string {"Spicy tuna", "Crispy seaweed", "Tempura"},
+		Ingredients: []string {"spicy_tuna", "crispy_seaweed", "tempura"},
+		Pricing: sushi.Pricing{
+			Price:  &sushi.Money{Amount: 1050, Currency: "EUR"},
+			Prices: map[string]sushi.Money{"tokyo": {Amount: 1500, Currency: "JPY"}},
+		},
 	},
 }
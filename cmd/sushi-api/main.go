@@ -1,91 +1,495 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/sergiorra/sushi-api-go/cmd/sample-data"
 	sushi "github.com/sergiorra/sushi-api-go/pkg"
 	"github.com/sergiorra/sushi-api-go/pkg/adding"
+	"github.com/sergiorra/sushi-api-go/pkg/cache"
+	"github.com/sergiorra/sushi-api-go/pkg/config"
+	"github.com/sergiorra/sushi-api-go/pkg/dietary"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
 	"github.com/sergiorra/sushi-api-go/pkg/getting"
+	"github.com/sergiorra/sushi-api-go/pkg/graphql"
+	"github.com/sergiorra/sushi-api-go/pkg/health"
+	"github.com/sergiorra/sushi-api-go/pkg/idempotency"
+	"github.com/sergiorra/sushi-api-go/pkg/ingredients"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	sushilog "github.com/sergiorra/sushi-api-go/pkg/log"
 	"github.com/sergiorra/sushi-api-go/pkg/log/logrus"
+	"github.com/sergiorra/sushi-api-go/pkg/log/slog"
+	"github.com/sergiorra/sushi-api-go/pkg/metrics"
 	"github.com/sergiorra/sushi-api-go/pkg/modifying"
+	"github.com/sergiorra/sushi-api-go/pkg/ratelimit"
 	"github.com/sergiorra/sushi-api-go/pkg/removing"
+	"github.com/sergiorra/sushi-api-go/pkg/rpc"
+	"github.com/sergiorra/sushi-api-go/pkg/seeding"
 	"github.com/sergiorra/sushi-api-go/pkg/server"
 	"github.com/sergiorra/sushi-api-go/pkg/storage/cockroach"
 	"github.com/sergiorra/sushi-api-go/pkg/storage/inmem"
 	"github.com/sergiorra/sushi-api-go/pkg/storage/mysql"
+	"github.com/sergiorra/sushi-api-go/pkg/storage/redis"
+	"github.com/sergiorra/sushi-api-go/pkg/tlsconfig"
+	"github.com/sergiorra/sushi-api-go/pkg/tracing"
+	"github.com/sergiorra/sushi-api-go/pkg/webhook"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
+	// the seed subcommand shares the configuration of the server
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		seedCommand(os.Args[2:])
+		return
+	}
 
-	var (
-		hostName, _     = os.Hostname()
-		defaultServerID = fmt.Sprintf("%s-%s", os.Getenv("SUSHIAPI_NAME"), hostName)
-		defaultHost     = os.Getenv("SUSHIAPI_SERVER_HOST")
-		defaultPort, _  = strconv.Atoi(os.Getenv("SUSHIAPI_SERVER_PORT"))
-		defaultDB		= "inmem"
-	)
-
-	host := flag.String("host", defaultHost, "define host of the server")
-	port := flag.Int("port", defaultPort, "define port of the server")
-	serverID := flag.String("server-id", defaultServerID, "define server identifier")
-	database := flag.String("database", defaultDB, "initialize the api using the given db engine")
-	flag.Parse()
+	printConfig := flag.Bool("print-config", false, "print the effective configuration, secrets redacted, and exit")
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:], os.LookupEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	if *printConfig {
+		fmt.Print(cfg)
+		return
+	}
+	fmt.Print("Effective configuration:\n", cfg)
 
 	var sushis map[string]sushi.Sushi
-	logger := logrus.NewLogger()
+	logger := newLogger(cfg.Log)
+
+	tracerProvider, err := tracing.NewProvider(context.Background(), tracing.Config{
+		ServiceName: cfg.Server.ID,
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(tracing.Propagator)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	repo, ingredientRepo := initializeRepos(cfg, sushis)
+	repo = tracing.NewRepository(repo, cfg.Database)
+	repo = metrics.NewRepository(repo, cfg.Database, registry)
+	if cfg.Cache.Enabled {
+		repo = cache.NewRepository(repo, registry, newCacheTiers(cfg)...)
+	}
+
+	if pinger, ok := repo.(sushi.Pinger); ok {
+		if err := health.Wait(context.Background(), pinger, health.DefaultBackoff(), logger); err != nil {
+			log.Fatalf("the %s repository is unreachable: %v", cfg.Database, err)
+		}
+	}
+	seedIngredients(context.Background(), ingredientRepo, cfg.Database)
+	catalogue := ingredients.NewCatalogue(ingredientRepo)
+
+	// through the cache, so it doesn't serve what the reset removed
+	if len(cfg.Seed.Fixtures) > 0 {
+		if err := seed(context.Background(), repo, catalogue, cfg.Database, cfg.Seed); err != nil {
+			log.Fatalf("can't seed the %s database: %v", cfg.Database, err)
+		}
+	}
+	var publishers []events.Publisher
+	var dispatcher *webhook.Dispatcher
+	if cfg.Webhooks.Enabled {
+		dispatcher = webhook.NewDispatcher(webhook.Config{
+			Workers:        cfg.Webhooks.Workers,
+			QueueSize:      webhook.DefaultConfig().QueueSize,
+			Timeout:        cfg.Webhooks.Timeout,
+			Attempts:       cfg.Webhooks.Attempts,
+			InitialBackoff: cfg.Webhooks.Backoff,
+			MaxBackoff:     cfg.Webhooks.MaxBackoff,
+			HistorySize:    webhook.DefaultConfig().HistorySize,
+			DeadLetterSize: webhook.DefaultConfig().DeadLetterSize,
+		}, &http.Client{}, logger)
+		publishers = append(publishers, dispatcher)
+	}
+	var bus *events.Bus
+	if cfg.Events.Enabled {
+		bus = events.NewBus(cfg.Events.History, cfg.Events.Buffer)
+		publishers = append(publishers, bus)
+	}
+	publisher := events.NewMultiPublisher(publishers...)
+
+	gS := getting.NewService(repo, logger, getting.WithIngredients(catalogue))
+	aS := adding.NewService(repo, logger, publisher, adding.WithIngredients(catalogue))
+	// the edit locks are in process memory, like the editors connections
+	locker := locking.NewMemoryLocker()
+	mS := modifying.NewService(repo, logger, publisher, locker, modifying.WithIngredients(catalogue))
+	rS := removing.NewService(repo, logger, publisher)
+	iS := ingredients.NewService(catalogue, repo, logger, publisher)
+
+	httpAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+
+	proxies, err := parseCIDRs(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := []server.Option{
+		server.WithLogger(logger),
+		server.WithTrustedProxies(proxies),
+		server.WithAPIKeys(cfg.Server.APIKeys),
+		server.WithMetrics(registry),
+		server.WithIngredients(iS),
+	}
+	// already validated with the rest of the configuration
+	cacheControl, _ := server.ParseCacheControl(cfg.Server.CacheControl)
+	opts = append(opts, server.WithCacheControl(cacheControl))
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		opts = append(opts, server.WithCORS(server.CORSPolicy{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			ExposedHeaders:   cfg.CORS.ExposedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+		}))
+	}
+	if cfg.Compression.Enabled {
+		opts = append(opts, server.WithCompression(cfg.Compression.MinSize))
+	}
+	if pinger, ok := repo.(sushi.Pinger); ok {
+		opts = append(opts, server.WithReadinessCheck("repository", pinger))
+	}
 
-	repo := initializeRepo(database, sushis)
-	gS := getting.NewService(repo, logger)
-	aS := adding.NewService(repo)
-	mS := modifying.NewService(repo)
-	rS := removing.NewService(repo)
+	if cfg.RateLimit.Default != "" || cfg.RateLimit.Routes != "" {
+		opts = append(opts, newRateLimit(cfg.RateLimit, cfg.Redis))
+	}
+	if cfg.Idempotency.Enabled {
+		opts = append(opts, newIdempotency(cfg.Idempotency, cfg.Redis))
+	}
+	if dispatcher != nil {
+		opts = append(opts, server.WithWebhooks(dispatcher))
+	}
+	if bus != nil {
+		opts = append(opts, server.WithEventStream(bus, cfg.Events.Heartbeat))
+	}
+	if cfg.WebSocket.Enabled {
+		opts = append(opts, server.WithWebSocket(bus, locker, cfg.WebSocket.LockTTL))
+	}
 
-	httpAddr := fmt.Sprintf("%s:%d", *host, *port)
+	if cfg.GraphQL.Enabled {
+		// without the events there are no subscriptions
+		schema, err := graphql.NewSchema(gS, aS, mS, rS, bus, graphql.Limits{
+			MaxDepth:      cfg.GraphQL.MaxDepth,
+			MaxComplexity: cfg.GraphQL.MaxComplexity,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, server.WithGraphQL(schema))
+	}
 
-	s := server.New(*serverID, gS, aS, mS, rS)
+	s := server.New(cfg.Server.ID, gS, aS, mS, rS, opts...)
 
-	fmt.Println("The sushi server is on tap now:", httpAddr)
-	log.Fatal(http.ListenAndServe(httpAddr, s.Router()))
+	httpServer := &http.Server{
+		Addr:              httpAddr,
+		Handler:           s.Router(),
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+	// the event streams and the websockets never go idle, they must end for
+	// the shutdown to drain
+	if bus != nil {
+		httpServer.RegisterOnShutdown(bus.Close)
+	}
+
+	ctx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+
+	serveErr := make(chan error, 3)
+	var redirectServer *http.Server
+	if cfg.TLS.Cert != "" {
+		reloader, err := tlsconfig.NewReloader(cfg.TLS.Cert, cfg.TLS.Key, cfg.TLS.ClientCA)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go reloader.Watch(ctx, cfg.TLS.ReloadInterval, func(err error) { logger.UnexpectedError(ctx, err) })
+		httpServer.TLSConfig = reloader.Config(parseClientAuth(cfg.TLS.ClientAuth))
+
+		if cfg.TLS.RedirectHTTPAddr != "" {
+			redirectServer = &http.Server{
+				Addr:              cfg.TLS.RedirectHTTPAddr,
+				Handler:           server.RedirectToHTTPS(cfg.Server.Port),
+				ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+			}
+			go func() { serveErr <- redirectServer.ListenAndServe() }()
+		}
+
+		go func() {
+			fmt.Println("The sushi server is on tap now: https://" + httpAddr)
+			serveErr <- httpServer.ListenAndServeTLS("", "")
+		}()
+	} else {
+		go func() {
+			fmt.Println("The sushi server is on tap now:", httpAddr)
+			serveErr <- httpServer.ListenAndServe()
+		}()
+	}
+
+	var grpcServer *rpc.Server
+	if cfg.GRPC.Enabled {
+		var grpcOpts []grpc.ServerOption
+		if httpServer.TLSConfig != nil {
+			grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(httpServer.TLSConfig)))
+		}
+		grpcServer = rpc.NewServer(gS, aS, mS, rS, bus, logger, grpcOpts...)
+
+		grpcAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.GRPC.Port)
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go func() {
+			fmt.Println("The sushi gRPC server is on tap now:", grpcAddr)
+			serveErr <- grpcServer.Serve(listener)
+		}()
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serveErr:
+		log.Fatal(err)
+	case <-stop:
+	}
 
+	// stop taking traffic first, then drain the in-flight requests
+	s.SetReady(false)
+	if grpcServer != nil {
+		grpcServer.Drain()
+	}
+	time.Sleep(cfg.Server.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if redirectServer != nil {
+		_ = redirectServer.Shutdown(shutdownCtx)
+	}
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.UnexpectedError(shutdownCtx, err)
+		_ = httpServer.Close()
+	}
+	// the event bus is closed with the http server, ending the watches
+	if grpcServer != nil {
+		grpcServer.Shutdown(shutdownCtx)
+	}
+	// the writes are over, deliver what they produced before leaving
+	if dispatcher != nil {
+		if err := dispatcher.Close(shutdownCtx); err != nil {
+			logger.UnexpectedError(shutdownCtx, err)
+		}
+	}
+	if closer, ok := repo.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.UnexpectedError(shutdownCtx, err)
+		}
+	}
+	if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+		logger.UnexpectedError(shutdownCtx, err)
+	}
+	if syncer, ok := logger.(sushilog.Syncer); ok {
+		_ = syncer.Sync()
+	}
+}
+
+func parseClientAuth(clientAuth string) tls.ClientAuthType {
+	if clientAuth == "require" {
+		return tls.RequireAndVerifyClientCert
+	}
+	return tls.VerifyClientCertIfGiven
 }
 
-func initializeRepo(database *string, sushis map[string]sushi.Sushi) sushi.Repository {
-	var repo sushi.Repository
-	switch *database {
+// newLogger builds the configured logger, the config is already validated
+func newLogger(cfg config.LogConfig) sushilog.Logger {
+	logCfg := sushilog.DefaultConfig()
+	logCfg.Level, _ = sushilog.ParseLevel(cfg.Level)
+	logCfg.Format, _ = sushilog.ParseFormat(cfg.Format)
+
+	if cfg.Logger == "slog" {
+		return slog.NewLogger(logCfg)
+	}
+	return logrus.NewLogger(logCfg)
+}
+
+// initializeRepos returns the sushi repository and the ingredient catalogue of
+// the database, both on the same connection
+func initializeRepos(cfg config.Config, sushis map[string]sushi.Sushi) (sushi.Repository, sushi.IngredientRepository) {
+	switch cfg.Database {
 	case "cockroach":
-		repo = newCockroachRepository()
+		cockroachConn, err := cockroach.NewConn(cfg.Cockroach.Addr, cfg.Cockroach.DB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return cockroach.NewRepository(cockroachConn), cockroach.NewIngredientRepository(cockroachConn)
 	case "mysql":
-		repo = newMySQLRepository()
+		mysqlConn, err := mysql.NewConn(cfg.MySQL.Addr, cfg.MySQL.DB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return mysql.NewRepository(cfg.MySQL.Table, mysqlConn), mysql.NewIngredientRepository(cfg.MySQL.IngredientTable, cfg.MySQL.Table, mysqlConn)
+	case "redis":
+		pool := redis.NewConn(cfg.Redis.Addr)
+		return redis.NewRepository(pool), redis.NewIngredientRepository(pool)
 	default:
-		repo = inmem.NewRepository(sushis)
+		return inmem.NewRepository(sushis), inmem.NewIngredientRepository(dietary.Ingredients())
+	}
+}
+
+// seedIngredients fills the catalogue with the usual ingredients of the menu
+// while it's empty, once the database is reachable
+func seedIngredients(ctx context.Context, catalogue sushi.IngredientRepository, database string) {
+	if _, err := seeding.SeedIngredients(ctx, catalogue, dietary.Ingredients()); err != nil {
+		log.Fatalf("can't seed the ingredients of the %s database: %v", database, err)
 	}
-	return repo
 }
 
-func newCockroachRepository() sushi.Repository {
-	cockroachAddr := os.Getenv("COCKROACH_ADDR")
-	cockroachDBName := os.Getenv("COCKROACH_DB")
+// sampleFixture names the sample menu among the fixture files
+const sampleFixture = "sample"
 
-	cockroachConn, err := cockroach.NewConn(cockroachAddr, cockroachDBName)
+// seedCommand loads the fixtures given as arguments, the sample menu when
+// there are none, into the configured database
+func seedCommand(args []string) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: sushi-api seed [flags] [fixture...]\n\n"+
+			"Loads the JSON or YAML fixture files, or %s for the sample menu, into the database.\n"+
+			"The sushis already there are kept, -seed-reset removes them first.\n\nflags:\n", sampleFixture)
+		fs.PrintDefaults()
+	}
+	cfg, err := config.Load(fs, args, os.LookupEnv)
 	if err != nil {
 		log.Fatal(err)
 	}
-	return cockroach.NewRepository(cockroachConn)
+	if fs.NArg() > 0 {
+		cfg.Seed.Fixtures = fs.Args()
+	}
+	if len(cfg.Seed.Fixtures) == 0 {
+		cfg.Seed.Fixtures = []string{sampleFixture}
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	if cfg.Database == "inmem" {
+		log.Fatal("the inmem database lives in the server, seed it at startup with -seed")
+	}
+
+	ctx := context.Background()
+	repo, catalogue := initializeRepos(cfg, nil)
+	if pinger, ok := repo.(sushi.Pinger); ok {
+		if err := health.Wait(ctx, pinger, health.DefaultBackoff(), newLogger(cfg.Log)); err != nil {
+			log.Fatalf("the %s repository is unreachable: %v", cfg.Database, err)
+		}
+	}
+	seedIngredients(ctx, catalogue, cfg.Database)
+	err = seed(ctx, repo, catalogue, cfg.Database, cfg.Seed)
+	// the catalogue shares the connection of the repository
+	if closer, ok := repo.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	if err != nil {
+		log.Fatalf("can't seed the %s database: %v", cfg.Database, err)
+	}
+}
+
+// seed loads the fixture files into the repository, sampleFixture being the
+// sample menu. Their ingredients must be in the catalogue.
+func seed(ctx context.Context, repo sushi.Repository, catalogue sushi.IngredientRepository, database string, cfg config.SeedConfig) error {
+	var fixtures []sushi.Sushi
+	for _, file := range cfg.Fixtures {
+		if file == sampleFixture {
+			fixtures = append(fixtures, seeding.FromMap(sample.Sushis)...)
+			continue
+		}
+		loaded, err := seeding.LoadFile(file)
+		if err != nil {
+			return err
+		}
+		fixtures = append(fixtures, loaded...)
+	}
+
+	result, err := seeding.Seed(ctx, repo, catalogue, fixtures, cfg.Reset)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Seeded the %s database: %d sushis created, %d already there, %d removed\n",
+		database, result.Created, result.Skipped, result.Removed)
+	return nil
+}
+
+func newCacheTiers(cfg config.Config) []cache.Tier {
+	tiers := []cache.Tier{cache.NewLRU(cfg.Cache.Size, cfg.Cache.TTL)}
+	if cfg.Cache.Redis {
+		tiers = append(tiers, cache.NewRedis(redis.NewConn(cfg.Redis.Addr), redis.CacheKeyPrefix, cfg.Cache.RedisTTL))
+	}
+	return tiers
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
 }
 
-func newMySQLRepository() sushi.Repository {
-	mysqlAddr := os.Getenv("MYSQL_ADDR")
-	mysqlDBName := os.Getenv("MYSQL_DB")
+func newRateLimit(cfg config.RateLimitConfig, redisCfg config.RedisConfig) server.Option {
+	var limit ratelimit.Limit
+	if cfg.Default != "" {
+		var err error
+		if limit, err = ratelimit.ParseLimit(cfg.Default); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	mysqlConn, err := mysql.NewConn(mysqlAddr, mysqlDBName)
+	policy, err := ratelimit.ParsePolicy(limit, cfg.Routes)
 	if err != nil {
 		log.Fatal(err)
 	}
-	return mysql.NewRepository("gophers", mysqlConn)
-}
\ No newline at end of file
+
+	if cfg.Store == "redis" {
+		pool := redis.NewConn(redisCfg.Addr)
+		return server.WithRateLimit(ratelimit.NewRedisStore(pool, redis.RateLimitKeyPrefix), policy)
+	}
+	return server.WithRateLimit(ratelimit.NewMemoryStore(), policy)
+}
+
+func newIdempotency(cfg config.IdempotencyConfig, redisCfg config.RedisConfig) server.Option {
+	if cfg.Store == "redis" {
+		pool := redis.NewConn(redisCfg.Addr)
+		return server.WithIdempotency(idempotency.NewRedisStore(pool, redis.IdempotencyKeyPrefix), cfg.TTL, int64(cfg.MaxBody))
+	}
+	return server.WithIdempotency(idempotency.NewMemoryStore(), cfg.TTL, int64(cfg.MaxBody))
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/client"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+)
+
+func (a *app) list(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("list")
+	format := fs.String("o", "table", "output format: table, json or yaml")
+	var opts []client.ListOption
+	fs.Func("price-min", "only the sushis costing at least this amount, in minor units of the -currency", func(value string) error {
+		amount, err := strconv.ParseInt(value, 10, 64)
+		opts = append(opts, client.PriceMin(amount))
+		return err
+	})
+	fs.Func("price-max", "only the sushis costing at most this amount, in minor units of the -currency", func(value string) error {
+		amount, err := strconv.ParseInt(value, 10, 64)
+		opts = append(opts, client.PriceMax(amount))
+		return err
+	})
+	fs.Func("currency", "only the sushis priced in this currency", func(value string) error {
+		opts = append(opts, client.Currency(value))
+		return nil
+	})
+	fs.Func("restaurant", "compare the prices of this restaurant", func(value string) error {
+		opts = append(opts, client.Restaurant(value))
+		return nil
+	})
+	fs.Func("exclude-allergens", "leave out the sushis containing any of these comma separated allergens", func(value string) error {
+		var allergens []sushi.Allergen
+		for _, name := range splitList(value) {
+			allergens = append(allergens, sushi.Allergen(name))
+		}
+		opts = append(opts, client.ExcludeAllergens(allergens...))
+		return nil
+	})
+	fs.Func("diet", "only the sushis labelled with all these comma separated diets", func(value string) error {
+		var diets []sushi.Diet
+		for _, name := range splitList(value) {
+			diets = append(diets, sushi.Diet(name))
+		}
+		opts = append(opts, client.Diet(diets...))
+		return nil
+	})
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+	printer, err := newPrinter(*format)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	sushis, err := c.List(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	sort.Slice(sushis, func(i, j int) bool { return sushis[i].ID < sushis[j].ID })
+	return printer.list(a.stdout, sushis)
+}
+
+func (a *app) get(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("get")
+	format := fs.String("o", "table", "output format: table, json or yaml")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+	printer, err := newPrinter(*format)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	s, err := c.Get(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printer.one(a.stdout, *s)
+}
+
+func (a *app) add(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("add")
+	file := fs.String("f", "", "JSON or YAML file of the sushi, - for stdin")
+	var s sushi.Sushi
+	fs.StringVar(&s.ID, "id", "", "ID of the sushi")
+	fs.StringVar(&s.Name, "name", "", "name of the sushi")
+	fs.StringVar(&s.ImageNumber, "image", "", "number of the image of the sushi")
+	ingredients := fs.String("ingredients", "", "comma separated ingredients")
+	price := fs.Int64("price", -1, "base price of the sushi, in minor units of the currency")
+	currency := fs.String("currency", "", "ISO 4217 code of the currency of the price")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || (*price >= 0) != (*currency != "") {
+		return errUsage
+	}
+
+	fromFlags := s.ID != "" || s.Name != "" || s.ImageNumber != "" || *ingredients != "" || *currency != ""
+	switch {
+	case *file != "" && fromFlags:
+		return errUsage
+	case *file != "":
+		var err error
+		if s, err = a.readSushi(*file); err != nil {
+			return err
+		}
+	case fromFlags:
+		s.Ingredients = splitList(*ingredients)
+		if *currency != "" {
+			s.Price = &sushi.Money{Amount: *price, Currency: *currency}
+		}
+	default:
+		return errUsage
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	if err := c.Add(ctx, s); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Sushi %s added\n", s.ID)
+	return nil
+}
+
+// readSushi decodes the sushi of the file, JSON being YAML too
+func (a *app) readSushi(path string) (sushi.Sushi, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(a.stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return sushi.Sushi{}, err
+	}
+
+	// through JSON, so the fields are named as in the API
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return sushi.Sushi{}, fmt.Errorf("invalid sushi file: %w", err)
+	}
+	data, err = json.Marshal(document)
+	if err != nil {
+		return sushi.Sushi{}, fmt.Errorf("invalid sushi file: %w", err)
+	}
+	return decodeSushi(data)
+}
+
+func decodeSushi(data []byte) (sushi.Sushi, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var s sushi.Sushi
+	if err := decoder.Decode(&s); err != nil {
+		return sushi.Sushi{}, fmt.Errorf("invalid sushi: %w", err)
+	}
+	return s, nil
+}
+
+func (a *app) edit(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("edit")
+	lockToken := fs.String("lock-token", "", "token of the edit lock held on the sushi")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	s, err := c.Get(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	// the labels are computed from the ingredients, they can't be edited
+	s.Labels = nil
+	original, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	original = append(original, '\n')
+
+	f, err := os.CreateTemp("", "sushictl-"+s.ID+"-*.json")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	_, err = f.Write(original)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if err := a.openEditor(path); err != nil {
+		os.Remove(path)
+		return err
+	}
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(original)) {
+		os.Remove(path)
+		fmt.Fprintln(a.stdout, "Edit cancelled, no changes made")
+		return nil
+	}
+
+	// the file is kept when the changes are rejected, they would be lost
+	// otherwise
+	modified, err := decodeSushi(edited)
+	if err == nil && modified.ID != s.ID {
+		err = errors.New("the ID of a sushi can't be changed")
+	}
+	if err == nil {
+		err = c.Modify(locking.WithToken(ctx, *lockToken), s.ID, modified)
+	}
+	if err != nil {
+		return fmt.Errorf("%w, the changes are kept in %s", err, path)
+	}
+	os.Remove(path)
+	fmt.Fprintf(a.stdout, "Sushi %s modified\n", s.ID)
+	return nil
+}
+
+func (a *app) remove(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	for _, ID := range args {
+		if err := c.Remove(ctx, ID); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "Sushi %s removed\n", ID)
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
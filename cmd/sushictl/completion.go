@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// completeCommand is the hidden command the completion scripts call for the
+// values only the API or the profiles file know
+const completeCommand = "__complete"
+
+func (a *app) completion(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	script, ok := map[string]string{"bash": bashCompletion, "zsh": zshCompletion, "fish": fishCompletion}[args[0]]
+	if !ok {
+		return errUsage
+	}
+	_, err := fmt.Fprint(a.stdout, script)
+	return err
+}
+
+// complete prints the candidates of the kind, one per line. The failures
+// print nothing, the shell has no use for them.
+func (a *app) complete(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	var candidates []string
+	switch args[0] {
+	case "ids":
+		c, err := a.client()
+		if err != nil {
+			return nil
+		}
+		for s, err := range c.Stream(ctx) {
+			if err != nil {
+				return nil
+			}
+			candidates = append(candidates, s.ID)
+		}
+		sort.Strings(candidates)
+	case "profiles":
+		profiles, err := a.loadProfiles()
+		if err != nil {
+			return nil
+		}
+		candidates = sortedKeys(profiles.Profiles)
+	default:
+		return errUsage
+	}
+
+	for _, candidate := range candidates {
+		fmt.Fprintln(a.stdout, candidate)
+	}
+	return nil
+}
+
+// the global flags taking a value are skipped to find the command, and given
+// to the completions needing the API so they reach the same server
+const bashCompletion = `# bash completion of sushictl, load it with
+#   source <(sushictl completion bash)
+_sushictl() {
+	local cur prev cmd="" i
+	local -a globals=()
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case "${COMP_WORDS[i]}" in
+		-config|-profile|-url|-api-key|-token|-timeout)
+			globals+=("${COMP_WORDS[i]}" "${COMP_WORDS[i+1]}")
+			((i++))
+			;;
+		-*)
+			globals+=("${COMP_WORDS[i]}")
+			;;
+		*)
+			cmd="${COMP_WORDS[i]}"
+			break
+			;;
+		esac
+	done
+
+	case "$prev" in
+	-o)
+		COMPREPLY=($(compgen -W "table json yaml" -- "$cur"))
+		return
+		;;
+	-profile)
+		COMPREPLY=($(compgen -W "$(sushictl "${globals[@]}" __complete profiles 2>/dev/null)" -- "$cur"))
+		return
+		;;
+	-f|-config|-ca-file|-cert-file|-key-file)
+		COMPREPLY=($(compgen -f -- "$cur"))
+		return
+		;;
+	esac
+
+	case "$cmd" in
+	"")
+		COMPREPLY=($(compgen -W "list get add edit remove profile completion" -- "$cur"))
+		;;
+	get|edit|remove)
+		COMPREPLY=($(compgen -W "$(sushictl "${globals[@]}" __complete ids 2>/dev/null)" -- "$cur"))
+		;;
+	profile)
+		if [[ $COMP_CWORD -eq $((i + 1)) ]]; then
+			COMPREPLY=($(compgen -W "list use set remove" -- "$cur"))
+		elif [[ $COMP_CWORD -eq $((i + 2)) && "${COMP_WORDS[i+1]}" != list ]]; then
+			COMPREPLY=($(compgen -W "$(sushictl "${globals[@]}" __complete profiles 2>/dev/null)" -- "$cur"))
+		fi
+		;;
+	completion)
+		COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur"))
+		;;
+	esac
+}
+complete -o default -F _sushictl sushictl
+`
+
+// zsh runs the bash completion
+const zshCompletion = `# zsh completion of sushictl, load it with
+#   source <(sushictl completion zsh)
+autoload -U +X bashcompinit && bashcompinit
+` + bashCompletion
+
+const fishCompletion = `# fish completion of sushictl, load it with
+#   sushictl completion fish | source
+function __sushictl_no_command
+	for word in (commandline -opc)[2..-1]
+		if contains -- $word list get add edit remove profile completion
+			return 1
+		end
+	end
+	return 0
+end
+
+complete -c sushictl -f
+complete -c sushictl -o config -r -F -d "profiles file"
+complete -c sushictl -o profile -x -a "(sushictl __complete profiles 2>/dev/null)" -d "profile to use"
+complete -c sushictl -o url -x -d "URL of the API"
+complete -c sushictl -o api-key -x -d "API key"
+complete -c sushictl -o token -x -d "bearer token"
+complete -c sushictl -o timeout -x -d "timeout of every request"
+
+complete -c sushictl -n __sushictl_no_command -a list -d "list the sushis"
+complete -c sushictl -n __sushictl_no_command -a get -d "show a sushi"
+complete -c sushictl -n __sushictl_no_command -a add -d "add a sushi"
+complete -c sushictl -n __sushictl_no_command -a edit -d "edit the JSON of a sushi"
+complete -c sushictl -n __sushictl_no_command -a remove -d "remove sushis"
+complete -c sushictl -n __sushictl_no_command -a profile -d "manage the profiles"
+complete -c sushictl -n __sushictl_no_command -a completion -d "print the completion script"
+
+complete -c sushictl -n "__fish_seen_subcommand_from list get" -o o -x -a "table json yaml" -d "output format"
+complete -c sushictl -n "__fish_seen_subcommand_from get edit remove; and not __fish_seen_subcommand_from profile" -a "(sushictl __complete ids 2>/dev/null)"
+complete -c sushictl -n "__fish_seen_subcommand_from list" -o price-min -x -d "minimum price in minor units"
+complete -c sushictl -n "__fish_seen_subcommand_from list" -o price-max -x -d "maximum price in minor units"
+complete -c sushictl -n "__fish_seen_subcommand_from list add" -o currency -x -d "ISO 4217 currency"
+complete -c sushictl -n "__fish_seen_subcommand_from list" -o restaurant -x -d "restaurant of the prices"
+complete -c sushictl -n "__fish_seen_subcommand_from list" -o exclude-allergens -x -a "gluten crustaceans eggs fish peanuts soybeans milk nuts celery mustard sesame sulphites lupin molluscs" -d "allergens to leave out"
+complete -c sushictl -n "__fish_seen_subcommand_from list" -o diet -x -a "vegetarian vegan gluten-free raw-fish" -d "diets to keep"
+complete -c sushictl -n "__fish_seen_subcommand_from add" -o f -r -F -d "sushi file"
+complete -c sushictl -n "__fish_seen_subcommand_from add" -o price -x -d "base price in minor units"
+complete -c sushictl -n "__fish_seen_subcommand_from profile; and not __fish_seen_subcommand_from list use set remove" -a "list use set remove"
+complete -c sushictl -n "__fish_seen_subcommand_from profile; and __fish_seen_subcommand_from use set remove" -a "(sushictl __complete profiles 2>/dev/null)"
+complete -c sushictl -n "__fish_seen_subcommand_from completion" -a "bash zsh fish"
+`
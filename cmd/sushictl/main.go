@@ -0,0 +1,179 @@
+// Command sushictl manages the sushi menu through the API, the servers being
+// chosen with profiles
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	configEnv  = "SUSHICTL_CONFIG"
+	profileEnv = "SUSHICTL_PROFILE"
+	urlEnv     = "SUSHICTL_URL"
+	apiKeyEnv  = "SUSHICTL_API_KEY"
+	tokenEnv   = "SUSHICTL_TOKEN"
+
+	defaultURL = "http://localhost:3000"
+)
+
+// errUsage is returned by the commands called with wrong arguments, their
+// usage is printed
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(a *app, ctx context.Context, args []string) error
+	// hidden commands are left out of the usage
+	hidden bool
+}
+
+var commands = []command{
+	{name: "list", args: "[-o table|json|yaml] [-price-min N] [-price-max N] [-currency CODE] [-restaurant ID] [-exclude-allergens LIST] [-diet LIST]", summary: "list the sushis, the prices in minor units", run: (*app).list},
+	{name: "get", args: "[-o table|json|yaml] ID", summary: "show a sushi", run: (*app).get},
+	{name: "add", args: "-f FILE | -id ID -name NAME [-image N] [-ingredients A,B] [-price N -currency CODE]", summary: "add a sushi, - reads the JSON or YAML file from stdin", run: (*app).add},
+	{name: "edit", args: "[-lock-token TOKEN] ID", summary: "edit the JSON of a sushi with $VISUAL or $EDITOR", run: (*app).edit},
+	{name: "remove", args: "ID...", summary: "remove sushis", run: (*app).remove},
+	{name: "profile", args: "list | use NAME | set NAME [flags] | remove NAME", summary: "manage the profiles of the servers", run: (*app).profile},
+	{name: "completion", args: "bash|zsh|fish", summary: "print the shell completion script", run: (*app).completion},
+	{name: completeCommand, run: (*app).complete, hidden: true},
+}
+
+// app holds what the commands share, the streams are replaced by the tests
+type app struct {
+	stdin     io.Reader
+	stdout    io.Writer
+	stderr    io.Writer
+	lookupEnv func(string) (string, bool)
+	// openEditor lets the user edit the file
+	openEditor func(path string) error
+
+	// the global flags
+	configFile  string
+	profileName string
+	url         string
+	apiKey      string
+	token       string
+	timeout     time.Duration
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	a := &app{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, lookupEnv: os.LookupEnv}
+	a.openEditor = a.runEditor
+	os.Exit(a.run(ctx, os.Args[1:]))
+}
+
+// run executes the command line and returns the exit code
+func (a *app) run(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("sushictl", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.StringVar(&a.configFile, "config", "", "profiles file (env "+configEnv+")")
+	fs.StringVar(&a.profileName, "profile", "", "profile to use instead of the current one (env "+profileEnv+")")
+	fs.StringVar(&a.url, "url", "", "URL of the API, overriding the profile (env "+urlEnv+")")
+	fs.StringVar(&a.apiKey, "api-key", "", "API key, overriding the profile (env "+apiKeyEnv+")")
+	fs.StringVar(&a.token, "token", "", "bearer token, overriding the profile (env "+tokenEnv+")")
+	fs.DurationVar(&a.timeout, "timeout", 30*time.Second, "timeout of every request")
+	fs.Usage = func() { a.usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() == 0 {
+		a.usage(fs)
+		return 2
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != fs.Arg(0) {
+			continue
+		}
+		err := cmd.run(a, ctx, fs.Args()[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(a.stderr, "usage: sushictl %s %s\n", cmd.name, cmd.args)
+			return 2
+		}
+		fmt.Fprintln(a.stderr, "sushictl:", err)
+		return 1
+	}
+	fmt.Fprintf(a.stderr, "sushictl: unknown command %q\n", fs.Arg(0))
+	a.usage(fs)
+	return 2
+}
+
+func (a *app) usage(fs *flag.FlagSet) {
+	fmt.Fprint(a.stderr, "usage: sushictl [flags] command [arguments]\n\ncommands:\n")
+	for _, cmd := range commands {
+		if !cmd.hidden {
+			fmt.Fprintf(a.stderr, "  %-11s %s\n", cmd.name, cmd.summary)
+		}
+	}
+	fmt.Fprint(a.stderr, "\nflags:\n")
+	fs.PrintDefaults()
+}
+
+// newFlagSet creates the flag set of a command, the errors are reported by run
+func (a *app) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
+
+// parseFlags turns the parse errors into errUsage
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// runEditor opens the file with $VISUAL or $EDITOR, vi when none is set. The
+// variables may carry arguments, like "code --wait".
+func (a *app) runEditor(path string) error {
+	editor := "vi"
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if value, ok := a.lookupEnv(env); ok && strings.TrimSpace(value) != "" {
+			editor = value
+			break
+		}
+	}
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("the editor failed: %w", err)
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	"github.com/sergiorra/sushi-api-go/pkg/server"
+	"github.com/sergiorra/sushi-api-go/pkg/servicetest"
+)
+
+type fixture struct {
+	url    string
+	config string
+	locker locking.Locker
+	// edit replaces the editor, it gets the content of the file and returns
+	// the edited one
+	edit func(string) string
+	env  map[string]string
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	services := servicetest.New(map[string]sushi.Sushi{
+		"nigiri": {ID: "nigiri", ImageNumber: "1", Name: "Salmon nigiri", Ingredients: []string{"Rice", "Salmon"},
+			Pricing: sushi.Pricing{Price: &sushi.Money{Amount: 450, Currency: "EUR"}, Prices: map[string]sushi.Money{"tokyo": {Amount: 600, Currency: "JPY"}}}},
+		"uramaki": {ID: "uramaki", ImageNumber: "2", Name: "California uramaki", Ingredients: []string{"Rice", "Crab"}},
+	})
+	f := &fixture{
+		config: filepath.Join(t.TempDir(), "config.yaml"),
+		locker: services.Locker,
+		env:    map[string]string{},
+	}
+	s := server.New("test", services.Getting, services.Adding, services.Modifying, services.Removing)
+	srv := httptest.NewServer(s.Router())
+	t.Cleanup(srv.Close)
+	f.url = srv.URL
+	f.env[configEnv] = f.config
+	f.env[urlEnv] = srv.URL
+	return f
+}
+
+// run executes the command line, returning the exit code and the outputs
+func (f *fixture) run(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	a := &app{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		lookupEnv: func(key string) (string, bool) {
+			value, ok := f.env[key]
+			return value, ok
+		},
+		openEditor: func(path string) error {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(path, []byte(f.edit(string(data))), 0o600)
+		},
+	}
+	code := a.run(context.Background(), args)
+	return code, stdout.String(), stderr.String()
+}
+
+func Test_List(t *testing.T) {
+	f := newFixture(t)
+
+	code, out, _ := f.run("", "list")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, ""+
+		"ID       NAME                IMAGE  PRICE     INGREDIENTS\n"+
+		"nigiri   Salmon nigiri       1      4.50 EUR  Rice, Salmon\n"+
+		"uramaki  California uramaki  2      -         Rice, Crab\n", out)
+
+	code, out, _ = f.run("", "list", "-o", "json")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `[
+		{"id":"nigiri","imageNumber":"1","name":"Salmon nigiri","ingredients":["Rice","Salmon"],"price":{"amount":450,"currency":"EUR"},"prices":{"tokyo":{"amount":600,"currency":"JPY"}},"labels":{"allergens":["fish"],"diets":["gluten-free","raw-fish"]}},
+		{"id":"uramaki","imageNumber":"2","name":"California uramaki","ingredients":["Rice","Crab"],"labels":{"allergens":["crustaceans"],"diets":["gluten-free"]}}
+	]`, out)
+
+	code, out, _ = f.run("", "get", "-o", "yaml", "uramaki")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, ""+
+		"id: uramaki\nimageNumber: \"2\"\nname: California uramaki\ningredients:\n  - Rice\n  - Crab\n"+
+		"labels:\n  allergens:\n    - crustaceans\n  diets:\n    - gluten-free\n", out)
+
+	code, _, errOut := f.run("", "list", "-o", "xml")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, `unknown output format "xml"`)
+}
+
+func Test_List_Prices(t *testing.T) {
+	f := newFixture(t)
+
+	code, out, _ := f.run("", "list", "-price-max", "500", "-currency", "EUR")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "nigiri")
+	assert.NotContains(t, out, "uramaki")
+
+	code, out, _ = f.run("", "list", "-restaurant", "tokyo", "-price-min", "500", "-currency", "JPY")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "nigiri")
+
+	code, out, _ = f.run("", "list", "-restaurant", "tokyo", "-currency", "EUR")
+	assert.Equal(t, 0, code)
+	assert.NotContains(t, out, "nigiri")
+
+	code, _, errOut := f.run("", "list", "-price-min", "500", "-price-max", "100")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "priceMin can't exceed priceMax")
+
+	code, _, errOut = f.run("", "list", "-price-max", "500")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "the price bounds require a currency")
+
+	code, _, _ = f.run("", "list", "-price-min", "cheap")
+	assert.Equal(t, 2, code)
+}
+
+func Test_List_Labels(t *testing.T) {
+	f := newFixture(t)
+
+	code, out, _ := f.run("", "list", "-exclude-allergens", "fish,sesame")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "uramaki")
+	assert.NotContains(t, out, "nigiri")
+
+	code, out, _ = f.run("", "list", "-diet", "gluten-free,raw-fish")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "nigiri")
+	assert.NotContains(t, out, "uramaki")
+
+	code, _, errOut := f.run("", "list", "-exclude-allergens", "onions")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, `unknown allergen "onions"`)
+}
+
+func Test_Get_NotFound(t *testing.T) {
+	f := newFixture(t)
+
+	code, _, errOut := f.run("", "get", "futomaki")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "sushictl: sushi api: 404 Sushi Not found (request ")
+}
+
+func Test_Add(t *testing.T) {
+	f := newFixture(t)
+
+	code, out, _ := f.run("", "add", "-id", "hosomaki", "-name", "Hosomaki", "-ingredients", "Rice, Cucumber")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Sushi hosomaki added\n", out)
+
+	code, out, _ = f.run("id: temaki\nname: Tuna temaki\nimageNumber: \"5\"\ningredients: [Rice, Tuna]\nprices:\n  tokyo: {amount: 500, currency: JPY}\n", "add", "-f", "-")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Sushi temaki added\n", out)
+
+	code, out, _ = f.run("", "get", "-o", "json", "temaki")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `{"id":"temaki","imageNumber":"5","name":"Tuna temaki","ingredients":["Rice","Tuna"],"prices":{"tokyo":{"amount":500,"currency":"JPY"}},"labels":{"allergens":["fish"],"diets":["gluten-free","raw-fish"]}}`, out)
+
+	code, _, _ = f.run("", "add", "-id", "futomaki", "-name", "Futomaki", "-price", "700", "-currency", "EUR")
+	assert.Equal(t, 0, code)
+	code, out, _ = f.run("", "get", "futomaki")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "7.00 EUR")
+
+	code, _, errOut := f.run("", "add", "-id", "futomaki", "-name", "Futomaki", "-price", "700")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "usage: sushictl add")
+
+	code, _, errOut = f.run("", "add", "-id", "hosomaki", "-name", "Hosomaki", "-price", "300", "-currency", "euro")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, `must be an ISO 4217 code`)
+
+	code, _, errOut = f.run("", "add", "-f", "-", "-id", "hosomaki")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "usage: sushictl add")
+}
+
+func Test_Edit(t *testing.T) {
+	f := newFixture(t)
+
+	f.edit = func(content string) string { return strings.Replace(content, "Salmon nigiri", "Sake nigiri", 1) }
+	code, out, _ := f.run("", "edit", "nigiri")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Sushi nigiri modified\n", out)
+	_, out, _ = f.run("", "get", "-o", "json", "nigiri")
+	assert.Contains(t, out, `"name": "Sake nigiri"`)
+
+	f.edit = func(content string) string { return content }
+	code, out, _ = f.run("", "edit", "nigiri")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Edit cancelled, no changes made\n", out)
+
+	f.edit = func(content string) string { return strings.Replace(content, `"nigiri"`, `"sashimi"`, 1) }
+	code, _, errOut := f.run("", "edit", "nigiri")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "the ID of a sushi can't be changed, the changes are kept in ")
+	kept := strings.TrimSpace(errOut[strings.LastIndex(errOut, " ")+1:])
+	assert.FileExists(t, kept)
+	os.Remove(kept)
+}
+
+func Test_Edit_Locked(t *testing.T) {
+	f := newFixture(t)
+	lock, err := f.locker.Acquire(context.Background(), "nigiri", "chef", "", time.Minute)
+	require.NoError(t, err)
+	f.edit = func(content string) string { return strings.Replace(content, "Salmon nigiri", "Sake nigiri", 1) }
+
+	code, _, errOut := f.run("", "edit", "nigiri")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "423 sushi locked")
+	os.Remove(strings.TrimSpace(errOut[strings.LastIndex(errOut, " ")+1:]))
+
+	code, _, _ = f.run("", "edit", "-lock-token", lock.Token, "nigiri")
+	assert.Equal(t, 0, code)
+}
+
+func Test_Remove(t *testing.T) {
+	f := newFixture(t)
+
+	code, out, _ := f.run("", "remove", "nigiri", "uramaki")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Sushi nigiri removed\nSushi uramaki removed\n", out)
+
+	_, out, _ = f.run("", "list", "-o", "json")
+	assert.JSONEq(t, `[]`, out)
+}
+
+func Test_Profiles(t *testing.T) {
+	f := newFixture(t)
+	delete(f.env, urlEnv)
+
+	code, out, _ := f.run("", "profile", "set", "local", "-url", f.url)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Profile local saved\n", out)
+	code, _, _ = f.run("", "profile", "set", "prod", "-url", "http://127.0.0.1:1", "-api-key", "secret")
+	assert.Equal(t, 0, code)
+
+	code, out, _ = f.run("", "profile", "list")
+	assert.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"CURRENT", "NAME", "URL", "AUTH"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"*", "local", f.url, "none"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"prod", "http://127.0.0.1:1", "api", "key"}, strings.Fields(lines[2]))
+	config, err := os.ReadFile(f.config)
+	require.NoError(t, err)
+	assert.Contains(t, string(config), "apiKey: secret")
+	info, err := os.Stat(f.config)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// the current profile is used, unless another one is asked for
+	code, out, _ = f.run("", "list")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "nigiri")
+	code, _, _ = f.run("", "-profile", "prod", "-timeout", "1s", "list")
+	assert.Equal(t, 1, code)
+	code, _, errOut := f.run("", "-profile", "staging", "list")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, `unknown profile "staging"`)
+
+	code, out, _ = f.run("", "profile", "use", "prod")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Switched to profile prod\n", out)
+	// the flags win over the profile
+	code, _, _ = f.run("", "-url", f.url, "list")
+	assert.Equal(t, 0, code)
+
+	code, _, _ = f.run("", "profile", "remove", "prod")
+	assert.Equal(t, 0, code)
+	_, out, _ = f.run("", completeCommand, "profiles")
+	assert.Equal(t, "local\n", out)
+}
+
+func Test_Completion(t *testing.T) {
+	f := newFixture(t)
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		code, out, _ := f.run("", "completion", shell)
+		assert.Equal(t, 0, code)
+		assert.Contains(t, out, "__complete ids")
+	}
+
+	code, out, _ := f.run("", completeCommand, "ids")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "nigiri\nuramaki\n", out)
+
+	code, _, errOut := f.run("", "completion", "powershell")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "usage: sushictl completion bash|zsh|fish")
+}
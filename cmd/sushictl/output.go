@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+// printer writes the sushis in one of the output formats
+type printer struct {
+	list func(w io.Writer, sushis []sushi.Sushi) error
+	one  func(w io.Writer, s sushi.Sushi) error
+}
+
+func newPrinter(format string) (printer, error) {
+	switch format {
+	case "table":
+		return printer{
+			list: printTable,
+			one:  func(w io.Writer, s sushi.Sushi) error { return printTable(w, []sushi.Sushi{s}) },
+		}, nil
+	case "json":
+		return printer{
+			list: func(w io.Writer, sushis []sushi.Sushi) error { return printJSON(w, sushis) },
+			one:  func(w io.Writer, s sushi.Sushi) error { return printJSON(w, s) },
+		}, nil
+	case "yaml":
+		return printer{
+			list: func(w io.Writer, sushis []sushi.Sushi) error { return printYAML(w, sushis) },
+			one:  func(w io.Writer, s sushi.Sushi) error { return printYAML(w, s) },
+		}, nil
+	}
+	return printer{}, fmt.Errorf("unknown output format %q, expected table, json or yaml", format)
+}
+
+func printTable(w io.Writer, sushis []sushi.Sushi) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tIMAGE\tPRICE\tINGREDIENTS")
+	for _, s := range sushis {
+		price := "-"
+		if s.Price != nil {
+			price = s.Price.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.ID, s.Name, s.ImageNumber, price, strings.Join(s.Ingredients, ", "))
+	}
+	return tw.Flush()
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printYAML names the fields as in the API, the JSON document is converted
+// keeping the order of its fields
+func printYAML(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle drops the flow style the JSON syntax gave to the nodes
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
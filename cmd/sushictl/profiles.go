@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sergiorra/sushi-api-go/pkg/client"
+)
+
+// Profile tells how to reach the API of an environment
+type Profile struct {
+	URL    string `yaml:"url"`
+	APIKey string `yaml:"apiKey,omitempty"`
+	Token  string `yaml:"token,omitempty"`
+	// CAFile verifies the server, CertFile and KeyFile authenticate the client
+	CAFile   string `yaml:"caFile,omitempty"`
+	CertFile string `yaml:"certFile,omitempty"`
+	KeyFile  string `yaml:"keyFile,omitempty"`
+}
+
+// Profiles is the content of the profiles file
+type Profiles struct {
+	Current  string             `yaml:"current,omitempty"`
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+// configPath returns the profiles file given by -config or SUSHICTL_CONFIG,
+// the one of the user configuration directory otherwise
+func (a *app) configPath() (string, error) {
+	if a.configFile != "" {
+		return a.configFile, nil
+	}
+	if path, ok := a.lookupEnv(configEnv); ok && path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("can't find the profiles file, set -config: %w", err)
+	}
+	return filepath.Join(dir, "sushictl", "config.yaml"), nil
+}
+
+// loadProfiles reads the profiles file, a missing one having no profiles
+func (a *app) loadProfiles() (*Profiles, error) {
+	path, err := a.configPath()
+	if err != nil {
+		return nil, err
+	}
+	profiles := &Profiles{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, profiles); err != nil {
+		return nil, fmt.Errorf("invalid profiles file %s: %w", path, err)
+	}
+	return profiles, nil
+}
+
+// saveProfiles writes the profiles file, readable by the user only as it
+// holds credentials
+func (a *app) saveProfiles(profiles *Profiles) error {
+	path, err := a.configPath()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(profiles)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// connection returns the profile in use, in increasing order of precedence
+// from the profiles file, the environment and the flags
+func (a *app) connection() (Profile, error) {
+	profiles, err := a.loadProfiles()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	name := a.profileName
+	if name == "" {
+		name, _ = a.lookupEnv(profileEnv)
+	}
+	var p Profile
+	if name != "" {
+		var ok bool
+		if p, ok = profiles.Profiles[name]; !ok {
+			return Profile{}, fmt.Errorf("unknown profile %q", name)
+		}
+	} else if profiles.Current != "" {
+		p = profiles.Profiles[profiles.Current]
+	}
+
+	for _, override := range []struct {
+		field *string
+		env   string
+		flag  string
+	}{
+		{&p.URL, urlEnv, a.url},
+		{&p.APIKey, apiKeyEnv, a.apiKey},
+		{&p.Token, tokenEnv, a.token},
+	} {
+		if value, ok := a.lookupEnv(override.env); ok && value != "" {
+			*override.field = value
+		}
+		if override.flag != "" {
+			*override.field = override.flag
+		}
+	}
+	if p.URL == "" {
+		p.URL = defaultURL
+	}
+	return p, nil
+}
+
+// client creates a client of the API of the profile in use
+func (a *app) client() (*client.Client, error) {
+	p, err := a.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{Timeout: a.timeout}
+	if p.CAFile != "" || p.CertFile != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if p.CAFile != "" {
+			pem, err := os.ReadFile(p.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificate found in %s", p.CAFile)
+			}
+		}
+		if p.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(p.CertFile, p.KeyFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		httpClient.Transport = &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment}
+	}
+
+	opts := []client.Option{client.WithHTTPClient(httpClient)}
+	switch {
+	case p.APIKey != "":
+		opts = append(opts, client.WithAuth(client.APIKey(p.APIKey)))
+	case p.Token != "":
+		opts = append(opts, client.WithAuth(client.BearerToken(p.Token)))
+	}
+	return client.New(p.URL, opts...)
+}
+
+// profile manages the profiles file
+func (a *app) profile(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	profiles, err := a.loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		if len(args) != 1 {
+			return errUsage
+		}
+		w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CURRENT\tNAME\tURL\tAUTH")
+		for _, name := range sortedKeys(profiles.Profiles) {
+			p := profiles.Profiles[name]
+			current := ""
+			if name == profiles.Current {
+				current = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", current, name, p.URL, p.auth())
+		}
+		return w.Flush()
+
+	case "use":
+		if len(args) != 2 {
+			return errUsage
+		}
+		if _, ok := profiles.Profiles[args[1]]; !ok {
+			return fmt.Errorf("unknown profile %q", args[1])
+		}
+		profiles.Current = args[1]
+		if err := a.saveProfiles(profiles); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "Switched to profile %s\n", args[1])
+		return nil
+
+	case "set":
+		if len(args) < 2 {
+			return errUsage
+		}
+		name := args[1]
+		p := profiles.Profiles[name]
+		flags := a.newFlagSet("profile set")
+		flags.StringVar(&p.URL, "url", p.URL, "URL of the API")
+		flags.StringVar(&p.APIKey, "api-key", p.APIKey, "API key")
+		flags.StringVar(&p.Token, "token", p.Token, "bearer token")
+		flags.StringVar(&p.CAFile, "ca-file", p.CAFile, "CA bundle verifying the server")
+		flags.StringVar(&p.CertFile, "cert-file", p.CertFile, "client certificate")
+		flags.StringVar(&p.KeyFile, "key-file", p.KeyFile, "key of the client certificate")
+		if err := parseFlags(flags, args[2:]); err != nil {
+			return err
+		}
+		if flags.NArg() != 0 {
+			return errUsage
+		}
+		if p.URL == "" {
+			return errors.New("the profile needs a URL")
+		}
+		if (p.CertFile == "") != (p.KeyFile == "") {
+			return errors.New("the client certificate needs both -cert-file and -key-file")
+		}
+		if profiles.Profiles == nil {
+			profiles.Profiles = make(map[string]Profile)
+		}
+		profiles.Profiles[name] = p
+		// the first profile is the one in use
+		if profiles.Current == "" {
+			profiles.Current = name
+		}
+		if err := a.saveProfiles(profiles); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "Profile %s saved\n", name)
+		return nil
+
+	case "remove":
+		if len(args) != 2 {
+			return errUsage
+		}
+		if _, ok := profiles.Profiles[args[1]]; !ok {
+			return fmt.Errorf("unknown profile %q", args[1])
+		}
+		delete(profiles.Profiles, args[1])
+		if profiles.Current == args[1] {
+			profiles.Current = ""
+		}
+		if err := a.saveProfiles(profiles); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "Profile %s removed\n", args[1])
+		return nil
+	}
+	return errUsage
+}
+
+// auth describes the credentials of the profile, never showing them
+func (p Profile) auth() string {
+	switch {
+	case p.APIKey != "":
+		return "api key"
+	case p.Token != "":
+		return "token"
+	case p.CertFile != "":
+		return "certificate"
+	}
+	return "none"
+}
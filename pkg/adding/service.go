@@ -2,25 +2,78 @@ package adding
 
 import (
 	"context"
+	"time"
+
 	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/ingredients"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/tracing"
+	"go.opentelemetry.io/otel"
 )
 
 // Service provides adding operations
 type Service interface {
-	AddSushi(ctx context.Context, ID, ImageNumber, Name string, Ingredients []string) error
+	AddSushi(ctx context.Context, ID, ImageNumber, Name string, Ingredients []string, Pricing sushi.Pricing) error
 }
 
+var tracer = otel.Tracer("github.com/sergiorra/sushi-api-go/pkg/adding")
+
 type service struct {
 	repository sushi.Repository
+	logger     log.Logger
+	publisher  events.Publisher
+	// ingredients is nil when the ingredients are free-form
+	ingredients *ingredients.Catalogue
+}
+
+// Option configures an adding service
+type Option func(*service)
+
+// WithIngredients refuses the sushis made of ingredients missing from the
+// catalogue, the ingredients are the IDs of its entries
+func WithIngredients(catalogue *ingredients.Catalogue) Option {
+	return func(s *service) {
+		s.ingredients = catalogue
+	}
 }
 
 // NewService creates an adding service with the necessary dependencies
-func NewService(repository sushi.Repository) Service {
-	return &service{repository}
+func NewService(repository sushi.Repository, logger log.Logger, publisher events.Publisher, opts ...Option) Service {
+	s := &service{repository: repository, logger: logger, publisher: publisher}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // AddSushi adds the given sushi to storage
-func (s *service) AddSushi(ctx context.Context, ID, ImageNumber, Name string, Ingredients []string) error {
+func (s *service) AddSushi(ctx context.Context, ID, ImageNumber, Name string, Ingredients []string, Pricing sushi.Pricing) error {
+	ctx, span := tracer.Start(ctx, "adding.AddSushi")
+	defer span.End()
+
 	sushi := sushi.New(ID, ImageNumber, Name, Ingredients)
-	return s.repository.CreateSushi(ctx, sushi)
+	sushi.Pricing = Pricing
+	now := time.Now()
+	sushi.CreatedAt = &now
+	if err := sushi.Validate(); err != nil {
+		s.logger.ValidationFailed(ctx, err)
+		return err
+	}
+
+	err := s.ingredients.Reference(ctx, s.logger, sushi.Ingredients, func() error {
+		err := s.repository.CreateSushi(ctx, sushi)
+		if err != nil {
+			tracing.Fail(ctx, err)
+			s.logger.UnexpectedError(ctx, err)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.SushiCreated(ctx, ID)
+	s.publisher.Publish(ctx, events.New(events.SushiCreated, ID, sushi))
+	return nil
 }
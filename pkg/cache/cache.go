@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Tier stores serialized sushis for a while, the repository decorator reads
+// the tiers in order before falling back to the decorated repository
+type Tier interface {
+	// Name labels the metrics of the tier
+	Name() string
+	// Get returns the value of the key and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+type lru struct {
+	mtx     sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+// NewLRU creates an in-process Tier keeping at most size entries for ttl,
+// evicting the least recently used ones first
+func NewLRU(size int, ttl time.Duration) Tier {
+	return &lru{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Name satisfies the Tier interface
+func (c *lru) Name() string {
+	return "lru"
+}
+
+// Get satisfies the Tier interface
+func (c *lru) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if c.now().After(entry.expires) {
+		c.remove(element)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set satisfies the Tier interface
+func (c *lru) Set(ctx context.Context, key string, value []byte) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	if c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Delete satisfies the Tier interface
+func (c *lru) Delete(ctx context.Context, key string) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	return nil
+}
+
+func (c *lru) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
+
+type redisTier struct {
+	pool   *redis.Pool
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedis creates a Tier shared by the replicas, keeping the entries for ttl
+// under keys starting with prefix
+func NewRedis(pool *redis.Pool, prefix string, ttl time.Duration) Tier {
+	return &redisTier{pool: pool, prefix: prefix, ttl: ttl}
+}
+
+// Name satisfies the Tier interface
+func (c *redisTier) Name() string {
+	return "redis"
+}
+
+// Get satisfies the Tier interface
+func (c *redisTier) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer conn.Close()
+
+	value, err := redis.Bytes(conn.Do("GET", c.prefix+key))
+	if err == redis.ErrNil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set satisfies the Tier interface
+func (c *redisTier) Set(ctx context.Context, key string, value []byte) error {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("SET", c.prefix+key, value, "PX", c.ttl.Milliseconds())
+	return err
+}
+
+// Delete satisfies the Tier interface
+func (c *redisTier) Delete(ctx context.Context, key string) error {
+	conn, err := c.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("DEL", c.prefix+key)
+	return err
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2, time.Minute)
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1")))
+	assert.NoError(t, c.Set(ctx, "b", []byte("2")))
+	_, ok, _ := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.NoError(t, c.Set(ctx, "c", []byte("3")))
+
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok, "b was the least recently used")
+	value, ok, _ := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	assert.NoError(t, c.Delete(ctx, "a"))
+	_, ok, _ = c.Get(ctx, "a")
+	assert.False(t, ok)
+}
+
+func Test_LRU_Expires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	c := NewLRU(10, time.Minute).(*lru)
+	c.now = func() time.Time { return now }
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1")))
+	now = now.Add(time.Minute + time.Second)
+
+	_, ok, _ := c.Get(ctx, "a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.order.Len())
+}
+
+func Test_Redis(t *testing.T) {
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", s.Addr()) }}
+	c := NewRedis(pool, "cache:", time.Minute)
+
+	_, ok, err := c.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1")))
+	value, ok, err := c.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+	assert.Equal(t, time.Minute, s.TTL("cache:a"))
+
+	s.FastForward(time.Minute)
+	_, ok, _ = c.Get(ctx, "a")
+	assert.False(t, ok)
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1")))
+	assert.NoError(t, c.Delete(ctx, "a"))
+	assert.False(t, s.Exists("cache:a"))
+}
@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+const namespace = "sushiapi"
+
+// entry is the cached form of a sushi, keeping the timestamps the API hides
+type entry struct {
+	sushi.Sushi
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+type repository struct {
+	next  sushi.Repository
+	tiers []Tier
+	group singleflight.Group
+
+	hits   *prometheus.CounterVec
+	misses *prometheus.CounterVec
+	errors *prometheus.CounterVec
+}
+
+// NewRepository decorates a sushi.Repository reading the sushis by ID through
+// the given tiers, in order. Concurrent misses of the same sushi load it once
+// and the writes invalidate it in every tier; a read racing with a write may
+// still cache the old sushi until the tier TTL expires.
+func NewRepository(next sushi.Repository, reg prometheus.Registerer, tiers ...Tier) sushi.Repository {
+	r := &repository{
+		next:  next,
+		tiers: tiers,
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "hits_total",
+			Help:      "Number of sushis found in the cache, by tier.",
+		}, []string{"tier"}),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "misses_total",
+			Help:      "Number of sushis missing from the cache, by tier.",
+		}, []string{"tier"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "errors_total",
+			Help:      "Number of failed cache operations, by tier and operation.",
+		}, []string{"tier", "operation"}),
+	}
+	reg.MustRegister(r.hits, r.misses, r.errors)
+	return r
+}
+
+func key(ID string) string {
+	return "sushi:" + ID
+}
+
+// GetSushiByID satisfies the sushi.Repository interface, a failing tier is
+// skipped rather than failing the read
+func (r *repository) GetSushiByID(ctx context.Context, ID string) (*sushi.Sushi, error) {
+	for i, tier := range r.tiers {
+		value, ok, err := tier.Get(ctx, key(ID))
+		if err != nil {
+			r.errors.WithLabelValues(tier.Name(), "get").Inc()
+			continue
+		}
+		if !ok {
+			r.misses.WithLabelValues(tier.Name()).Inc()
+			continue
+		}
+		s, err := decode(value)
+		if err != nil {
+			r.errors.WithLabelValues(tier.Name(), "decode").Inc()
+			continue
+		}
+		r.hits.WithLabelValues(tier.Name()).Inc()
+		r.fill(ctx, r.tiers[:i], key(ID), value)
+		return s, nil
+	}
+
+	result := r.group.DoChan(key(ID), func() (interface{}, error) {
+		// the load is shared, it mustn't fail because the first caller gave up
+		ctx := context.WithoutCancel(ctx)
+		s, err := r.next.GetSushiByID(ctx, ID)
+		if err != nil {
+			return nil, err
+		}
+		value, err := encode(s)
+		if err != nil {
+			return nil, err
+		}
+		r.fill(ctx, r.tiers, key(ID), value)
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		// every caller decodes its own copy
+		return decode(res.Val.([]byte))
+	}
+}
+
+// fill stores the value in the given tiers
+func (r *repository) fill(ctx context.Context, tiers []Tier, key string, value []byte) {
+	for _, tier := range tiers {
+		if err := tier.Set(ctx, key, value); err != nil {
+			r.errors.WithLabelValues(tier.Name(), "set").Inc()
+		}
+	}
+}
+
+// invalidate removes the sushi from every tier once it has been written
+func (r *repository) invalidate(ctx context.Context, ID string) {
+	r.group.Forget(key(ID))
+	for _, tier := range r.tiers {
+		if err := tier.Delete(ctx, key(ID)); err != nil {
+			r.errors.WithLabelValues(tier.Name(), "delete").Inc()
+		}
+	}
+}
+
+// CreateSushi satisfies the sushi.Repository interface
+func (r *repository) CreateSushi(ctx context.Context, s *sushi.Sushi) error {
+	err := r.next.CreateSushi(ctx, s)
+	r.invalidate(ctx, s.ID)
+	return err
+}
+
+// UpdateSushi satisfies the sushi.Repository interface
+func (r *repository) UpdateSushi(ctx context.Context, ID string, s *sushi.Sushi) error {
+	err := r.next.UpdateSushi(ctx, ID, s)
+	r.invalidate(ctx, ID)
+	return err
+}
+
+// DeleteSushi satisfies the sushi.Repository interface
+func (r *repository) DeleteSushi(ctx context.Context, ID string) error {
+	err := r.next.DeleteSushi(ctx, ID)
+	r.invalidate(ctx, ID)
+	return err
+}
+
+// GetSushis satisfies the sushi.Repository interface, lists aren't cached
+func (r *repository) GetSushis(ctx context.Context) ([]sushi.Sushi, error) {
+	return r.next.GetSushis(ctx)
+}
+
+// StreamSushis satisfies the sushi.Streamer interface, lists aren't cached
+func (r *repository) StreamSushis(ctx context.Context) iter.Seq2[sushi.Sushi, error] {
+	return sushi.Stream(ctx, r.next)
+}
+
+// Version satisfies the sushi.Versioner interface, versions aren't cached
+func (r *repository) Version(ctx context.Context) (sushi.Version, error) {
+	return sushi.CurrentVersion(ctx, r.next)
+}
+
+// Ping satisfies the sushi.Pinger interface when the decorated repository does
+func (r *repository) Ping(ctx context.Context) error {
+	if pinger, ok := r.next.(sushi.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Close releases the decorated repository when it holds resources
+func (r *repository) Close() error {
+	if closer, ok := r.next.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func encode(s *sushi.Sushi) ([]byte, error) {
+	return json.Marshal(entry{Sushi: *s, CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt})
+}
+
+func decode(value []byte) (*sushi.Sushi, error) {
+	var e entry
+	if err := json.Unmarshal(value, &e); err != nil {
+		return nil, err
+	}
+	s := e.Sushi
+	s.CreatedAt, s.UpdatedAt = e.CreatedAt, e.UpdatedAt
+	return &s, nil
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/storage/inmem"
+)
+
+// countingRepository counts the reads by ID reaching the storage and holds
+// them until release is closed
+type countingRepository struct {
+	sushi.Repository
+	reads   int32
+	release chan struct{}
+}
+
+func (r *countingRepository) GetSushiByID(ctx context.Context, ID string) (*sushi.Sushi, error) {
+	atomic.AddInt32(&r.reads, 1)
+	if r.release != nil {
+		<-r.release
+	}
+	return r.Repository.GetSushiByID(ctx, ID)
+}
+
+type failingTier struct{}
+
+func (failingTier) Name() string { return "failing" }
+func (failingTier) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("unreachable")
+}
+func (failingTier) Set(ctx context.Context, key string, value []byte) error {
+	return errors.New("unreachable")
+}
+func (failingTier) Delete(ctx context.Context, key string) error { return errors.New("unreachable") }
+
+func buildRepository(tiers ...Tier) (*repository, *countingRepository) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := sushi.New("01D3XZ38KDR", "1", "California Roll", []string{"Crab"})
+	s.CreatedAt = &createdAt
+
+	storage := &countingRepository{Repository: inmem.NewRepository(map[string]sushi.Sushi{s.ID: *s})}
+	return NewRepository(storage, prometheus.NewRegistry(), tiers...).(*repository), storage
+}
+
+func Test_Repository_ReadThrough(t *testing.T) {
+	ctx := context.Background()
+	repo, storage := buildRepository(NewLRU(10, time.Minute))
+
+	first, err := repo.GetSushiByID(ctx, "01D3XZ38KDR")
+	require.NoError(t, err)
+	second, err := repo.GetSushiByID(ctx, "01D3XZ38KDR")
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), storage.reads)
+	assert.Equal(t, first, second)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), *second.CreatedAt, "the timestamps are cached")
+	assert.Equal(t, float64(1), testutil.ToFloat64(repo.hits.WithLabelValues("lru")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(repo.misses.WithLabelValues("lru")))
+
+	second.Ingredients[0] = "Tuna"
+	third, _ := repo.GetSushiByID(ctx, "01D3XZ38KDR")
+	assert.Equal(t, []string{"Crab"}, third.Ingredients, "callers get their own copy")
+}
+
+func Test_Repository_FillsUpperTiers(t *testing.T) {
+	ctx := context.Background()
+	local, shared := NewLRU(10, time.Minute), NewLRU(10, time.Minute)
+	repo, storage := buildRepository(local, shared)
+
+	_, err := repo.GetSushiByID(ctx, "01D3XZ38KDR")
+	require.NoError(t, err)
+	require.NoError(t, local.Delete(ctx, key("01D3XZ38KDR")))
+
+	_, err = repo.GetSushiByID(ctx, "01D3XZ38KDR")
+	require.NoError(t, err)
+	_, ok, _ := local.Get(ctx, key("01D3XZ38KDR"))
+
+	assert.True(t, ok, "the local tier is filled from the shared one")
+	assert.Equal(t, int32(1), storage.reads)
+}
+
+func Test_Repository_Invalidates(t *testing.T) {
+	ctx := context.Background()
+	repo, storage := buildRepository(NewLRU(10, time.Minute))
+
+	_, err := repo.GetSushiByID(ctx, "01D3XZ38KDR")
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateSushi(ctx, "01D3XZ38KDR", sushi.New("01D3XZ38KDR", "2", "Rainbow Roll", nil)))
+
+	s, err := repo.GetSushiByID(ctx, "01D3XZ38KDR")
+	require.NoError(t, err)
+	assert.Equal(t, "Rainbow Roll", s.Name)
+
+	require.NoError(t, repo.DeleteSushi(ctx, "01D3XZ38KDR"))
+	_, err = repo.GetSushiByID(ctx, "01D3XZ38KDR")
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), storage.reads)
+}
+
+func Test_Repository_LoadsConcurrentMissesOnce(t *testing.T) {
+	repo, storage := buildRepository(NewLRU(10, time.Minute))
+	storage.release = make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := repo.GetSushiByID(context.Background(), "01D3XZ38KDR")
+			assert.NoError(t, err)
+			assert.Equal(t, "California Roll", s.Name)
+		}()
+	}
+	// let every reader join the flight before the storage answers
+	time.Sleep(50 * time.Millisecond)
+	close(storage.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), storage.reads)
+}
+
+func Test_Repository_SkipsFailingTiers(t *testing.T) {
+	repo, storage := buildRepository(failingTier{})
+
+	s, err := repo.GetSushiByID(context.Background(), "01D3XZ38KDR")
+
+	require.NoError(t, err)
+	assert.Equal(t, "California Roll", s.Name)
+	assert.Equal(t, int32(1), storage.reads)
+	assert.Equal(t, float64(1), testutil.ToFloat64(repo.errors.WithLabelValues("failing", "get")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(repo.errors.WithLabelValues("failing", "set")))
+}
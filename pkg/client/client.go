@@ -0,0 +1,439 @@
+// Package client is a typed client of the sushi REST API, retrying the
+// throttled and failed requests
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+)
+
+const (
+	apiKeyHeader         = "X-API-Key"
+	idempotencyKeyHeader = "Idempotency-Key"
+	lockTokenHeader      = "Lock-Token"
+	requestIDHeader      = "X-Request-ID"
+	ndjsonContentType    = "application/x-ndjson"
+	userAgent            = "sushi-api-go-client"
+)
+
+// Authenticator adds the credentials of the client to every request
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc turns a function into an Authenticator
+type AuthenticatorFunc func(req *http.Request) error
+
+// Authenticate satisfies the Authenticator interface
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// APIKey authenticates with the API key header of the server
+func APIKey(key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set(apiKeyHeader, key)
+		return nil
+	})
+}
+
+// BearerToken authenticates with an Authorization header, for the gateways
+// in front of the server
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// RetryPolicy tells how the failed requests are retried
+type RetryPolicy struct {
+	// Attempts is the number of attempts of every request, 1 meaning no retry
+	Attempts int
+	// the wait between the attempts doubles from InitialBackoff up to
+	// MaxBackoff, unless the server asks for another one with Retry-After
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy retries for a few seconds
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts:       4,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// Client calls the sushi API. It's safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	auth       Authenticator
+	retry      RetryPolicy
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// Option configures optional features of the client
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with the given client, the one to
+// configure for the client certificates
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAuth authenticates every request, retries included
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// WithRetry replaces the DefaultRetryPolicy
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// New creates a client of the API served at baseURL
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: the scheme must be http or https", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy(),
+		sleep:      sleep,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.retry.Attempts < 1 {
+		c.retry.Attempts = 1
+	}
+	return c, nil
+}
+
+// ListOption restricts the sushis listed
+type ListOption func(query url.Values)
+
+// PriceMin keeps the sushis costing at least the amount, in minor units
+func PriceMin(amount int64) ListOption {
+	return func(query url.Values) { query.Set("priceMin", strconv.FormatInt(amount, 10)) }
+}
+
+// PriceMax keeps the sushis costing at most the amount, in minor units
+func PriceMax(amount int64) ListOption {
+	return func(query url.Values) { query.Set("priceMax", strconv.FormatInt(amount, 10)) }
+}
+
+// Currency keeps the sushis priced in the currency, an ISO 4217 code
+func Currency(code string) ListOption {
+	return func(query url.Values) { query.Set("currency", code) }
+}
+
+// Restaurant compares the prices of the restaurant rather than the base
+// prices
+func Restaurant(ID string) ListOption {
+	return func(query url.Values) { query.Set("restaurant", ID) }
+}
+
+// ExcludeAllergens leaves out the sushis containing any of the allergens, or
+// ingredients nothing is known about
+func ExcludeAllergens(allergens ...sushi.Allergen) ListOption {
+	return func(query url.Values) {
+		for _, a := range allergens {
+			query.Add("excludeAllergens", string(a))
+		}
+	}
+}
+
+// Diet keeps the sushis labelled with all the diets
+func Diet(diets ...sushi.Diet) ListOption {
+	return func(query url.Values) {
+		for _, d := range diets {
+			query.Add("diet", string(d))
+		}
+	}
+}
+
+func listPath(opts []ListOption) string {
+	query := url.Values{}
+	for _, opt := range opts {
+		opt(query)
+	}
+	if len(query) == 0 {
+		return "/sushi"
+	}
+	return "/sushi?" + query.Encode()
+}
+
+// List returns the sushis, all of them unless options restrict them
+func (c *Client) List(ctx context.Context, opts ...ListOption) ([]sushi.Sushi, error) {
+	res, err := c.do(ctx, http.MethodGet, listPath(opts), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var sushis []sushi.Sushi
+	if err := json.NewDecoder(res.Body).Decode(&sushis); err != nil {
+		return nil, fmt.Errorf("can't decode the sushis: %w", err)
+	}
+	return sushis, nil
+}
+
+// Stream yields the sushis as the server sends them, keeping memory flat
+// however large the catalogue is. The iteration stops after the first error.
+func (c *Client) Stream(ctx context.Context, opts ...ListOption) iter.Seq2[sushi.Sushi, error] {
+	return func(yield func(sushi.Sushi, error) bool) {
+		header := http.Header{"Accept": {ndjsonContentType}}
+		res, err := c.do(ctx, http.MethodGet, listPath(opts), header, nil)
+		if err != nil {
+			yield(sushi.Sushi{}, err)
+			return
+		}
+		defer res.Body.Close()
+
+		decoder := json.NewDecoder(res.Body)
+		for {
+			var s sushi.Sushi
+			err := decoder.Decode(&s)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(sushi.Sushi{}, fmt.Errorf("can't decode the sushis: %w", err))
+				return
+			}
+			if !yield(s, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Get returns the sushi, the error matches ErrNotFound when there is none
+func (c *Client) Get(ctx context.Context, ID string) (*sushi.Sushi, error) {
+	res, err := c.do(ctx, http.MethodGet, "/sushi/"+url.PathEscape(ID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var s sushi.Sushi
+	if err := json.NewDecoder(res.Body).Decode(&s); err != nil {
+		return nil, fmt.Errorf("can't decode the sushi: %w", err)
+	}
+	return &s, nil
+}
+
+type addSushiRequest struct {
+	ID          string   `json:"id"`
+	ImageNumber string   `json:"imageNumber"`
+	Name        string   `json:"name"`
+	Ingredients []string `json:"ingredients"`
+	sushi.Pricing
+}
+
+// Add creates the sushi, the error matches sushi.ErrInvalidSushi when the
+// server rejects it
+func (c *Client) Add(ctx context.Context, s sushi.Sushi) error {
+	body, err := json.Marshal(addSushiRequest{ID: s.ID, ImageNumber: s.ImageNumber, Name: s.Name, Ingredients: s.Ingredients, Pricing: s.Pricing})
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, http.MethodPost, "/sushi", nil, body)
+}
+
+type modifySushiRequest struct {
+	ImageNumber string   `json:"imageNumber"`
+	Name        string   `json:"name"`
+	Ingredients []string `json:"ingredients"`
+	sushi.Pricing
+}
+
+// Modify replaces the data of the sushi. The lock token carried by ctx, see
+// locking.WithToken, is sent along; the error matches locking.ErrLocked when
+// somebody else holds the lock.
+func (c *Client) Modify(ctx context.Context, ID string, s sushi.Sushi) error {
+	body, err := json.Marshal(modifySushiRequest{ImageNumber: s.ImageNumber, Name: s.Name, Ingredients: s.Ingredients, Pricing: s.Pricing})
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	if token := locking.Token(ctx); token != "" {
+		header.Set(lockTokenHeader, token)
+	}
+	return c.send(ctx, http.MethodPut, "/sushi/"+url.PathEscape(ID), header, body)
+}
+
+// Remove deletes the sushi, removing a missing one isn't an error
+func (c *Client) Remove(ctx context.Context, ID string) error {
+	return c.send(ctx, http.MethodDelete, "/sushi/"+url.PathEscape(ID), nil, nil)
+}
+
+// send runs a request whose response has no body worth reading
+func (c *Client) send(ctx context.Context, method, path string, header http.Header, body []byte) error {
+	res, err := c.do(ctx, method, path, header, body)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return res.Body.Close()
+}
+
+// do sends the request until it succeeds or can't be retried. The mutations
+// carry an Idempotency-Key, the same for all the attempts, so a server
+// replaying the responses runs them once. The errors of the responses are
+// returned as *Error.
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body []byte) (*http.Response, error) {
+	if header == nil {
+		header = http.Header{}
+	}
+	if method != http.MethodGet && header.Get(idempotencyKeyHeader) == "" {
+		header.Set(idempotencyKeyHeader, newIdempotencyKey())
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := c.attempt(ctx, method, path, header, body)
+		if err == nil && res.StatusCode < http.StatusBadRequest {
+			return res, nil
+		}
+
+		var delay time.Duration
+		retry := attempt < c.retry.Attempts && ctx.Err() == nil
+		if err != nil {
+			// the failures of the transport are retried, the idempotency key
+			// makes it safe for the mutations
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			delay = c.retry.backoff(attempt)
+		} else {
+			err = decodeError(res)
+			retry = retry && retryable(res)
+			delay = retryAfter(res, c.retry.backoff(attempt))
+		}
+		if !retry {
+			return nil, err
+		}
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, method, path string, header http.Header, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	u := *c.baseURL
+	// the path may carry a query, like the filters of the list
+	path, u.RawQuery, _ = strings.Cut(path, "?")
+	u.Path += path
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header.Clone()
+	req.Header.Set("User-Agent", userAgent)
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.auth != nil {
+		if err := c.auth.Authenticate(req); err != nil {
+			return nil, fmt.Errorf("can't authenticate the request: %w", err)
+		}
+	}
+	return c.httpClient.Do(req)
+}
+
+// retryable tells whether the request may succeed later. A 409 with
+// Retry-After is a request with the same idempotency key still in progress.
+func retryable(res *http.Response) bool {
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests, res.StatusCode >= http.StatusInternalServerError:
+		return true
+	case res.StatusCode == http.StatusConflict:
+		return res.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// retryAfter returns the wait asked by the server, in seconds or as a date,
+// or fallback when there is none
+func retryAfter(res *http.Response, fallback time.Duration) time.Duration {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+		return 0
+	}
+	return fallback
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
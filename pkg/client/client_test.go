@@ -0,0 +1,274 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergiorra/sushi-api-go/cmd/sample-data"
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	"github.com/sergiorra/sushi-api-go/pkg/ratelimit"
+	"github.com/sergiorra/sushi-api-go/pkg/server"
+	"github.com/sergiorra/sushi-api-go/pkg/servicetest"
+)
+
+type fixture struct {
+	locker locking.Locker
+	// delays are the waits between the attempts, the client doesn't sleep
+	mtx    sync.Mutex
+	delays []time.Duration
+}
+
+// newClient serves the sample sushis, the handler wrapping the router when
+// given
+func newClient(t *testing.T, wrap func(http.Handler) http.Handler, serverOpts []server.Option, opts ...Option) (*Client, *fixture) {
+	t.Helper()
+
+	services := servicetest.New(sample.Sushis)
+	f := &fixture{locker: services.Locker}
+	s := server.New("test", services.Getting, services.Adding, services.Modifying, services.Removing, serverOpts...)
+
+	handler := s.Router()
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c, err := New(srv.URL+"/", opts...)
+	require.NoError(t, err)
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		f.mtx.Lock()
+		defer f.mtx.Unlock()
+		f.delays = append(f.delays, d)
+		return ctx.Err()
+	}
+	return c, f
+}
+
+func Test_CRUD(t *testing.T) {
+	c, _ := newClient(t, nil, nil)
+	ctx := context.Background()
+
+	sushis, err := c.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, sushis, len(sample.Sushis))
+
+	s, err := c.Get(ctx, "01D3XZ38KDR")
+	require.NoError(t, err)
+	assert.Equal(t, "California Roll", s.Name)
+
+	require.NoError(t, c.Add(ctx, sushi.Sushi{ID: "hosomaki", Name: "Hosomaki", Ingredients: []string{"Rice", "Cucumber"}}))
+	pricing := sushi.Pricing{Price: &sushi.Money{Amount: 350, Currency: "EUR"}, Prices: map[string]sushi.Money{"tokyo": {Amount: 500, Currency: "JPY"}}}
+	require.NoError(t, c.Modify(ctx, "hosomaki", sushi.Sushi{ImageNumber: "4", Name: "Cucumber hosomaki", Ingredients: []string{"Rice", "Cucumber"}, Pricing: pricing}))
+	s, err = c.Get(ctx, "hosomaki")
+	require.NoError(t, err)
+	labels := &sushi.Labels{Allergens: []sushi.Allergen{}, Diets: []sushi.Diet{sushi.Vegetarian, sushi.Vegan, sushi.GlutenFree}}
+	assert.Equal(t, sushi.Sushi{ID: "hosomaki", ImageNumber: "4", Name: "Cucumber hosomaki", Ingredients: []string{"Rice", "Cucumber"}, Pricing: pricing, Labels: labels}, *s)
+
+	require.NoError(t, c.Remove(ctx, "hosomaki"))
+	_, err = c.Get(ctx, "hosomaki")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "Sushi Not found", apiErr.Message)
+	assert.NotEmpty(t, apiErr.RequestID)
+}
+
+func Test_Stream(t *testing.T) {
+	c, _ := newClient(t, nil, nil)
+
+	var IDs []string
+	for s, err := range c.Stream(context.Background()) {
+		require.NoError(t, err)
+		IDs = append(IDs, s.ID)
+	}
+	var expected []string
+	for ID := range sample.Sushis {
+		expected = append(expected, ID)
+	}
+	assert.ElementsMatch(t, expected, IDs)
+}
+
+func Test_List_Filter(t *testing.T) {
+	c, _ := newClient(t, nil, nil)
+	ctx := context.Background()
+
+	sushis, err := c.List(ctx, PriceMin(900), PriceMax(1100), Currency("EUR"))
+	require.NoError(t, err)
+	require.Len(t, sushis, 1)
+	assert.Equal(t, "01D3XZ38KLE", sushis[0].ID)
+	assert.Equal(t, &sushi.Money{Amount: 1500, Currency: "JPY"}, sushis[0].PriceAt("tokyo"))
+
+	var IDs []string
+	for s, err := range c.Stream(ctx, Restaurant("tokyo"), Currency("JPY"), PriceMax(1200)) {
+		require.NoError(t, err)
+		IDs = append(IDs, s.ID)
+	}
+	assert.Equal(t, []string{"01D3XZ38KDR"}, IDs)
+
+	_, err = c.List(ctx, PriceMin(1100), PriceMax(900))
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+}
+
+func Test_List_Labels(t *testing.T) {
+	c, _ := newClient(t, nil, nil)
+	ctx := context.Background()
+
+	sushis, err := c.List(ctx, ExcludeAllergens(sushi.Crustaceans))
+	require.NoError(t, err)
+	require.Len(t, sushis, 1)
+	assert.Equal(t, "01D3XZ38KLE", sushis[0].ID)
+	assert.Equal(t, []sushi.Allergen{sushi.Gluten, sushi.Eggs, sushi.Fish, sushi.Mustard}, sushis[0].Labels.Allergens)
+
+	sushis, err = c.List(ctx, ExcludeAllergens(sushi.Mustard, sushi.Sesame), Diet(sushi.RawFish))
+	require.NoError(t, err)
+	require.Len(t, sushis, 1)
+	assert.Equal(t, "01D3XZ38TRE", sushis[0].ID)
+
+	_, err = c.List(ctx, Diet("pescatarian"))
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Contains(t, apiErr.Message, `unknown diet "pescatarian"`)
+}
+
+func Test_Add_Invalid(t *testing.T) {
+	c, f := newClient(t, nil, nil)
+
+	err := c.Add(context.Background(), sushi.Sushi{ID: "hosomaki", Name: "Hosomaki", Pricing: sushi.Pricing{Price: &sushi.Money{Amount: -300, Currency: "EUR"}}})
+	assert.ErrorIs(t, err, sushi.ErrInvalidSushi)
+	assert.Contains(t, err.Error(), `amount -300 can't be negative`)
+	assert.Empty(t, f.delays, "a rejected sushi isn't retried")
+}
+
+func Test_Modify_Locked(t *testing.T) {
+	c, f := newClient(t, nil, nil)
+	ctx := context.Background()
+
+	lock, err := f.locker.Acquire(ctx, "01D3XZ38KDR", "chef", "", time.Minute)
+	require.NoError(t, err)
+
+	err = c.Modify(ctx, "01D3XZ38KDR", sushi.Sushi{Name: "California Roll"})
+	assert.ErrorIs(t, err, locking.ErrLocked)
+
+	assert.NoError(t, c.Modify(locking.WithToken(ctx, lock.Token), "01D3XZ38KDR", sushi.Sushi{Name: "California Roll"}))
+}
+
+func Test_Retry(t *testing.T) {
+	var mtx sync.Mutex
+	var keys []string
+	failures := 2
+	wrap := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mtx.Lock()
+			keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+			fail := failures > 0
+			failures--
+			mtx.Unlock()
+			if fail {
+				w.Header().Set("Retry-After", "3")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	c, f := newClient(t, wrap, nil)
+
+	require.NoError(t, c.Add(context.Background(), sushi.Sushi{ID: "hosomaki", Name: "Hosomaki"}))
+	assert.Equal(t, []time.Duration{3 * time.Second, 3 * time.Second}, f.delays)
+	require.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, []string{keys[0], keys[0], keys[0]}, keys, "the attempts share their idempotency key")
+}
+
+func Test_Retry_Backoff(t *testing.T) {
+	wrap := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		})
+	}
+	c, f := newClient(t, wrap, nil, WithRetry(RetryPolicy{Attempts: 4, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}))
+
+	_, err := c.List(context.Background())
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, "Bad Gateway", apiErr.Message)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, f.delays)
+}
+
+func Test_Retry_RateLimited(t *testing.T) {
+	policy, err := ratelimit.ParsePolicy(ratelimit.Limit{}, "GET /sushi/{ID}=0.001:1")
+	require.NoError(t, err)
+	c, f := newClient(t, nil, []server.Option{server.WithRateLimit(ratelimit.NewMemoryStore(), policy)},
+		WithRetry(RetryPolicy{Attempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+	ctx := context.Background()
+
+	_, err = c.Get(ctx, "01D3XZ38KDR")
+	require.NoError(t, err)
+	_, err = c.Get(ctx, "01D3XZ38KDR")
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Contains(t, err.Error(), "Too many requests")
+	require.Len(t, f.delays, 1)
+	assert.Greater(t, f.delays[0], time.Second, "the wait asked by the server is honoured")
+}
+
+func Test_Retry_Canceled(t *testing.T) {
+	wrap := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+	}
+	c, _ := newClient(t, wrap, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.List(ctx)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
+
+func Test_Auth(t *testing.T) {
+	var header http.Header
+	wrap := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Clone()
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	c, _ := newClient(t, wrap, nil, WithAuth(APIKey("kiosk")))
+	_, err := c.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "kiosk", header.Get(apiKeyHeader))
+
+	c, _ = newClient(t, wrap, nil, WithAuth(BearerToken("secret")))
+	_, err = c.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+
+	failing := AuthenticatorFunc(func(*http.Request) error { return errors.New("no credentials") })
+	c, _ = newClient(t, wrap, nil, WithAuth(failing))
+	_, err = c.List(context.Background())
+	assert.ErrorContains(t, err, "no credentials")
+}
+
+func Test_New_Invalid(t *testing.T) {
+	for _, baseURL := range []string{"localhost:3000", "ftp://localhost", "http://[::1"} {
+		_, err := New(baseURL)
+		assert.Error(t, err, baseURL)
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+)
+
+var (
+	// ErrNotFound is matched by the errors of the requests on missing sushis
+	// or ingredients
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is matched by the errors of the requests still throttled
+	// after the retries
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// maxErrorBody bounds the bytes read from an error response
+const maxErrorBody = 64 << 10
+
+// Error is an error response of the server. It matches ErrNotFound,
+// sushi.ErrInvalidSushi, sushi.ErrInvalidIngredient, sushi.ErrIngredientInUse,
+// locking.ErrLocked or ErrRateLimited according to its status.
+type Error struct {
+	StatusCode int
+	// Message is the error sent by the server, or the status text when there
+	// is none
+	Message string
+	// RequestID identifies the request in the logs of the server
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("sushi api: %d %s", e.StatusCode, e.Message)
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Is satisfies the interface used by errors.Is
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case sushi.ErrInvalidSushi, sushi.ErrInvalidIngredient:
+		return e.StatusCode == http.StatusBadRequest
+	case sushi.ErrIngredientInUse:
+		return e.StatusCode == http.StatusConflict
+	case locking.ErrLocked:
+		return e.StatusCode == http.StatusLocked
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// decodeError reads the error of the response, the server sends it as a JSON
+// string. The body is closed.
+func decodeError(res *http.Response) *Error {
+	defer res.Body.Close()
+	e := &Error{StatusCode: res.StatusCode, RequestID: res.Header.Get(requestIDHeader)}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	if err := json.Unmarshal(body, &e.Message); err != nil {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(res.StatusCode)
+	}
+	return e
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+// Ingredients returns the ingredient catalogue, ordered by ID
+func (c *Client) Ingredients(ctx context.Context) ([]sushi.Ingredient, error) {
+	res, err := c.do(ctx, http.MethodGet, "/ingredients", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var ingredients []sushi.Ingredient
+	if err := json.NewDecoder(res.Body).Decode(&ingredients); err != nil {
+		return nil, fmt.Errorf("can't decode the ingredients: %w", err)
+	}
+	return ingredients, nil
+}
+
+// Ingredient returns the ingredient, the error matches ErrNotFound when there
+// is none
+func (c *Client) Ingredient(ctx context.Context, ID string) (*sushi.Ingredient, error) {
+	res, err := c.do(ctx, http.MethodGet, "/ingredients/"+url.PathEscape(ID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var i sushi.Ingredient
+	if err := json.NewDecoder(res.Body).Decode(&i); err != nil {
+		return nil, fmt.Errorf("can't decode the ingredient: %w", err)
+	}
+	return &i, nil
+}
+
+// AddIngredient adds the ingredient to the catalogue, the error matches
+// sushi.ErrInvalidIngredient when the server rejects it
+func (c *Client) AddIngredient(ctx context.Context, i sushi.Ingredient) error {
+	body, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, http.MethodPost, "/ingredients", nil, body)
+}
+
+// ModifyIngredient replaces the name and the dietary information of the
+// ingredient, the ID of i is ignored
+func (c *Client) ModifyIngredient(ctx context.Context, ID string, i sushi.Ingredient) error {
+	i.ID = ID
+	body, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, http.MethodPut, "/ingredients/"+url.PathEscape(ID), nil, body)
+}
+
+// RemoveIngredient removes the ingredient from the catalogue, the error
+// matches sushi.ErrIngredientInUse while some sushis are made of it
+func (c *Client) RemoveIngredient(ctx context.Context, ID string) error {
+	return c.send(ctx, http.MethodDelete, "/ingredients/"+url.PathEscape(ID), nil, nil)
+}
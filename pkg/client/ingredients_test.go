@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergiorra/sushi-api-go/cmd/sample-data"
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/dietary"
+	"github.com/sergiorra/sushi-api-go/pkg/server"
+	"github.com/sergiorra/sushi-api-go/pkg/servicetest"
+	"github.com/sergiorra/sushi-api-go/pkg/storage/inmem"
+)
+
+// newCatalogueClient serves the sample sushis made of the ingredients of the
+// catalogue
+func newCatalogueClient(t *testing.T) *Client {
+	t.Helper()
+
+	services := servicetest.New(sample.Sushis, servicetest.WithIngredients(inmem.NewIngredientRepository(dietary.Ingredients())))
+	s := server.New("test", services.Getting, services.Adding, services.Modifying, services.Removing,
+		server.WithIngredients(services.Ingredients))
+	srv := httptest.NewServer(s.Router())
+	t.Cleanup(srv.Close)
+
+	c, err := New(srv.URL, WithRetry(RetryPolicy{Attempts: 1}))
+	require.NoError(t, err)
+	return c
+}
+
+func Test_Ingredients(t *testing.T) {
+	c := newCatalogueClient(t)
+	ctx := context.Background()
+
+	catalogue, err := c.Ingredients(ctx)
+	require.NoError(t, err)
+	assert.Len(t, catalogue, len(dietary.Ingredients()))
+
+	kombu := sushi.Ingredient{ID: "kombu", Name: "Kombu", IngredientInfo: sushi.IngredientInfo{Vegetarian: true, Vegan: true}}
+	require.NoError(t, c.AddIngredient(ctx, kombu))
+	i, err := c.Ingredient(ctx, "kombu")
+	require.NoError(t, err)
+	assert.Equal(t, kombu, *i)
+
+	err = c.AddIngredient(ctx, sushi.Ingredient{ID: "kombu_dashi", Name: "kombu"})
+	assert.ErrorIs(t, err, sushi.ErrInvalidIngredient)
+
+	require.NoError(t, c.Add(ctx, sushi.Sushi{ID: "kombu_maki", Name: "Kombu maki", Ingredients: []string{"rice", "kombu"}}))
+	err = c.Add(ctx, sushi.Sushi{ID: "wakame_maki", Name: "Wakame maki", Ingredients: []string{"rice", "wakame"}})
+	assert.ErrorIs(t, err, sushi.ErrInvalidSushi)
+	assert.Contains(t, err.Error(), `ingredient "wakame" isn't in the catalogue`)
+
+	kombu.Name = "Kelp"
+	kombu.Allergens = []sushi.Allergen{sushi.Sulphites}
+	require.NoError(t, c.ModifyIngredient(ctx, "kombu", kombu))
+	s, err := c.Get(ctx, "kombu_maki")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rice", "kombu"}, s.Ingredients)
+	assert.Equal(t, []sushi.Allergen{sushi.Sulphites}, s.Labels.Allergens)
+
+	err = c.RemoveIngredient(ctx, "kombu")
+	assert.ErrorIs(t, err, sushi.ErrIngredientInUse)
+	assert.Contains(t, err.Error(), "kombu is an ingredient of kombu_maki")
+
+	require.NoError(t, c.Remove(ctx, "kombu_maki"))
+	require.NoError(t, c.RemoveIngredient(ctx, "kombu"))
+	_, err = c.Ingredient(ctx, "kombu")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
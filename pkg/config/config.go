@@ -0,0 +1,390 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/ratelimit"
+	"github.com/sergiorra/sushi-api-go/pkg/server"
+	"github.com/sergiorra/sushi-api-go/pkg/tracing"
+)
+
+// Config is the whole configuration of the sushi API. Every field can be set
+// from a YAML or TOML file, an environment variable and a flag, in increasing
+// order of precedence.
+type Config struct {
+	Database    string            `yaml:"database" toml:"database" env:"SUSHIAPI_DATABASE" flag:"database" usage:"initialize the api using the given db engine: inmem, mysql, cockroach or redis"`
+	Server      ServerConfig      `yaml:"server" toml:"server"`
+	TLS         TLSConfig         `yaml:"tls" toml:"tls"`
+	Compression CompressionConfig `yaml:"compression" toml:"compression"`
+	CORS        CORSConfig        `yaml:"cors" toml:"cors"`
+	Log         LogConfig         `yaml:"log" toml:"log"`
+	Tracing     TracingConfig     `yaml:"tracing" toml:"tracing"`
+	RateLimit   RateLimitConfig   `yaml:"rateLimit" toml:"rateLimit"`
+	Cache       CacheConfig       `yaml:"cache" toml:"cache"`
+	Idempotency IdempotencyConfig `yaml:"idempotency" toml:"idempotency"`
+	Webhooks    WebhooksConfig    `yaml:"webhooks" toml:"webhooks"`
+	Events      EventsConfig      `yaml:"events" toml:"events"`
+	WebSocket   WebSocketConfig   `yaml:"webSocket" toml:"webSocket"`
+	GRPC        GRPCConfig        `yaml:"grpc" toml:"grpc"`
+	GraphQL     GraphQLConfig     `yaml:"graphql" toml:"graphql"`
+	Seed        SeedConfig        `yaml:"seed" toml:"seed"`
+	MySQL       MySQLConfig       `yaml:"mysql" toml:"mysql"`
+	Cockroach   CockroachConfig   `yaml:"cockroach" toml:"cockroach"`
+	Redis       RedisConfig       `yaml:"redis" toml:"redis"`
+}
+
+// ServerConfig defines the HTTP server
+type ServerConfig struct {
+	Name              string        `yaml:"name" toml:"name" env:"SUSHIAPI_NAME" usage:"name of the API, prefix of the default server identifier"`
+	ID                string        `yaml:"id" toml:"id" env:"SUSHIAPI_SERVER_ID" flag:"server-id" usage:"define server identifier (default name-hostname)"`
+	Host              string        `yaml:"host" toml:"host" env:"SUSHIAPI_SERVER_HOST" flag:"host" usage:"define host of the server"`
+	Port              int           `yaml:"port" toml:"port" env:"SUSHIAPI_SERVER_PORT" flag:"port" usage:"define port of the server"`
+	TrustedProxies    []string      `yaml:"trustedProxies" toml:"trustedProxies" env:"SUSHIAPI_TRUSTED_PROXIES" flag:"trusted-proxies" usage:"comma separated CIDRs of proxies whose X-Forwarded-For is honoured"`
+	APIKeys           []string      `yaml:"apiKeys" toml:"apiKeys" env:"SUSHIAPI_API_KEYS" flag:"api-keys" secret:"true" usage:"comma separated API keys identifying the callers sending them in X-API-Key, the others are rate limited by IP"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" toml:"readTimeout" env:"SUSHIAPI_READ_TIMEOUT" flag:"read-timeout" usage:"maximum duration for reading a whole request"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" toml:"readHeaderTimeout" env:"SUSHIAPI_READ_HEADER_TIMEOUT" flag:"read-header-timeout" usage:"maximum duration for reading the request headers"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" toml:"writeTimeout" env:"SUSHIAPI_WRITE_TIMEOUT" flag:"write-timeout" usage:"maximum duration before timing out the response writes"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout" toml:"idleTimeout" env:"SUSHIAPI_IDLE_TIMEOUT" flag:"idle-timeout" usage:"maximum time to wait for the next request on keep-alive connections"`
+	MaxHeaderBytes    int           `yaml:"maxHeaderBytes" toml:"maxHeaderBytes" env:"SUSHIAPI_MAX_HEADER_BYTES" flag:"max-header-bytes" usage:"maximum size of the request headers"`
+	ShutdownDelay     time.Duration `yaml:"shutdownDelay" toml:"shutdownDelay" env:"SUSHIAPI_SHUTDOWN_DELAY" flag:"shutdown-delay" usage:"time /readyz fails before draining so load balancers stop routing"`
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout" toml:"shutdownTimeout" env:"SUSHIAPI_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"maximum time to drain the in-flight requests"`
+	CacheControl      string        `yaml:"cacheControl" toml:"cacheControl" env:"SUSHIAPI_CACHE_CONTROL" flag:"cache-control" usage:"Cache-Control of the successful responses per route, e.g. \"GET /sushi=public, max-age=60; GET /sushi/{ID}=no-cache\""`
+}
+
+// TLSConfig defines how the API is served over HTTPS
+type TLSConfig struct {
+	Cert             string        `yaml:"cert" toml:"cert" env:"SUSHIAPI_TLS_CERT" flag:"tls-cert" usage:"certificate file, the API is served over HTTPS when set"`
+	Key              string        `yaml:"key" toml:"key" env:"SUSHIAPI_TLS_KEY" flag:"tls-key" usage:"private key file of the certificate"`
+	ClientCA         string        `yaml:"clientCA" toml:"clientCA" env:"SUSHIAPI_CLIENT_CA" flag:"client-ca" usage:"CA bundle used to verify client certificates (mutual TLS)"`
+	ClientAuth       string        `yaml:"clientAuth" toml:"clientAuth" env:"SUSHIAPI_CLIENT_AUTH" flag:"client-auth" usage:"client certificates with -client-ca: optional or require"`
+	ReloadInterval   time.Duration `yaml:"reloadInterval" toml:"reloadInterval" env:"SUSHIAPI_TLS_RELOAD_INTERVAL" flag:"tls-reload-interval" usage:"how often certificate files are checked for changes"`
+	RedirectHTTPAddr string        `yaml:"redirectHTTPAddr" toml:"redirectHTTPAddr" env:"SUSHIAPI_REDIRECT_HTTP_ADDR" flag:"redirect-http-addr" usage:"address of a plain HTTP listener redirecting to HTTPS, e.g. :80"`
+}
+
+// CompressionConfig defines how the responses are compressed
+type CompressionConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"SUSHIAPI_COMPRESSION" flag:"compression" usage:"compress the responses with zstd, brotli or gzip as negotiated with Accept-Encoding"`
+	MinSize int  `yaml:"minSize" toml:"minSize" env:"SUSHIAPI_COMPRESSION_MIN_SIZE" flag:"compression-min-size" usage:"size in bytes below which responses aren't compressed"`
+}
+
+// CORSConfig defines which browser origins may call the API
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowedOrigins" toml:"allowedOrigins" env:"SUSHIAPI_CORS_ALLOWED_ORIGINS" flag:"cors-allowed-origins" usage:"comma separated origins allowed to call the API, * or a wildcard like https://*.example.com (CORS disabled if empty)"`
+	AllowedMethods   []string      `yaml:"allowedMethods" toml:"allowedMethods" env:"SUSHIAPI_CORS_ALLOWED_METHODS" flag:"cors-allowed-methods" usage:"comma separated methods allowed in CORS requests"`
+	AllowedHeaders   []string      `yaml:"allowedHeaders" toml:"allowedHeaders" env:"SUSHIAPI_CORS_ALLOWED_HEADERS" flag:"cors-allowed-headers" usage:"comma separated request headers allowed in CORS requests, * allows any"`
+	ExposedHeaders   []string      `yaml:"exposedHeaders" toml:"exposedHeaders" env:"SUSHIAPI_CORS_EXPOSED_HEADERS" flag:"cors-exposed-headers" usage:"comma separated response headers readable by the browser"`
+	AllowCredentials bool          `yaml:"allowCredentials" toml:"allowCredentials" env:"SUSHIAPI_CORS_ALLOW_CREDENTIALS" flag:"cors-allow-credentials" usage:"let the browsers send credentials in CORS requests"`
+	MaxAge           time.Duration `yaml:"maxAge" toml:"maxAge" env:"SUSHIAPI_CORS_MAX_AGE" flag:"cors-max-age" usage:"how long browsers may cache a preflight response"`
+}
+
+// LogConfig defines the logger
+type LogConfig struct {
+	Logger string `yaml:"logger" toml:"logger" env:"SUSHIAPI_LOGGER" flag:"logger" usage:"logger implementation: logrus or slog"`
+	Level  string `yaml:"level" toml:"level" env:"SUSHIAPI_LOG_LEVEL" flag:"log-level" usage:"minimum level logged: debug, info, warn or error"`
+	Format string `yaml:"format" toml:"format" env:"SUSHIAPI_LOG_FORMAT" flag:"log-format" usage:"log output format: text or json"`
+}
+
+// TracingConfig defines where the spans are exported
+type TracingConfig struct {
+	Exporter string `yaml:"exporter" toml:"exporter" env:"SUSHIAPI_TRACE_EXPORTER" flag:"trace-exporter" usage:"trace exporter: none, stdout or otlp"`
+	Endpoint string `yaml:"endpoint" toml:"endpoint" env:"SUSHIAPI_TRACE_ENDPOINT" flag:"trace-endpoint" usage:"host:port of the OTLP/HTTP collector"`
+	Insecure bool   `yaml:"insecure" toml:"insecure" env:"SUSHIAPI_TRACE_INSECURE" flag:"trace-insecure" usage:"send spans to the OTLP collector without TLS"`
+}
+
+// RateLimitConfig defines the limits applied per client
+type RateLimitConfig struct {
+	Default string `yaml:"default" toml:"default" env:"SUSHIAPI_RATE_LIMIT" flag:"rate-limit" usage:"default rate limit per client as rate:burst, e.g. 5:10 (disabled if empty)"`
+	Routes  string `yaml:"routes" toml:"routes" env:"SUSHIAPI_RATE_LIMIT_ROUTES" flag:"rate-limit-routes" usage:"rate limits per route, e.g. \"GET /sushi=5:10,POST /sushi=1:2\""`
+	Store   string `yaml:"store" toml:"store" env:"SUSHIAPI_RATE_LIMIT_STORE" flag:"rate-limit-store" usage:"store shared by the rate limiter: inmem or redis"`
+}
+
+// CacheConfig defines the cache of the sushis read by ID
+type CacheConfig struct {
+	Enabled  bool          `yaml:"enabled" toml:"enabled" env:"SUSHIAPI_CACHE" flag:"cache" usage:"cache the sushis read by ID"`
+	Size     int           `yaml:"size" toml:"size" env:"SUSHIAPI_CACHE_SIZE" flag:"cache-size" usage:"number of sushis kept by the in-process cache"`
+	TTL      time.Duration `yaml:"ttl" toml:"ttl" env:"SUSHIAPI_CACHE_TTL" flag:"cache-ttl" usage:"how long the in-process cache keeps a sushi"`
+	Redis    bool          `yaml:"redis" toml:"redis" env:"SUSHIAPI_CACHE_REDIS" flag:"cache-redis" usage:"share a second cache tier between the replicas in Redis"`
+	RedisTTL time.Duration `yaml:"redisTTL" toml:"redisTTL" env:"SUSHIAPI_CACHE_REDIS_TTL" flag:"cache-redis-ttl" usage:"how long the Redis cache keeps a sushi"`
+}
+
+// IdempotencyConfig defines how the responses of the requests carrying an
+// Idempotency-Key are kept for their retries
+type IdempotencyConfig struct {
+	Enabled bool          `yaml:"enabled" toml:"enabled" env:"SUSHIAPI_IDEMPOTENCY" flag:"idempotency" usage:"replay the responses of the writes retried with the same Idempotency-Key"`
+	Store   string        `yaml:"store" toml:"store" env:"SUSHIAPI_IDEMPOTENCY_STORE" flag:"idempotency-store" usage:"store of the idempotency keys: inmem or redis"`
+	TTL     time.Duration `yaml:"ttl" toml:"ttl" env:"SUSHIAPI_IDEMPOTENCY_TTL" flag:"idempotency-ttl" usage:"how long the response of an idempotency key is replayed"`
+	MaxBody int           `yaml:"maxBody" toml:"maxBody" env:"SUSHIAPI_IDEMPOTENCY_MAX_BODY" flag:"idempotency-max-body" usage:"size in bytes of the largest body of a request carrying an Idempotency-Key"`
+}
+
+// WebhooksConfig defines the delivery of the sushi events to the webhooks
+type WebhooksConfig struct {
+	Enabled    bool          `yaml:"enabled" toml:"enabled" env:"SUSHIAPI_WEBHOOKS" flag:"webhooks" usage:"deliver the sushi events to the webhooks registered on /webhooks"`
+	Workers    int           `yaml:"workers" toml:"workers" env:"SUSHIAPI_WEBHOOKS_WORKERS" flag:"webhooks-workers" usage:"number of webhook deliveries sent concurrently"`
+	Timeout    time.Duration `yaml:"timeout" toml:"timeout" env:"SUSHIAPI_WEBHOOKS_TIMEOUT" flag:"webhooks-timeout" usage:"timeout of every webhook delivery attempt"`
+	Attempts   int           `yaml:"attempts" toml:"attempts" env:"SUSHIAPI_WEBHOOKS_ATTEMPTS" flag:"webhooks-attempts" usage:"attempts before dead-lettering an event"`
+	Backoff    time.Duration `yaml:"backoff" toml:"backoff" env:"SUSHIAPI_WEBHOOKS_BACKOFF" flag:"webhooks-backoff" usage:"wait before the first retry, doubled after every failure"`
+	MaxBackoff time.Duration `yaml:"maxBackoff" toml:"maxBackoff" env:"SUSHIAPI_WEBHOOKS_MAX_BACKOFF" flag:"webhooks-max-backoff" usage:"longest wait between two attempts"`
+}
+
+// EventsConfig defines the stream of the sushi events on /sushi/events
+type EventsConfig struct {
+	Enabled   bool          `yaml:"enabled" toml:"enabled" env:"SUSHIAPI_EVENTS" flag:"events" usage:"stream the sushi events as Server-Sent Events on /sushi/events"`
+	History   int           `yaml:"history" toml:"history" env:"SUSHIAPI_EVENTS_HISTORY" flag:"events-history" usage:"number of events kept for the clients resuming with Last-Event-ID"`
+	Buffer    int           `yaml:"buffer" toml:"buffer" env:"SUSHIAPI_EVENTS_BUFFER" flag:"events-buffer" usage:"events buffered per client before disconnecting it"`
+	Heartbeat time.Duration `yaml:"heartbeat" toml:"heartbeat" env:"SUSHIAPI_EVENTS_HEARTBEAT" flag:"events-heartbeat" usage:"interval of the heartbeats keeping idle streams open"`
+}
+
+// WebSocketConfig defines the collaborative editing endpoint /sushi/ws
+type WebSocketConfig struct {
+	Enabled bool          `yaml:"enabled" toml:"enabled" env:"SUSHIAPI_WEBSOCKET" flag:"websocket" usage:"let the menu editors follow the changes and lock sushis on /sushi/ws"`
+	LockTTL time.Duration `yaml:"lockTTL" toml:"lockTTL" env:"SUSHIAPI_WEBSOCKET_LOCK_TTL" flag:"websocket-lock-ttl" usage:"longest an edit lock is held without being renewed"`
+}
+
+// GRPCConfig defines the gRPC server, listening on its own port
+type GRPCConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"SUSHIAPI_GRPC" flag:"grpc" usage:"serve the sushi service over gRPC too"`
+	Port    int  `yaml:"port" toml:"port" env:"SUSHIAPI_GRPC_PORT" flag:"grpc-port" usage:"port of the gRPC server, on the host of the HTTP server"`
+}
+
+// GraphQLConfig defines the GraphQL endpoint /graphql
+type GraphQLConfig struct {
+	Enabled       bool `yaml:"enabled" toml:"enabled" env:"SUSHIAPI_GRAPHQL" flag:"graphql" usage:"serve the sushi catalogue over GraphQL on /graphql"`
+	MaxDepth      int  `yaml:"maxDepth" toml:"maxDepth" env:"SUSHIAPI_GRAPHQL_MAX_DEPTH" flag:"graphql-max-depth" usage:"deepest nesting of fields of a GraphQL query, 0 for no limit"`
+	MaxComplexity int  `yaml:"maxComplexity" toml:"maxComplexity" env:"SUSHIAPI_GRAPHQL_MAX_COMPLEXITY" flag:"graphql-max-complexity" usage:"most fields resolved by a GraphQL query, 0 for no limit"`
+}
+
+// SeedConfig defines the fixtures loaded into the database at startup
+type SeedConfig struct {
+	Fixtures []string `yaml:"fixtures" toml:"fixtures" env:"SUSHIAPI_SEED" flag:"seed" usage:"comma separated JSON or YAML fixture files, or sample for the sample menu, loaded into the database at startup"`
+	Reset    bool     `yaml:"reset" toml:"reset" env:"SUSHIAPI_SEED_RESET" flag:"seed-reset" usage:"remove every sushi of the database before seeding it"`
+}
+
+// MySQLConfig defines the MySQL backend
+type MySQLConfig struct {
+	Addr            string `yaml:"addr" toml:"addr" env:"MYSQL_ADDR" flag:"mysql-addr" secret:"dsn" usage:"MySQL address, user:password@tcp(host:port)"`
+	DB              string `yaml:"db" toml:"db" env:"MYSQL_DB" flag:"mysql-db" usage:"MySQL database name"`
+	Table           string `yaml:"table" toml:"table" env:"MYSQL_TABLE" flag:"mysql-table" usage:"MySQL table storing the sushis"`
+	IngredientTable string `yaml:"ingredientTable" toml:"ingredientTable" env:"MYSQL_INGREDIENT_TABLE" flag:"mysql-ingredient-table" usage:"MySQL table storing the ingredient catalogue"`
+}
+
+// CockroachConfig defines the CockroachDB backend
+type CockroachConfig struct {
+	Addr string `yaml:"addr" toml:"addr" env:"COCKROACH_ADDR" flag:"cockroach-addr" secret:"dsn" usage:"CockroachDB address, user[:password]@host:port"`
+	DB   string `yaml:"db" toml:"db" env:"COCKROACH_DB" flag:"cockroach-db" usage:"CockroachDB database name"`
+}
+
+// RedisConfig defines the Redis server, used as backend or shared store
+type RedisConfig struct {
+	Addr string `yaml:"addr" toml:"addr" env:"REDIS_ADDR" flag:"redis-addr" usage:"Redis address, host:port"`
+}
+
+// Default returns the configuration used when nothing else is set
+func Default() Config {
+	return Config{
+		Database: "inmem",
+		Server: ServerConfig{
+			Name:              "SUSHIAPI",
+			Host:              "localhost",
+			Port:              3000,
+			ReadTimeout:       10 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownDelay:     5 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			CacheControl:      "GET /sushi=public, no-cache; GET /sushi/{ID}=public, no-cache",
+		},
+		TLS: TLSConfig{
+			ClientAuth:     "optional",
+			ReloadInterval: time.Minute,
+		},
+		Compression: CompressionConfig{
+			Enabled: true,
+			MinSize: 1024,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+			AllowedHeaders: []string{"Accept", "Content-Type", "Idempotency-Key", "Lock-Token", "X-API-Key", "X-Request-ID"},
+			ExposedHeaders: []string{"Idempotent-Replayed", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Request-ID"},
+			MaxAge:         10 * time.Minute,
+		},
+		Log: LogConfig{
+			Logger: "logrus",
+			Level:  "info",
+			Format: "text",
+		},
+		Tracing: TracingConfig{
+			Exporter: tracing.ExporterNone,
+			Endpoint: "localhost:4318",
+		},
+		RateLimit: RateLimitConfig{
+			Store: "inmem",
+		},
+		Cache: CacheConfig{
+			Size:     1000,
+			TTL:      30 * time.Second,
+			RedisTTL: 5 * time.Minute,
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled: true,
+			Store:   "inmem",
+			TTL:     24 * time.Hour,
+			MaxBody: 1 << 20,
+		},
+		Webhooks: WebhooksConfig{
+			Workers:    4,
+			Timeout:    5 * time.Second,
+			Attempts:   8,
+			Backoff:    time.Second,
+			MaxBackoff: 10 * time.Minute,
+		},
+		Events: EventsConfig{
+			Enabled:   true,
+			History:   1000,
+			Buffer:    64,
+			Heartbeat: 15 * time.Second,
+		},
+		WebSocket: WebSocketConfig{
+			Enabled: true,
+			LockTTL: time.Minute,
+		},
+		GRPC: GRPCConfig{
+			Port: 3001,
+		},
+		GraphQL: GraphQLConfig{
+			Enabled:       true,
+			MaxDepth:      10,
+			MaxComplexity: 2000,
+		},
+		MySQL: MySQLConfig{
+			Table:           "gophers",
+			IngredientTable: "ingredients",
+		},
+		Redis: RedisConfig{
+			Addr: "localhost:6379",
+		},
+	}
+}
+
+// Validate checks the configuration is consistent, reporting every problem
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(oneOf(c.Database, "inmem", "mysql", "cockroach", "redis"), "database %q must be inmem, mysql, cockroach or redis", c.Database)
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server port %d must be between 1 and 65535", c.Server.Port)
+	for _, cidr := range c.Server.TrustedProxies {
+		_, _, err := net.ParseCIDR(cidr)
+		check(err == nil, "trusted proxy %q must be a CIDR", cidr)
+	}
+	check(c.Server.ReadTimeout > 0 && c.Server.ReadHeaderTimeout > 0 && c.Server.WriteTimeout > 0 && c.Server.IdleTimeout > 0,
+		"server timeouts must be positive")
+	check(c.Server.MaxHeaderBytes > 0, "server max header bytes must be positive")
+	_, err := server.ParseCacheControl(c.Server.CacheControl)
+	check(err == nil, "%v", err)
+	check(c.Server.ShutdownDelay >= 0 && c.Server.ShutdownTimeout > 0, "shutdown delay can't be negative and timeout must be positive")
+
+	check((c.TLS.Cert == "") == (c.TLS.Key == ""), "tls cert and key must be set together")
+	check(c.TLS.ClientCA == "" || c.TLS.Cert != "", "tls client CA requires a certificate")
+	check(c.TLS.RedirectHTTPAddr == "" || c.TLS.Cert != "", "redirecting HTTP requires a certificate")
+	check(oneOf(c.TLS.ClientAuth, "optional", "require"), "tls client auth %q must be optional or require", c.TLS.ClientAuth)
+	check(c.TLS.ReloadInterval > 0, "tls reload interval must be positive")
+
+	check(c.Compression.MinSize >= 0, "compression min size can't be negative")
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		check(origin == "*" || (strings.Contains(origin, "://") && strings.Count(origin, "*") <= 1),
+			"cors origin %q must be * or scheme://host with at most one wildcard", origin)
+		check(origin != "*" || !c.CORS.AllowCredentials, "cors credentials can't be allowed to any origin")
+	}
+	check(len(c.CORS.AllowedOrigins) == 0 || len(c.CORS.AllowedMethods) > 0, "cors requires allowed methods")
+	check(c.CORS.MaxAge >= 0, "cors max age can't be negative")
+
+	check(oneOf(c.Log.Logger, "logrus", "slog"), "logger %q must be logrus or slog", c.Log.Logger)
+	_, err = log.ParseLevel(c.Log.Level)
+	check(err == nil, "%v", err)
+	_, err = log.ParseFormat(c.Log.Format)
+	check(err == nil, "%v", err)
+
+	check(oneOf(c.Tracing.Exporter, tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP),
+		"trace exporter %q must be none, stdout or otlp", c.Tracing.Exporter)
+	check(c.Tracing.Exporter != tracing.ExporterOTLP || c.Tracing.Endpoint != "", "the otlp trace exporter requires an endpoint")
+
+	if c.RateLimit.Default != "" {
+		_, err := ratelimit.ParseLimit(c.RateLimit.Default)
+		check(err == nil, "%v", err)
+	}
+	_, err = ratelimit.ParsePolicy(ratelimit.Limit{}, c.RateLimit.Routes)
+	check(err == nil, "%v", err)
+	check(oneOf(c.RateLimit.Store, "inmem", "redis"), "rate limit store %q must be inmem or redis", c.RateLimit.Store)
+
+	if c.Cache.Enabled {
+		check(c.Cache.Size > 0 && c.Cache.TTL > 0, "cache size and ttl must be positive")
+		check(!c.Cache.Redis || c.Cache.RedisTTL > 0, "cache redis ttl must be positive")
+	}
+
+	if c.Idempotency.Enabled {
+		check(oneOf(c.Idempotency.Store, "inmem", "redis"), "idempotency store %q must be inmem or redis", c.Idempotency.Store)
+		check(c.Idempotency.TTL > 0, "idempotency ttl must be positive")
+		check(c.Idempotency.MaxBody > 0, "idempotency max body must be positive")
+	}
+
+	if c.Webhooks.Enabled {
+		check(c.Webhooks.Workers > 0 && c.Webhooks.Attempts > 0, "webhooks workers and attempts must be positive")
+		check(c.Webhooks.Timeout > 0 && c.Webhooks.Backoff > 0, "webhooks timeout and backoff must be positive")
+		check(c.Webhooks.MaxBackoff >= c.Webhooks.Backoff, "webhooks max backoff can't be shorter than the backoff")
+	}
+
+	if c.Events.Enabled {
+		check(c.Events.History > 0 && c.Events.Buffer > 0, "events history and buffer must be positive")
+		check(c.Events.Heartbeat > 0, "events heartbeat must be positive")
+	}
+
+	if c.WebSocket.Enabled {
+		check(c.Events.Enabled, "the websocket requires the events")
+		check(c.WebSocket.LockTTL > 0, "websocket lock ttl must be positive")
+	}
+
+	if c.GRPC.Enabled {
+		check(c.GRPC.Port > 0 && c.GRPC.Port < 65536, "grpc port %d must be between 1 and 65535", c.GRPC.Port)
+		check(c.GRPC.Port != c.Server.Port, "the grpc server can't share the port of the http server")
+	}
+
+	if c.GraphQL.Enabled {
+		check(c.GraphQL.MaxDepth >= 0 && c.GraphQL.MaxComplexity >= 0, "graphql max depth and complexity can't be negative")
+	}
+
+	check(!c.Seed.Reset || len(c.Seed.Fixtures) > 0, "the seed reset requires fixtures")
+
+	switch c.Database {
+	case "mysql":
+		check(c.MySQL.Addr != "" && c.MySQL.DB != "" && c.MySQL.Table != "" && c.MySQL.IngredientTable != "", "the mysql database requires addr, db, table and ingredientTable")
+	case "cockroach":
+		check(c.Cockroach.Addr != "" && c.Cockroach.DB != "", "the cockroach database requires addr and db")
+	}
+	if c.Database == "redis" || c.RateLimit.Store == "redis" || (c.Cache.Enabled && c.Cache.Redis) ||
+		(c.Idempotency.Enabled && c.Idempotency.Store == "redis") {
+		check(c.Redis.Addr != "", "redis requires an addr")
+	}
+
+	return errors.Join(errs...)
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
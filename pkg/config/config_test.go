@@ -0,0 +1,257 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func load(t *testing.T, args []string, env map[string]string) (Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return Load(fs, args, func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load(t, nil, nil)
+	require.NoError(t, err)
+
+	hostName, _ := os.Hostname()
+	assert.Equal(t, "inmem", cfg.Database)
+	assert.Equal(t, 3000, cfg.Server.Port)
+	assert.Equal(t, "SUSHIAPI-"+hostName, cfg.Server.ID)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	file := writeFile(t, "sushi.yaml", `
+server:
+  host: file-host
+  port: 4000
+  readTimeout: 3s
+log:
+  level: debug
+`)
+	env := map[string]string{
+		FileEnv:                file,
+		"SUSHIAPI_SERVER_PORT": "5000",
+		"SUSHIAPI_SERVER_HOST": "",
+		"SUSHIAPI_LOG_LEVEL":   "warn",
+	}
+
+	cfg, err := load(t, []string{"-log-level", "error"}, env)
+	require.NoError(t, err)
+
+	assert.Equal(t, "file-host", cfg.Server.Host, "an empty variable doesn't override the file")
+	assert.Equal(t, 5000, cfg.Server.Port, "the environment overrides the file")
+	assert.Equal(t, "error", cfg.Log.Level, "the flags override the environment")
+	assert.Equal(t, 3*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, 5*time.Second, cfg.Server.ReadHeaderTimeout, "the defaults are kept")
+}
+
+func TestLoad_ConfigFlagOverridesEnv(t *testing.T) {
+	file := writeFile(t, "sushi.yaml", "database: redis\n")
+
+	cfg, err := load(t, []string{"-config", file}, map[string]string{FileEnv: "missing.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, "redis", cfg.Database)
+}
+
+func TestLoad_TOML(t *testing.T) {
+	file := writeFile(t, "sushi.toml", `
+database = "mysql"
+
+[server]
+trustedProxies = ["10.0.0.0/8"]
+shutdownDelay = "1s"
+
+[mysql]
+addr = "root:root@tcp(localhost:3306)"
+db = "sushiapi"
+`)
+
+	cfg, err := load(t, []string{"-config", file}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "mysql", cfg.Database)
+	assert.Equal(t, []string{"10.0.0.0/8"}, cfg.Server.TrustedProxies)
+	assert.Equal(t, time.Second, cfg.Server.ShutdownDelay)
+	assert.Equal(t, "gophers", cfg.MySQL.Table)
+	assert.Equal(t, "ingredients", cfg.MySQL.IngredientTable)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoad_UnknownKeys(t *testing.T) {
+	yamlFile := writeFile(t, "sushi.yaml", "server:\n  prot: 4000\n")
+	_, err := load(t, []string{"-config", yamlFile}, nil)
+	assert.Error(t, err)
+
+	tomlFile := writeFile(t, "sushi.toml", "[server]\nprot = 4000\n")
+	_, err = load(t, []string{"-config", tomlFile}, nil)
+	assert.Error(t, err)
+}
+
+func TestLoad_InvalidValues(t *testing.T) {
+	_, err := load(t, nil, map[string]string{"SUSHIAPI_SERVER_PORT": "http"})
+	assert.EqualError(t, err, `environment variable SUSHIAPI_SERVER_PORT: strconv.Atoi: parsing "http": invalid syntax`)
+
+	_, err = load(t, []string{"-read-timeout", "10"}, nil)
+	assert.Error(t, err)
+
+	_, err = load(t, []string{"-config", writeFile(t, "sushi.json", "{}")}, nil)
+	assert.Error(t, err)
+}
+
+func TestLoad_ListFlag(t *testing.T) {
+	cfg, err := load(t, []string{"-trusted-proxies", "10.0.0.0/8, 192.168.0.0/16,"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.0.0/16"}, cfg.Server.TrustedProxies)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.Database = "postgres"
+	cfg.Server.Port = 0
+	cfg.TLS.Key = "key.pem"
+	cfg.Log.Level = "verbose"
+	cfg.RateLimit.Default = "fast"
+	cfg.Events.Heartbeat = 0
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `database "postgres" must be inmem, mysql, cockroach or redis`)
+	assert.Contains(t, err.Error(), "server port 0 must be between 1 and 65535")
+	assert.Contains(t, err.Error(), "tls cert and key must be set together")
+	assert.Contains(t, err.Error(), "verbose")
+	assert.Contains(t, err.Error(), "fast")
+	assert.Contains(t, err.Error(), "events heartbeat must be positive")
+}
+
+func TestValidate_BackendSection(t *testing.T) {
+	cfg := Default()
+	cfg.Database = "cockroach"
+	assert.EqualError(t, cfg.Validate(), "the cockroach database requires addr and db")
+
+	cfg.Cockroach = CockroachConfig{Addr: "root@localhost:26257", DB: "sushiapi"}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_CORS(t *testing.T) {
+	cfg := Default()
+	cfg.CORS.AllowedOrigins = []string{"https://*.example.com", "menu.example.com"}
+	assert.EqualError(t, cfg.Validate(), `cors origin "menu.example.com" must be * or scheme://host with at most one wildcard`)
+
+	cfg.CORS.AllowedOrigins = []string{"*"}
+	cfg.CORS.AllowCredentials = true
+	assert.EqualError(t, cfg.Validate(), "cors credentials can't be allowed to any origin")
+}
+
+func TestValidate_Cache(t *testing.T) {
+	cfg := Default()
+	cfg.Database = "redis"
+	cfg.Cache.Enabled = true
+	cfg.Cache.Redis = true
+	assert.NoError(t, cfg.Validate(), "the keys of the database are apart from the cached copies")
+
+	cfg.Cache.Size = 0
+	assert.EqualError(t, cfg.Validate(), "cache size and ttl must be positive")
+}
+
+func TestValidate_Idempotency(t *testing.T) {
+	cfg := Default()
+	cfg.Idempotency.Store = "memcached"
+	cfg.Idempotency.TTL = 0
+	cfg.Idempotency.MaxBody = 0
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `idempotency store "memcached" must be inmem or redis`)
+	assert.Contains(t, err.Error(), "idempotency ttl must be positive")
+	assert.Contains(t, err.Error(), "idempotency max body must be positive")
+
+	cfg = Default()
+	cfg.Database = "redis"
+	cfg.Idempotency.Store = "redis"
+	cfg.RateLimit.Store = "redis"
+	assert.NoError(t, cfg.Validate(), "the keys of the database are apart from the idempotency keys and the rate limits")
+}
+
+func TestValidate_Webhooks(t *testing.T) {
+	cfg := Default()
+	cfg.Webhooks.Workers = 0
+	assert.NoError(t, cfg.Validate(), "disabled webhooks aren't validated")
+
+	cfg.Webhooks.Enabled = true
+	cfg.Webhooks.MaxBackoff = time.Millisecond
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhooks workers and attempts must be positive")
+	assert.Contains(t, err.Error(), "webhooks max backoff can't be shorter than the backoff")
+}
+
+func TestValidate_WebSocket(t *testing.T) {
+	cfg := Default()
+	cfg.Events.Enabled = false
+	assert.EqualError(t, cfg.Validate(), "the websocket requires the events")
+
+	cfg.WebSocket.Enabled = false
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_GRPC(t *testing.T) {
+	cfg := Default()
+	cfg.GRPC.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.GRPC.Port = cfg.Server.Port
+	assert.EqualError(t, cfg.Validate(), "the grpc server can't share the port of the http server")
+}
+
+func TestValidate_GraphQL(t *testing.T) {
+	cfg := Default()
+	cfg.GraphQL.MaxDepth = -1
+	assert.EqualError(t, cfg.Validate(), "graphql max depth and complexity can't be negative")
+
+	cfg.GraphQL.Enabled = false
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_Seed(t *testing.T) {
+	cfg := Default()
+	cfg.Seed.Reset = true
+	assert.EqualError(t, cfg.Validate(), "the seed reset requires fixtures")
+
+	cfg.Seed.Fixtures = []string{"sample"}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.MySQL.Addr = "root:s3cr3t@tcp(localhost:3306)"
+	cfg.Cockroach.Addr = "root@localhost:26257"
+	cfg.Server.APIKeys = []string{"kiosk-s3cr3t"}
+
+	redactedCfg := cfg.Redacted()
+	assert.Equal(t, "root:******@tcp(localhost:3306)", redactedCfg.MySQL.Addr)
+	assert.Equal(t, "root@localhost:26257", redactedCfg.Cockroach.Addr)
+	assert.Equal(t, []string{"******"}, redactedCfg.Server.APIKeys)
+	assert.Equal(t, []string{"kiosk-s3cr3t"}, cfg.Server.APIKeys, "the original is untouched")
+	assert.Equal(t, "root:s3cr3t@tcp(localhost:3306)", cfg.MySQL.Addr, "the original is untouched")
+	assert.NotContains(t, cfg.String(), "s3cr3t")
+}
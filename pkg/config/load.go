@@ -0,0 +1,203 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv is the environment variable pointing to the configuration file
+// when the -config flag isn't given
+const FileEnv = "SUSHIAPI_CONFIG"
+
+// Load builds the configuration from, in increasing order of precedence, the
+// defaults, the YAML or TOML file given by -config or SUSHIAPI_CONFIG, the
+// environment and the flags. The flags are registered on fs and parsed from
+// args, so the caller may register its own flags beforehand.
+func Load(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+
+	file := fs.String("config", "", "YAML or TOML configuration file (env "+FileEnv+")")
+	flags := make(map[string]*flagValue)
+	walk(reflect.ValueOf(&cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		name := field.Tag.Get("flag")
+		if name == "" {
+			return
+		}
+		usage := field.Tag.Get("usage")
+		if env := field.Tag.Get("env"); env != "" {
+			usage += " (env " + env + ")"
+		}
+		flags[name] = &flagValue{kind: value.Type(), def: format(value)}
+		fs.Var(flags[name], name, usage)
+	})
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if *file == "" {
+		*file, _ = lookupEnv(FileEnv)
+	}
+	if *file != "" {
+		if err := loadFile(*file, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var err error
+	walk(reflect.ValueOf(&cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		env := field.Tag.Get("env")
+		if raw, ok := lookupEnv(env); env != "" && ok && raw != "" && err == nil {
+			if setErr := set(value, raw); setErr != nil {
+				err = fmt.Errorf("environment variable %s: %w", env, setErr)
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		if _, ok := flags[f.Name]; !ok {
+			return
+		}
+		walk(reflect.ValueOf(&cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+			if field.Tag.Get("flag") == f.Name {
+				// the value was already checked while parsing the flags
+				_ = set(value, flags[f.Name].raw)
+			}
+		})
+	})
+
+	if cfg.Server.ID == "" {
+		hostName, _ := os.Hostname()
+		cfg.Server.ID = fmt.Sprintf("%s-%s", cfg.Server.Name, hostName)
+	}
+
+	return cfg, nil
+}
+
+func loadFile(file string, cfg *Config) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config file %s: %w", file, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(content), cfg)
+		if err != nil {
+			return fmt.Errorf("config file %s: %w", file, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config file %s: unknown keys %v", file, undecoded)
+		}
+	default:
+		return fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", file)
+	}
+
+	return nil
+}
+
+// walk calls fn for every leaf field of the struct v, descending into sections
+func walk(v reflect.Value, fn func(field reflect.StructField, value reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		if value.Kind() == reflect.Struct {
+			walk(value, fn)
+			continue
+		}
+		fn(field, value)
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set parses raw into value according to its type
+func set(value reflect.Value, raw string) error {
+	switch {
+	case value.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+	case value.Kind() == reflect.Int:
+		i, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(i))
+	case value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case value.Kind() == reflect.String:
+		value.SetString(raw)
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+// format writes value the way set parses it
+func format(value reflect.Value) string {
+	switch {
+	case value.Type() == durationType:
+		return time.Duration(value.Int()).String()
+	case value.Kind() == reflect.Slice:
+		return strings.Join(value.Interface().([]string), ",")
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+// flagValue keeps the raw value of a flag until the lower layers are loaded
+type flagValue struct {
+	kind reflect.Type
+	def  string
+	raw  string
+}
+
+func (f *flagValue) String() string {
+	return f.def
+}
+
+func (f *flagValue) Set(raw string) error {
+	if err := set(reflect.New(f.kind).Elem(), raw); err != nil {
+		return err
+	}
+	f.raw = raw
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be given without a value
+func (f *flagValue) IsBoolFlag() bool {
+	return f.kind != nil && f.kind.Kind() == reflect.Bool
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "******"
+
+// Redacted returns a copy of the configuration safe to print: fields tagged
+// secret:"true" are masked, every element of the lists, and fields tagged
+// secret:"dsn" keep everything but the password of their user info
+func (c Config) Redacted() Config {
+	walk(reflect.ValueOf(&c).Elem(), func(field reflect.StructField, value reflect.Value) {
+		if value.Kind() == reflect.Slice && value.Len() > 0 && field.Tag.Get("secret") == "true" {
+			// a new list, the copy shares the elements of the original
+			masked := make([]string, value.Len())
+			for i := range masked {
+				masked[i] = redacted
+			}
+			value.Set(reflect.ValueOf(masked))
+			return
+		}
+		if value.Kind() != reflect.String || value.String() == "" {
+			return
+		}
+		switch field.Tag.Get("secret") {
+		case "true":
+			value.SetString(redacted)
+		case "dsn":
+			value.SetString(redactDSN(value.String()))
+		}
+	})
+	return c
+}
+
+// String prints the redacted configuration as YAML
+func (c Config) String() string {
+	out, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
+
+// redactDSN masks the password in addresses like user:password@tcp(host:port)
+func redactDSN(dsn string) string {
+	at := strings.LastIndex(dsn, "@")
+	if at < 0 {
+		return dsn
+	}
+	userInfo := dsn[:at]
+	if colon := strings.Index(userInfo, ":"); colon >= 0 {
+		userInfo = userInfo[:colon+1] + redacted
+	}
+	return userInfo + dsn[at:]
+}
@@ -0,0 +1,125 @@
+// Package dietary tells what the ingredients of the sushis contain, so the
+// sushis can be labelled with their allergens and diets
+package dietary
+
+import (
+	"strings"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+// Catalogue holds what the ingredients contain, by name ignoring case
+type Catalogue struct {
+	infos map[string]sushi.IngredientInfo
+}
+
+// NewCatalogue creates a catalogue of the ingredients, the later of the
+// names equal ignoring case wins
+func NewCatalogue(infos map[string]sushi.IngredientInfo) *Catalogue {
+	c := &Catalogue{infos: make(map[string]sushi.IngredientInfo, len(infos))}
+	for name, info := range infos {
+		c.infos[key(name)] = info
+	}
+	return c
+}
+
+// Lookup returns what the ingredient contains, false when it's unknown
+func (c *Catalogue) Lookup(name string) (sushi.IngredientInfo, bool) {
+	info, ok := c.infos[key(name)]
+	return info, ok
+}
+
+// FromIngredients creates a catalogue of the ingredients, found by ID as the
+// sushis reference them or by name as they used to
+func FromIngredients(ingredients []sushi.Ingredient) *Catalogue {
+	c := &Catalogue{infos: make(map[string]sushi.IngredientInfo, 2*len(ingredients))}
+	for _, i := range ingredients {
+		c.infos[key(i.Name)] = i.IngredientInfo
+	}
+	// the IDs win over the names
+	for _, i := range ingredients {
+		c.infos[key(i.ID)] = i.IngredientInfo
+	}
+	return c
+}
+
+// Labels computes the labels of the sushi
+func (c *Catalogue) Labels(s sushi.Sushi) sushi.Labels {
+	return sushi.ComputeLabels(s.Ingredients, c.Lookup)
+}
+
+func key(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+var (
+	plant      = sushi.IngredientInfo{Vegetarian: true, Vegan: true}
+	vegetarian = sushi.IngredientInfo{Vegetarian: true}
+	cooked     = sushi.IngredientInfo{}
+	raw        = sushi.IngredientInfo{Allergens: []sushi.Allergen{sushi.Fish}, RawFish: true}
+)
+
+func contains(info sushi.IngredientInfo, allergens ...sushi.Allergen) sushi.IngredientInfo {
+	info.Allergens = allergens
+	return info
+}
+
+// Default returns the catalogue of the usual ingredients of the menu
+func Default() *Catalogue {
+	return FromIngredients(Ingredients())
+}
+
+// Ingredients returns the usual ingredients of the menu, their IDs are their
+// names in snake case. The sauces and batters are labelled with what their
+// common recipes contain.
+func Ingredients() []sushi.Ingredient {
+	return []sushi.Ingredient{
+		ingredient("Rice", plant),
+		ingredient("Nori", plant),
+		ingredient("Crispy seaweed", plant),
+		ingredient("Avocado", plant),
+		ingredient("Cucumber", plant),
+		ingredient("Carrot", plant),
+		ingredient("Asparagus", plant),
+		ingredient("Mango", plant),
+		ingredient("Spring onion", plant),
+		ingredient("Shiitake", plant),
+		ingredient("Pickled ginger", plant),
+		ingredient("Sesame seeds", contains(plant, sushi.Sesame)),
+		ingredient("Tofu", contains(plant, sushi.Soybeans)),
+		ingredient("Inari", contains(plant, sushi.Soybeans)),
+		ingredient("Soy sauce", contains(plant, sushi.Soybeans, sushi.Gluten)),
+		ingredient("Wasabi", contains(plant, sushi.Mustard)),
+		ingredient("Peanuts", contains(plant, sushi.Peanuts)),
+
+		ingredient("Tamago", contains(vegetarian, sushi.Eggs, sushi.Soybeans)),
+		ingredient("Cream cheese", contains(vegetarian, sushi.Milk)),
+		ingredient("Mayonnaise", contains(vegetarian, sushi.Eggs, sushi.Mustard)),
+		ingredient("Tempura", contains(vegetarian, sushi.Gluten, sushi.Eggs)),
+
+		ingredient("Salmon", raw),
+		ingredient("Tuna", raw),
+		ingredient("Yellowtail", raw),
+		ingredient("Mackerel", raw),
+		ingredient("Tobiko", raw),
+		ingredient("Ikura", raw),
+		ingredient("Spicy tuna", contains(raw, sushi.Fish, sushi.Eggs, sushi.Mustard)),
+		ingredient("Eel", contains(cooked, sushi.Fish, sushi.Soybeans, sushi.Gluten)),
+		ingredient("Smoked salmon", contains(cooked, sushi.Fish)),
+
+		ingredient("Crab", contains(cooked, sushi.Crustaceans)),
+		ingredient("Shrimp", contains(cooked, sushi.Crustaceans)),
+		ingredient("Lobster", contains(cooked, sushi.Crustaceans)),
+		ingredient("Shrimp tempura", contains(cooked, sushi.Crustaceans, sushi.Gluten, sushi.Eggs)),
+		ingredient("Surimi", contains(cooked, sushi.Fish, sushi.Gluten, sushi.Eggs)),
+
+		ingredient("Octopus", contains(cooked, sushi.Molluscs)),
+		ingredient("Squid", contains(raw, sushi.Molluscs)),
+		ingredient("Scallop", contains(raw, sushi.Molluscs)),
+	}
+}
+
+func ingredient(name string, info sushi.IngredientInfo) sushi.Ingredient {
+	ID := strings.ReplaceAll(strings.ToLower(name), " ", "_")
+	return *sushi.NewIngredient(ID, name, info)
+}
@@ -0,0 +1,32 @@
+package dietary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergiorra/sushi-api-go/cmd/sample-data"
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+func Test_Lookup(t *testing.T) {
+	c := NewCatalogue(map[string]sushi.IngredientInfo{" Soy Sauce ": {Allergens: []sushi.Allergen{sushi.Soybeans}}})
+
+	info, ok := c.Lookup("soy sauce")
+	assert.True(t, ok)
+	assert.Equal(t, []sushi.Allergen{sushi.Soybeans}, info.Allergens)
+
+	_, ok = c.Lookup("Ponzu")
+	assert.False(t, ok)
+}
+
+func Test_Default_Sample(t *testing.T) {
+	c := Default()
+	for ID, s := range sample.Sushis {
+		assert.Empty(t, c.Labels(s).Unknown, "ingredients of %s", ID)
+	}
+
+	labels := c.Labels(sample.Sushis["01D3XZ38KDR"])
+	assert.Equal(t, []sushi.Allergen{sushi.Crustaceans, sushi.Sesame}, labels.Allergens)
+	assert.Equal(t, []sushi.Diet{sushi.GlutenFree}, labels.Diets)
+}
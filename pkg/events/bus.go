@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrOverflow ends the subscriptions that didn't keep up with the events
+	ErrOverflow = errors.New("the subscriber fell behind")
+	// ErrClosed ends the subscriptions of a closed bus
+	ErrClosed = errors.New("the event bus is closed")
+)
+
+// Subscription receives the events published on a Bus until it ends
+type Subscription struct {
+	events chan Event
+	err    error
+}
+
+// Events is closed when the subscription ends, Err tells why
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns why the subscription ended, once Events is closed
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Bus is an in-process Publisher fanning out the events to its subscribers.
+// It remembers the latest events so subscribers can resume after a
+// disconnection; a subscriber whose buffer is full is dropped rather than
+// slowing down the writes, it can resume from its last event.
+type Bus struct {
+	mtx         sync.Mutex
+	history     []Event
+	historySize int
+	bufferSize  int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewBus creates a Bus remembering historySize events and buffering up to
+// bufferSize events per subscriber
+func NewBus(historySize, bufferSize int) *Bus {
+	return &Bus{
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish satisfies the Publisher interface
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.history = append(b.history, event)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for s := range b.subscribers {
+		select {
+		case s.events <- event:
+		default:
+			b.end(s, ErrOverflow)
+		}
+	}
+}
+
+// Subscribe starts receiving the events published from now on. When
+// lastEventID is given, the events published after it are returned to be
+// sent first; resumed is false when that event has been forgotten, events may
+// have been missed then.
+func (b *Bus) Subscribe(lastEventID string) (s *Subscription, missed []Event, resumed bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	s = &Subscription{events: make(chan Event, b.bufferSize)}
+	if b.closed {
+		s.err = ErrClosed
+		close(s.events)
+		return s, nil, true
+	}
+	b.subscribers[s] = struct{}{}
+
+	if lastEventID == "" {
+		return s, nil, true
+	}
+	for i := len(b.history) - 1; i >= 0; i-- {
+		if b.history[i].ID == lastEventID {
+			return s, append([]Event{}, b.history[i+1:]...), true
+		}
+	}
+	return s, nil, false
+}
+
+// Unsubscribe ends the subscription
+func (b *Bus) Unsubscribe(s *Subscription) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if _, ok := b.subscribers[s]; ok {
+		b.end(s, nil)
+	}
+}
+
+// Close ends every subscription, the events published afterwards are dropped
+func (b *Bus) Close() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.closed = true
+	for s := range b.subscribers {
+		b.end(s, ErrClosed)
+	}
+}
+
+func (b *Bus) end(s *Subscription, err error) {
+	delete(b.subscribers, s)
+	s.err = err
+	close(s.events)
+}
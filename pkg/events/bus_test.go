@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Bus_Publish(t *testing.T) {
+	bus := NewBus(10, 10)
+	a, _, _ := bus.Subscribe("")
+	b, _, _ := bus.Subscribe("")
+
+	event := New(SushiCreated, "01D3XZ38KDR", nil)
+	bus.Publish(context.Background(), event)
+
+	assert.Equal(t, event, <-a.Events())
+	assert.Equal(t, event, <-b.Events())
+
+	bus.Unsubscribe(a)
+	_, ok := <-a.Events()
+	assert.False(t, ok)
+	assert.NoError(t, a.Err())
+}
+
+func Test_Bus_Resume(t *testing.T) {
+	bus := NewBus(2, 10)
+	first := New(SushiCreated, "1", nil)
+	second := New(SushiModified, "1", nil)
+	third := New(SushiRemoved, "1", nil)
+	for _, event := range []Event{first, second, third} {
+		bus.Publish(context.Background(), event)
+	}
+
+	_, missed, resumed := bus.Subscribe(second.ID)
+	assert.True(t, resumed)
+	assert.Equal(t, []Event{third}, missed)
+
+	_, missed, resumed = bus.Subscribe(third.ID)
+	assert.True(t, resumed)
+	assert.Empty(t, missed)
+
+	_, missed, resumed = bus.Subscribe(first.ID)
+	assert.False(t, resumed, "the first event was forgotten")
+	assert.Empty(t, missed)
+}
+
+func Test_Bus_Overflow(t *testing.T) {
+	bus := NewBus(10, 1)
+	slow, _, _ := bus.Subscribe("")
+
+	bus.Publish(context.Background(), New(SushiCreated, "1", nil))
+	bus.Publish(context.Background(), New(SushiCreated, "2", nil))
+
+	event, ok := <-slow.Events()
+	require.True(t, ok, "the buffered event is still received")
+	assert.Equal(t, "1", event.SushiID)
+	_, ok = <-slow.Events()
+	assert.False(t, ok)
+	assert.ErrorIs(t, slow.Err(), ErrOverflow)
+}
+
+func Test_Bus_Close(t *testing.T) {
+	bus := NewBus(10, 10)
+	s, _, _ := bus.Subscribe("")
+
+	bus.Close()
+	_, ok := <-s.Events()
+	assert.False(t, ok)
+	assert.ErrorIs(t, s.Err(), ErrClosed)
+
+	late, _, _ := bus.Subscribe("")
+	_, ok = <-late.Events()
+	assert.False(t, ok, "a closed bus ends the new subscriptions")
+	bus.Unsubscribe(late)
+}
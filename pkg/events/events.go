@@ -0,0 +1,81 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+// Type identifies what happened to a sushi
+type Type string
+
+// Lifecycle events of the sushis
+const (
+	SushiCreated  Type = "sushi.created"
+	SushiModified Type = "sushi.modified"
+	SushiRemoved  Type = "sushi.removed"
+)
+
+// Types lists every event type, in the order of the lifecycle
+var Types = []Type{SushiCreated, SushiModified, SushiRemoved}
+
+// Valid tells whether the type is a known event type
+func (t Type) Valid() bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a change of the menu, published once it has been stored
+type Event struct {
+	ID      string       `json:"id"`
+	Type    Type         `json:"type"`
+	Time    time.Time    `json:"time"`
+	SushiID string       `json:"sushiId"`
+	Sushi   *sushi.Sushi `json:"sushi,omitempty"`
+}
+
+// New creates an event with a unique ID, s is nil for removals
+func New(t Type, sushiID string, s *sushi.Sushi) Event {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return Event{ID: hex.EncodeToString(b), Type: t, Time: time.Now().UTC(), SushiID: sushiID, Sushi: s}
+}
+
+// Publisher notifies the events to whoever is interested. Publish mustn't
+// block the write that produced the event nor fail it.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+type noop struct{}
+
+// NewNoopPublisher creates a Publisher dropping every event
+func NewNoopPublisher() Publisher {
+	return noop{}
+}
+
+// Publish satisfies the Publisher interface
+func (noop) Publish(ctx context.Context, event Event) {
+	// nothing to do here, use for test
+}
+
+type multi []Publisher
+
+// NewMultiPublisher creates a Publisher notifying every given publisher
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	return multi(publishers)
+}
+
+// Publish satisfies the Publisher interface
+func (m multi) Publish(ctx context.Context, event Event) {
+	for _, publisher := range m {
+		publisher.Publish(ctx, event)
+	}
+}
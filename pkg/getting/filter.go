@@ -0,0 +1,104 @@
+package getting
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+// Filter restricts the sushis listed, the zero value matches them all
+type Filter struct {
+	// PriceMin and PriceMax bound the price in minor units of the Currency,
+	// the sushis without a price are left out when either is set
+	PriceMin *int64
+	PriceMax *int64
+	// Currency only keeps the sushis priced in this currency, the price
+	// bounds require it
+	Currency string
+	// Restaurant picks the prices of the restaurant, the base prices when
+	// empty
+	Restaurant string
+	// ExcludeAllergens leaves out the sushis containing any of the
+	// allergens or ingredients nothing is known about, and those without
+	// ingredients, whose allergens are unknown
+	ExcludeAllergens []sushi.Allergen
+	// Diets only keeps the sushis labelled with all the diets
+	Diets []sushi.Diet
+}
+
+// ErrInvalidFilter is returned when the filter can't match anything sensible
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Validate checks the bounds of the price, the allergens and the diets
+func (f Filter) Validate() error {
+	for _, a := range f.ExcludeAllergens {
+		if _, err := sushi.ParseAllergen(string(a)); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+	}
+	for _, d := range f.Diets {
+		if _, err := sushi.ParseDiet(string(d)); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+	}
+	if f.PriceMin != nil && *f.PriceMin < 0 || f.PriceMax != nil && *f.PriceMax < 0 {
+		return fmt.Errorf("%w: the price bounds can't be negative", ErrInvalidFilter)
+	}
+	if f.PriceMin != nil && f.PriceMax != nil && *f.PriceMin > *f.PriceMax {
+		return fmt.Errorf("%w: priceMin can't exceed priceMax", ErrInvalidFilter)
+	}
+	if (f.PriceMin != nil || f.PriceMax != nil) && f.Currency == "" {
+		return fmt.Errorf("%w: the price bounds require a currency", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// Matches tells whether the sushi passes the filter, the allergens and the
+// diets are those of its labels
+func (f Filter) Matches(s sushi.Sushi) bool {
+	return f.matchesLabels(s) && f.matchesPrice(s)
+}
+
+func (f Filter) matchesLabels(s sushi.Sushi) bool {
+	if len(f.ExcludeAllergens) == 0 && len(f.Diets) == 0 {
+		return true
+	}
+	labels := s.Labels
+	if labels == nil {
+		return false
+	}
+	if len(f.ExcludeAllergens) > 0 && (len(s.Ingredients) == 0 || len(labels.Unknown) > 0) {
+		return false
+	}
+	for _, a := range f.ExcludeAllergens {
+		if slices.Contains(labels.Allergens, a) {
+			return false
+		}
+	}
+	for _, d := range f.Diets {
+		if !slices.Contains(labels.Diets, d) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Filter) matchesPrice(s sushi.Sushi) bool {
+	if f.PriceMin == nil && f.PriceMax == nil && f.Currency == "" {
+		return true
+	}
+	price := s.PriceAt(f.Restaurant)
+	switch {
+	case price == nil:
+		return false
+	case f.Currency != "" && price.Currency != f.Currency:
+		return false
+	case f.PriceMin != nil && price.Amount < *f.PriceMin:
+		return false
+	case f.PriceMax != nil && price.Amount > *f.PriceMax:
+		return false
+	}
+	return true
+}
@@ -2,41 +2,192 @@ package getting
 
 import (
 	"context"
+	"errors"
+	"iter"
 
 	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/dietary"
 	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/tracing"
+	"go.opentelemetry.io/otel"
 )
 
 // Service provides getting operations
 type Service interface {
 	GetSushis(ctx context.Context) ([]sushi.Sushi, error)
+	StreamSushis(ctx context.Context, filter Filter) iter.Seq2[sushi.Sushi, error]
+	Version(ctx context.Context) (sushi.Version, error)
 	GetSushiByID(ctx context.Context, ID string) *sushi.Sushi
 }
 
+var tracer = otel.Tracer("github.com/sergiorra/sushi-api-go/pkg/getting")
+
 type service struct {
-	repository sushi.Repository
-	logger     log.Logger
+	repository  sushi.Repository
+	logger      log.Logger
+	ingredients sushi.IngredientRepository
+}
+
+// Option configures the getting service
+type Option func(*service)
+
+// WithIngredients labels the sushis from the ingredient catalogue they
+// reference, rather than from the usual ingredients of dietary
+func WithIngredients(ingredients sushi.IngredientRepository) Option {
+	return func(s *service) {
+		s.ingredients = ingredients
+	}
 }
 
-// NewService creates a getting service with the necessary dependencies
-func NewService(repository sushi.Repository, logger log.Logger) Service {
-	return &service{repository, logger}
+// NewService creates a getting service with the necessary dependencies. The
+// sushis it returns are labelled with their allergens and diets.
+func NewService(repository sushi.Repository, logger log.Logger, opts ...Option) Service {
+	s := &service{repository: repository, logger: logger}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// catalogue returns what the ingredients contain, read once per call. The
+// sushis are labelled as made of unknown ingredients when the catalogue
+// can't be read.
+func (s *service) catalogue(ctx context.Context) *dietary.Catalogue {
+	if s.ingredients == nil {
+		return defaultCatalogue
+	}
+	ingredients, err := s.ingredients.GetIngredients(ctx)
+	if err != nil {
+		tracing.Fail(ctx, err)
+		s.logger.UnexpectedError(ctx, err)
+	}
+	return dietary.FromIngredients(ingredients)
+}
+
+// ingredientsOf returns what the ingredients of the sushi contain, looking up
+// only them rather than reading the whole catalogue
+func (s *service) ingredientsOf(ctx context.Context, g *sushi.Sushi) *dietary.Catalogue {
+	if s.ingredients == nil {
+		return defaultCatalogue
+	}
+	ingredients := make([]sushi.Ingredient, 0, len(g.Ingredients))
+	for _, ID := range g.Ingredients {
+		i, err := s.ingredients.GetIngredientByID(ctx, ID)
+		if errors.Is(err, sushi.ErrIngredientNotFound) {
+			continue
+		}
+		if err != nil {
+			tracing.Fail(ctx, err)
+			s.logger.UnexpectedError(ctx, err)
+			return dietary.FromIngredients(nil)
+		}
+		ingredients = append(ingredients, *i)
+	}
+	return dietary.FromIngredients(ingredients)
+}
+
+var defaultCatalogue = dietary.Default()
+
+// label sets the labels of the sushi
+func label(g *sushi.Sushi, catalogue *dietary.Catalogue) {
+	labels := catalogue.Labels(*g)
+	g.Labels = &labels
 }
 
 // GetSushis returns all sushis
 func (s *service) GetSushis(ctx context.Context) ([]sushi.Sushi, error) {
-	return s.repository.GetSushis(ctx)
+	ctx, span := tracer.Start(ctx, "getting.GetSushis")
+	defer span.End()
+
+	sushis, err := s.repository.GetSushis(ctx)
+	if err != nil {
+		tracing.Fail(ctx, err)
+		s.logger.RepositoryUnavailable(ctx, err)
+		return nil, err
+	}
+
+	catalogue := s.catalogue(ctx)
+	for i := range sushis {
+		label(&sushis[i], catalogue)
+	}
+	return sushis, nil
+}
+
+// StreamSushis yields the sushis matching the filter one at a time, stopping
+// after the first error
+func (s *service) StreamSushis(ctx context.Context, filter Filter) iter.Seq2[sushi.Sushi, error] {
+	return func(yield func(sushi.Sushi, error) bool) {
+		ctx, span := tracer.Start(ctx, "getting.StreamSushis")
+		defer span.End()
+
+		catalogue := s.catalogue(ctx)
+		for g, err := range sushi.Stream(ctx, s.repository) {
+			if err != nil {
+				tracing.Fail(ctx, err)
+				s.logger.RepositoryUnavailable(ctx, err)
+				yield(sushi.Sushi{}, err)
+				return
+			}
+			label(&g, catalogue)
+			if !filter.Matches(g) {
+				continue
+			}
+			if !yield(g, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Version returns the version of the catalogue, sushi.ErrUnversioned when
+// the repository can't tell it. The labels depend on the ingredients, their
+// version is part of it.
+func (s *service) Version(ctx context.Context) (sushi.Version, error) {
+	ctx, span := tracer.Start(ctx, "getting.Version")
+	defer span.End()
+
+	version, err := sushi.CurrentVersion(ctx, s.repository)
+	if err != nil && !errors.Is(err, sushi.ErrUnversioned) {
+		tracing.Fail(ctx, err)
+		s.logger.RepositoryUnavailable(ctx, err)
+	}
+	if err != nil || s.ingredients == nil {
+		return version, err
+	}
+
+	versioner, ok := s.ingredients.(sushi.Versioner)
+	if !ok {
+		return sushi.Version{}, sushi.ErrUnversioned
+	}
+	ingredients, err := versioner.Version(ctx)
+	if err != nil && !errors.Is(err, sushi.ErrUnversioned) {
+		tracing.Fail(ctx, err)
+		s.logger.UnexpectedError(ctx, err)
+	}
+	if err != nil {
+		return sushi.Version{}, err
+	}
+	version.Tag += "-" + ingredients.Tag
+	if ingredients.Modified.After(version.Modified) {
+		version.Modified = ingredients.Modified
+	}
+	return version, nil
 }
 
 // GetSushiByID returns a sushi
 func (s *service) GetSushiByID(ctx context.Context, ID string) *sushi.Sushi {
+	ctx, span := tracer.Start(ctx, "getting.GetSushiByID")
+	defer span.End()
+
 	g, err := s.repository.GetSushiByID(ctx, ID)
 
 	if err != nil {
+		tracing.Fail(ctx, err)
 		s.logger.UnexpectedError(ctx, err)
 		return nil
 	}
 
+	label(g, s.ingredientsOf(ctx, g))
 	return g
 }
 
@@ -0,0 +1,159 @@
+// Package graphql exposes the sushi catalogue as a GraphQL schema on top of
+// the same services as the REST API, whatever the transport
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"github.com/sergiorra/sushi-api-go/pkg/adding"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/getting"
+	"github.com/sergiorra/sushi-api-go/pkg/modifying"
+	"github.com/sergiorra/sushi-api-go/pkg/removing"
+)
+
+// Kinds of operation
+const (
+	KindQuery        = ast.OperationTypeQuery
+	KindMutation     = ast.OperationTypeMutation
+	KindSubscription = ast.OperationTypeSubscription
+)
+
+// Result is the response to an operation, errors included
+type Result = gql.Result
+
+// Limits bound the cost of the operations, zero meaning no limit
+type Limits struct {
+	// MaxDepth is the deepest nesting of fields
+	MaxDepth int
+	// MaxComplexity is the most fields resolved, the fields of the lists
+	// counting once per expected item
+	MaxComplexity int
+}
+
+// ErrorResult is the result of a request rejected before being executed
+func ErrorResult(err error) *Result {
+	return &Result{Errors: gqlerrors.FormatErrors(err)}
+}
+
+// Request is a GraphQL request, as posted by the clients
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Schema executes the operations on the sushi catalogue
+type Schema struct {
+	schema gql.Schema
+	limits Limits
+}
+
+// NewSchema creates the schema. The subscriptions follow the events of the
+// bus, there are none when bus is nil.
+func NewSchema(gS getting.Service, aS adding.Service, mS modifying.Service, rS removing.Service, bus *events.Bus, limits Limits) (*Schema, error) {
+	r := &resolver{getting: gS, adding: aS, modifying: mS, removing: rS, bus: bus}
+	schema, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{schema: schema, limits: limits}, nil
+}
+
+// Operation is a parsed and validated request, ready to be executed
+type Operation struct {
+	schema    *Schema
+	document  *ast.Document
+	operation *ast.OperationDefinition
+	request   Request
+}
+
+// Parse checks the request against the schema and the limits. When it's
+// rejected the returned result holds the errors.
+func (s *Schema) Parse(req Request) (*Operation, *Result) {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return nil, ErrorResult(err)
+	}
+
+	validation := gql.ValidateDocument(&s.schema, document, nil)
+	if !validation.IsValid {
+		return nil, &Result{Errors: validation.Errors}
+	}
+
+	operation, err := selectOperation(document, req.OperationName)
+	if err != nil {
+		return nil, ErrorResult(err)
+	}
+
+	if operation.Operation == KindSubscription && s.schema.SubscriptionType() == nil {
+		return nil, ErrorResult(errors.New("the subscriptions are disabled"))
+	}
+
+	if err := s.checkLimits(document, operation, req.Variables); err != nil {
+		return nil, ErrorResult(err)
+	}
+
+	return &Operation{schema: s, document: document, operation: operation, request: req}, nil
+}
+
+// Kind tells whether the operation is a query, a mutation or a subscription
+func (o *Operation) Kind() string {
+	return o.operation.Operation
+}
+
+// Execute runs a query or a mutation
+func (o *Operation) Execute(ctx context.Context) *Result {
+	return gql.Execute(o.params(ctx))
+}
+
+// Subscribe runs a subscription, a result is sent for every event until ctx
+// is done or the events end. The channel must be drained until it's closed.
+func (o *Operation) Subscribe(ctx context.Context) <-chan *Result {
+	return gql.ExecuteSubscription(o.params(ctx))
+}
+
+func (o *Operation) params(ctx context.Context) gql.ExecuteParams {
+	return gql.ExecuteParams{
+		Schema:        o.schema.schema,
+		AST:           o.document,
+		OperationName: o.request.OperationName,
+		Args:          o.request.Variables,
+		Context:       ctx,
+	}
+}
+
+func selectOperation(document *ast.Document, name string) (*ast.OperationDefinition, error) {
+	var selected *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if selected != nil {
+				return nil, errors.New("the operation name is required when the document has several operations")
+			}
+			selected = operation
+		} else if operation.Name != nil && operation.Name.Value == name {
+			selected = operation
+		}
+	}
+	if selected == nil {
+		if name != "" {
+			return nil, fmt.Errorf("unknown operation %q", name)
+		}
+		return nil, errors.New("the document has no operation")
+	}
+	return selected, nil
+}
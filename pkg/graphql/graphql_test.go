@@ -0,0 +1,291 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	"github.com/sergiorra/sushi-api-go/pkg/servicetest"
+)
+
+type fixture struct {
+	schema *Schema
+	bus    *events.Bus
+	locker locking.Locker
+}
+
+func newFixture(t *testing.T, sushis map[string]sushiapi.Sushi, limits Limits) *fixture {
+	t.Helper()
+
+	services := servicetest.New(sushis)
+	schema, err := NewSchema(services.Getting, services.Adding, services.Modifying, services.Removing, services.Bus, limits)
+	require.NoError(t, err)
+	return &fixture{schema: schema, bus: services.Bus, locker: services.Locker}
+}
+
+// do executes the request and returns the result as JSON, like the clients see it
+func (f *fixture) do(t *testing.T, req Request) string {
+	t.Helper()
+
+	operation, result := f.schema.Parse(req)
+	if result == nil {
+		result = operation.Execute(context.Background())
+	}
+	body, err := json.Marshal(result)
+	require.NoError(t, err)
+	return string(body)
+}
+
+var menu = map[string]sushiapi.Sushi{
+	"nigiri":  {ID: "nigiri", Name: "Salmon nigiri", Ingredients: []string{"Rice", "Salmon"}},
+	"uramaki": {ID: "uramaki", Name: "California uramaki", Ingredients: []string{"rice", "Crab", "Avocado"}},
+	"temaki":  {ID: "temaki", Name: "Tuna temaki", Ingredients: []string{"Rice", "Tuna", "Nori"}},
+}
+
+func Test_Query_Sushi(t *testing.T) {
+	f := newFixture(t, menu, Limits{})
+
+	assert.JSONEq(t,
+		`{"data":{"sushi":{"id":"nigiri","name":"Salmon nigiri","imageNumber":null,"ingredients":[{"name":"Rice"},{"name":"Salmon"}]}}}`,
+		f.do(t, Request{Query: `{ sushi(id: "nigiri") { id name imageNumber ingredients { name } } }`}))
+
+	assert.JSONEq(t, `{"data":{"sushi":null}}`, f.do(t, Request{Query: `{ sushi(id: "futomaki") { id } }`}))
+}
+
+func Test_Query_Sushis_Filter(t *testing.T) {
+	f := newFixture(t, menu, Limits{})
+
+	assert.JSONEq(t,
+		`{"data":{"sushis":{"totalCount":2,"nodes":[{"id":"nigiri"},{"id":"temaki"}]}}}`,
+		f.do(t, Request{Query: `{ sushis(filter: {ingredient: "rice", ids: ["nigiri", "temaki"]}) { totalCount nodes { id } } }`}))
+
+	assert.JSONEq(t,
+		`{"data":{"sushis":{"totalCount":1,"nodes":[{"id":"uramaki"}]}}}`,
+		f.do(t, Request{Query: `query($name: String) { sushis(filter: {name: $name}) { totalCount nodes { id } } }`, Variables: map[string]interface{}{"name": "CALIFORNIA"}}))
+}
+
+func Test_Query_Sushis_Prices(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{
+		"nigiri": {ID: "nigiri", Name: "Salmon nigiri", Pricing: sushiapi.Pricing{
+			Price:  &sushiapi.Money{Amount: 450, Currency: "EUR"},
+			Prices: map[string]sushiapi.Money{"tokyo": {Amount: 600, Currency: "JPY"}},
+		}},
+		"uramaki": {ID: "uramaki", Name: "California uramaki", Pricing: sushiapi.Pricing{Price: &sushiapi.Money{Amount: 900, Currency: "EUR"}}},
+		"temaki":  {ID: "temaki", Name: "Tuna temaki"},
+	}, Limits{})
+
+	assert.JSONEq(t,
+		`{"data":{"sushi":{"price":{"amount":450,"currency":"EUR","formatted":"4.50 EUR"},"prices":[{"restaurant":"tokyo","price":{"formatted":"600 JPY"}}],"tokyo":{"amount":600},"paris":{"amount":450}}}}`,
+		f.do(t, Request{Query: `{ sushi(id: "nigiri") { price { amount currency formatted } prices { restaurant price { formatted } } tokyo: priceAt(restaurant: "tokyo") { amount } paris: priceAt(restaurant: "paris") { amount } } }`}))
+	assert.JSONEq(t, `{"data":{"sushi":{"price":null,"prices":[]}}}`, f.do(t, Request{Query: `{ sushi(id: "temaki") { price { amount } prices { restaurant } } }`}))
+
+	assert.JSONEq(t,
+		`{"data":{"sushis":{"totalCount":1,"nodes":[{"id":"uramaki"}]}}}`,
+		f.do(t, Request{Query: `{ sushis(filter: {priceMin: 500, currency: "EUR"}) { totalCount nodes { id } } }`}))
+	assert.JSONEq(t,
+		`{"data":{"sushis":{"totalCount":1,"nodes":[{"id":"nigiri"}]}}}`,
+		f.do(t, Request{Query: `{ sushis(filter: {restaurant: "tokyo", currency: "JPY"}) { totalCount nodes { id } } }`}))
+
+	body := f.do(t, Request{Query: `{ sushis(filter: {priceMin: 500, priceMax: 100}) { totalCount } }`})
+	assert.Contains(t, body, `"code":"BAD_USER_INPUT"`)
+	assert.Contains(t, body, "priceMin can't exceed priceMax")
+}
+
+func Test_Query_Sushis_Labels(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{
+		"nigiri":  {ID: "nigiri", Name: "Salmon nigiri", Ingredients: []string{"Rice", "Salmon"}},
+		"uramaki": {ID: "uramaki", Name: "California uramaki", Ingredients: []string{"Rice", "Crab", "Avocado"}},
+		"inari":   {ID: "inari", Name: "Inari", Ingredients: []string{"Rice", "Inari"}},
+		"special": {ID: "special", Name: "Chef special", Ingredients: []string{"Rice", "Secret sauce"}},
+	}, Limits{})
+
+	assert.JSONEq(t,
+		`{"data":{"sushi":{"labels":{"allergens":["FISH"],"diets":["GLUTEN_FREE","RAW_FISH"],"unknownIngredients":[]}}}}`,
+		f.do(t, Request{Query: `{ sushi(id: "nigiri") { labels { allergens diets unknownIngredients } } }`}))
+	assert.JSONEq(t,
+		`{"data":{"sushi":{"labels":{"allergens":[],"diets":[],"unknownIngredients":["Secret sauce"]}}}}`,
+		f.do(t, Request{Query: `{ sushi(id: "special") { labels { allergens diets unknownIngredients } } }`}))
+
+	assert.JSONEq(t,
+		`{"data":{"sushis":{"nodes":[{"id":"inari"},{"id":"nigiri"}]}}}`,
+		f.do(t, Request{Query: `{ sushis(filter: {excludeAllergens: [CRUSTACEANS]}) { nodes { id } } }`}))
+	assert.JSONEq(t,
+		`{"data":{"sushis":{"nodes":[{"id":"inari"}]}}}`,
+		f.do(t, Request{Query: `{ sushis(filter: {diets: [VEGAN, GLUTEN_FREE]}) { nodes { id } } }`}))
+
+	body := f.do(t, Request{Query: `{ sushis(filter: {diets: [PESCATARIAN]}) { totalCount } }`})
+	assert.Contains(t, body, `"errors"`)
+}
+
+func Test_Query_Sushis_Pages(t *testing.T) {
+	f := newFixture(t, menu, Limits{})
+	query := `query($after: String) { sushis(first: 2, after: $after) { nodes { id } pageInfo { endCursor hasNextPage } } }`
+
+	var first struct {
+		Data struct {
+			Sushis struct {
+				Nodes    []struct{ ID string }
+				PageInfo struct {
+					EndCursor   string
+					HasNextPage bool
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(f.do(t, Request{Query: query})), &first))
+	assert.Len(t, first.Data.Sushis.Nodes, 2)
+	assert.True(t, first.Data.Sushis.PageInfo.HasNextPage)
+
+	assert.JSONEq(t,
+		fmt.Sprintf(`{"data":{"sushis":{"nodes":[{"id":"uramaki"}],"pageInfo":{"endCursor":%q,"hasNextPage":false}}}}`, cursor("uramaki")),
+		f.do(t, Request{Query: query, Variables: map[string]interface{}{"after": first.Data.Sushis.PageInfo.EndCursor}}))
+}
+
+func Test_Query_Ingredients(t *testing.T) {
+	f := newFixture(t, menu, Limits{})
+
+	assert.JSONEq(t,
+		`{"data":{"ingredients":[{"name":"Avocado"},{"name":"Crab"},{"name":"Nori"},{"name":"Rice"},{"name":"Salmon"},{"name":"Tuna"}]}}`,
+		f.do(t, Request{Query: `{ ingredients { name } }`}))
+
+	// the ingredients match ignoring case
+	assert.JSONEq(t,
+		`{"data":{"sushi":{"ingredients":[{"name":"Rice","sushis":[{"id":"nigiri"},{"id":"temaki"},{"id":"uramaki"}]},{"name":"Salmon","sushis":[{"id":"nigiri"}]}]}}}`,
+		f.do(t, Request{Query: `{ sushi(id: "nigiri") { ingredients { name sushis { id } } } }`}))
+}
+
+func Test_Mutations(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{}, Limits{})
+
+	assert.JSONEq(t,
+		`{"data":{"addSushi":{"id":"hosomaki","name":"Hosomaki"}}}`,
+		f.do(t, Request{Query: `mutation { addSushi(input: {id: "hosomaki", name: "Hosomaki", ingredients: ["Rice"]}) { id name } }`}))
+
+	assert.JSONEq(t,
+		`{"data":{"modifySushi":{"name":"Cucumber hosomaki"}}}`,
+		f.do(t, Request{Query: `mutation { modifySushi(id: "hosomaki", input: {name: "Cucumber hosomaki"}) { name } }`}))
+
+	assert.JSONEq(t,
+		`{"data":{"modifySushi":{"price":{"formatted":"3.00 EUR"},"prices":[{"restaurant":"tokyo","price":{"amount":400}}]}}}`,
+		f.do(t, Request{Query: `mutation { modifySushi(id: "hosomaki", input: {name: "Hosomaki", price: {amount: 300, currency: "EUR"}, prices: [{restaurant: "tokyo", price: {amount: 400, currency: "JPY"}}]}) { price { formatted } prices { restaurant price { amount } } } }`}))
+
+	assert.JSONEq(t,
+		`{"data":{"removeSushi":"hosomaki"}}`,
+		f.do(t, Request{Query: `mutation { removeSushi(id: "hosomaki") }`}))
+}
+
+func Test_Mutations_Errors(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{"nigiri": {ID: "nigiri", Name: "Nigiri"}}, Limits{})
+
+	body := f.do(t, Request{Query: `mutation { addSushi(input: {id: "hosomaki", name: "Hosomaki", price: {amount: 300, currency: "euro"}}) { id } }`})
+	assert.Contains(t, body, `"code":"BAD_USER_INPUT"`)
+	assert.Contains(t, body, `"data":null`)
+
+	lock, err := f.locker.Acquire(context.Background(), "nigiri", "chef", "", time.Minute)
+	require.NoError(t, err)
+	body = f.do(t, Request{Query: `mutation { modifySushi(id: "nigiri", input: {name: "Nigiri"}) { name } }`})
+	assert.Contains(t, body, `"code":"LOCKED"`)
+
+	assert.JSONEq(t,
+		`{"data":{"modifySushi":{"name":"Nigiri"}}}`,
+		f.do(t, Request{
+			Query:     `mutation($token: String) { modifySushi(id: "nigiri", input: {name: "Nigiri"}, lockToken: $token) { name } }`,
+			Variables: map[string]interface{}{"token": lock.Token},
+		}))
+}
+
+func Test_Parse_Invalid(t *testing.T) {
+	f := newFixture(t, menu, Limits{})
+
+	for _, query := range []string{
+		`{ sushi(id: "nigiri") { id `,
+		`{ sushi(id: "nigiri") { price } }`,
+		`query A { sushis { totalCount } } query B { ingredients { name } }`,
+	} {
+		operation, result := f.schema.Parse(Request{Query: query})
+		assert.Nil(t, operation, query)
+		require.NotNil(t, result, query)
+		assert.NotEmpty(t, result.Errors, query)
+	}
+
+	operation, result := f.schema.Parse(Request{Query: `query A { sushis { totalCount } } query B { ingredients { name } }`, OperationName: "B"})
+	require.Nil(t, result)
+	assert.Equal(t, KindQuery, operation.Kind())
+}
+
+func Test_Limits_Depth(t *testing.T) {
+	f := newFixture(t, menu, Limits{MaxDepth: 4})
+
+	_, result := f.schema.Parse(Request{Query: `{ sushi(id: "nigiri") { ingredients { sushis { id } } } }`})
+	assert.Nil(t, result)
+
+	_, result = f.schema.Parse(Request{Query: `{ sushi(id: "nigiri") { ingredients { sushis { ...deeper } } } } fragment deeper on Sushi { ingredients { name } }`})
+	require.NotNil(t, result)
+	assert.Equal(t, "the query depth 5 exceeds the limit of 4", result.Errors[0].Message)
+}
+
+func Test_Limits_Complexity(t *testing.T) {
+	f := newFixture(t, menu, Limits{MaxComplexity: 100})
+
+	// 1 for sushis, 1 for nodes plus 2 per node, 50 nodes
+	_, result := f.schema.Parse(Request{Query: `{ sushis(first: 50) { nodes { id name } } }`})
+	require.NotNil(t, result)
+	assert.Equal(t, "the query complexity 102 exceeds the limit of 100", result.Errors[0].Message)
+
+	_, result = f.schema.Parse(Request{Query: `query($n: Int) { sushis(first: $n) { nodes { id name } } }`, Variables: map[string]interface{}{"n": float64(49)}})
+	assert.Nil(t, result)
+
+	// the introspection is free
+	_, result = f.schema.Parse(Request{Query: `{ __schema { types { name fields { name type { name ofType { name } } } } } }`})
+	assert.Nil(t, result)
+}
+
+func Test_Subscription(t *testing.T) {
+	f := newFixture(t, menu, Limits{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	operation, result := f.schema.Parse(Request{Query: `subscription { sushiChanged(ids: ["nigiri"]) { type sushiId sushi { name } } }`})
+	require.Nil(t, result)
+	assert.Equal(t, KindSubscription, operation.Kind())
+	results := operation.Subscribe(ctx)
+
+	// the subscription starts asynchronously, keep publishing until it's seen
+	go func() {
+		for ctx.Err() == nil {
+			f.bus.Publish(ctx, events.New(events.SushiRemoved, "temaki", nil))
+			f.bus.Publish(ctx, events.New(events.SushiModified, "nigiri", &sushiapi.Sushi{ID: "nigiri", Name: "Nigiri"}))
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	select {
+	case result := <-results:
+		body, err := json.Marshal(result)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"data":{"sushiChanged":{"type":"MODIFIED","sushiId":"nigiri","sushi":{"name":"Nigiri"}}}}`, string(body))
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+	}
+
+	cancel()
+	for range results {
+	}
+}
+
+func Test_NoSubscriptionsWithoutBus(t *testing.T) {
+	services := servicetest.New(nil)
+	schema, err := NewSchema(services.Getting, services.Adding, services.Modifying, services.Removing, nil, Limits{})
+	require.NoError(t, err)
+
+	_, result := schema.Parse(Request{Query: `subscription { sushiChanged { id } }`})
+	require.NotNil(t, result)
+	assert.NotEmpty(t, result.Errors)
+}
@@ -0,0 +1,163 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultListSize is how many items are expected from the lists that can't be
+// paged, when measuring the complexity
+const defaultListSize = 10
+
+// checkLimits measures the operation, the introspection fields are free so the
+// tools can always read the schema
+func (s *Schema) checkLimits(document *ast.Document, operation *ast.OperationDefinition, variables map[string]interface{}) error {
+	var root *gql.Object
+	switch operation.Operation {
+	case KindMutation:
+		root = s.schema.MutationType()
+	case KindSubscription:
+		root = s.schema.SubscriptionType()
+	default:
+		root = s.schema.QueryType()
+	}
+
+	m := &measure{schema: &s.schema, fragments: make(map[string]*ast.FragmentDefinition), variables: variables}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	depth, complexity := m.selections(operation.SelectionSet, root, 0, 0)
+	if s.limits.MaxDepth > 0 && depth > s.limits.MaxDepth {
+		return fmt.Errorf("the query depth %d exceeds the limit of %d", depth, s.limits.MaxDepth)
+	}
+	if s.limits.MaxComplexity > 0 && complexity > s.limits.MaxComplexity {
+		return fmt.Errorf("the query complexity %d exceeds the limit of %d", complexity, s.limits.MaxComplexity)
+	}
+	return nil
+}
+
+type measure struct {
+	schema    *gql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// selections returns the depth and the complexity of the selection set of the
+// parent type. listSize is the page size asked to the parent field, the
+// number of items of its lists.
+func (m *measure) selections(set *ast.SelectionSet, parent *gql.Object, depth, listSize int) (int, int) {
+	if set == nil || parent == nil {
+		return depth, 0
+	}
+
+	maxDepth, complexity := depth, 0
+	add := func(d, c int) {
+		if d > maxDepth {
+			maxDepth = d
+		}
+		complexity += c
+	}
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			add(m.field(selection, parent, depth, listSize))
+		case *ast.InlineFragment:
+			add(m.selections(selection.SelectionSet, m.object(selection.TypeCondition, parent), depth, listSize))
+		case *ast.FragmentSpread:
+			// the validation rejected the unknown and cyclic fragments
+			if fragment, ok := m.fragments[selection.Name.Value]; ok {
+				add(m.selections(fragment.SelectionSet, m.object(fragment.TypeCondition, parent), depth, listSize))
+			}
+		}
+	}
+	return maxDepth, complexity
+}
+
+func (m *measure) field(field *ast.Field, parent *gql.Object, depth, listSize int) (int, int) {
+	if strings.HasPrefix(field.Name.Value, "__") {
+		return depth, 0
+	}
+	definition, ok := parent.Fields()[field.Name.Value]
+	if !ok {
+		return depth + 1, 1
+	}
+
+	list, named := unwrap(definition.Type)
+	object, _ := named.(*gql.Object)
+	childDepth, childComplexity := m.selections(field.SelectionSet, object, depth+1, m.first(field, definition))
+
+	if !list {
+		return childDepth, 1 + childComplexity
+	}
+	if listSize == 0 {
+		listSize = defaultListSize
+	}
+	return childDepth, 1 + listSize*childComplexity
+}
+
+// first returns the page size asked to the field, 0 when it isn't paged
+func (m *measure) first(field *ast.Field, definition *gql.FieldDefinition) int {
+	var value interface{}
+	for _, arg := range definition.Args {
+		if arg.Name() == "first" {
+			value = arg.DefaultValue
+		}
+	}
+	if value == nil {
+		return 0
+	}
+
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			value, _ = strconv.Atoi(v.Value)
+		case *ast.Variable:
+			if variable, ok := m.variables[v.Name.Value]; ok && variable != nil {
+				value = variable
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+func (m *measure) object(condition *ast.Named, parent *gql.Object) *gql.Object {
+	if condition == nil {
+		return parent
+	}
+	object, _ := m.schema.Type(condition.Name.Value).(*gql.Object)
+	return object
+}
+
+// unwrap returns the named type of a field and whether it's a list
+func unwrap(t gql.Type) (bool, gql.Type) {
+	list := false
+	for {
+		switch wrapped := t.(type) {
+		case *gql.NonNull:
+			t = wrapped.OfType
+		case *gql.List:
+			list = true
+			t = wrapped.OfType
+		default:
+			return list, t
+		}
+	}
+}
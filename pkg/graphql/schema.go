@@ -0,0 +1,701 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+
+	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/adding"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/getting"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	"github.com/sergiorra/sushi-api-go/pkg/modifying"
+	"github.com/sergiorra/sushi-api-go/pkg/removing"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Error is an error of a resolver, its code is given in the extensions
+type Error struct {
+	Message string
+	Code    string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions satisfies the gqlerrors.ExtendedError interface
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// toError maps the errors of the services to the error codes, the services
+// already logged them
+func toError(err error) error {
+	switch {
+	case errors.Is(err, sushiapi.ErrInvalidSushi):
+		return &Error{Message: err.Error(), Code: "BAD_USER_INPUT"}
+	case errors.Is(err, locking.ErrLocked):
+		return &Error{Message: err.Error(), Code: "LOCKED"}
+	default:
+		return &Error{Message: "internal error", Code: "INTERNAL"}
+	}
+}
+
+// ingredient is a source of the Ingredient type
+type ingredient string
+
+// restaurantPrice is a source of the RestaurantPrice type
+type restaurantPrice struct {
+	restaurant string
+	price      sushiapi.Money
+}
+
+// page is a source of the SushiConnection type
+type page struct {
+	sushis      []sushiapi.Sushi
+	total       int
+	hasNextPage bool
+}
+
+type resolver struct {
+	getting   getting.Service
+	adding    adding.Service
+	modifying modifying.Service
+	removing  removing.Service
+	bus       *events.Bus
+}
+
+func (r *resolver) schema() (gql.Schema, error) {
+	sushiType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Sushi",
+		Description: "A sushi of the menu",
+		Fields: gql.Fields{
+			"id":          &gql.Field{Type: gql.NewNonNull(gql.ID), Resolve: sushiField(func(s *sushiapi.Sushi) interface{} { return s.ID })},
+			"imageNumber": &gql.Field{Type: gql.String, Resolve: sushiField(func(s *sushiapi.Sushi) interface{} { return optional(s.ImageNumber) })},
+			"name":        &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: sushiField(func(s *sushiapi.Sushi) interface{} { return s.Name })},
+			"createdAt": &gql.Field{Type: gql.DateTime, Resolve: sushiField(func(s *sushiapi.Sushi) interface{} {
+				if s.CreatedAt == nil {
+					return nil
+				}
+				return *s.CreatedAt
+			})},
+			"updatedAt": &gql.Field{Type: gql.DateTime, Resolve: sushiField(func(s *sushiapi.Sushi) interface{} {
+				if s.UpdatedAt == nil {
+					return nil
+				}
+				return *s.UpdatedAt
+			})},
+		},
+	})
+
+	moneyType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Money",
+		Description: "An amount in the minor unit of its currency",
+		Fields: gql.Fields{
+			"amount":    &gql.Field{Type: gql.NewNonNull(gql.Int), Resolve: moneyField(func(m sushiapi.Money) interface{} { return m.Amount })},
+			"currency":  &gql.Field{Type: gql.NewNonNull(gql.String), Description: "The ISO 4217 code of the currency", Resolve: moneyField(func(m sushiapi.Money) interface{} { return m.Currency })},
+			"formatted": &gql.Field{Type: gql.NewNonNull(gql.String), Description: "The amount in the major unit, like 12.50 EUR", Resolve: moneyField(func(m sushiapi.Money) interface{} { return m.String() })},
+		},
+	})
+	restaurantPriceType := gql.NewObject(gql.ObjectConfig{
+		Name:        "RestaurantPrice",
+		Description: "The price a restaurant charges instead of the base price",
+		Fields: gql.Fields{
+			"restaurant": &gql.Field{Type: gql.NewNonNull(gql.ID), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(restaurantPrice).restaurant, nil
+			}},
+			"price": &gql.Field{Type: gql.NewNonNull(moneyType), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(restaurantPrice).price, nil
+			}},
+		},
+	})
+	sushiType.AddFieldConfig("price", &gql.Field{
+		Type:        moneyType,
+		Description: "The base price, null when the sushi isn't priced",
+		Resolve: sushiField(func(s *sushiapi.Sushi) interface{} {
+			if s.Price == nil {
+				return nil
+			}
+			return *s.Price
+		}),
+	})
+	sushiType.AddFieldConfig("prices", &gql.Field{
+		Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(restaurantPriceType))),
+		Description: "The prices of the restaurants overriding the base price, ordered by restaurant",
+		Resolve: sushiField(func(s *sushiapi.Sushi) interface{} {
+			prices := make([]restaurantPrice, 0, len(s.Prices))
+			for _, restaurant := range slices.Sorted(maps.Keys(s.Prices)) {
+				prices = append(prices, restaurantPrice{restaurant: restaurant, price: s.Prices[restaurant]})
+			}
+			return prices
+		}),
+	})
+	sushiType.AddFieldConfig("priceAt", &gql.Field{
+		Type:        moneyType,
+		Description: "The price in the restaurant, the base price when it has none of its own",
+		Args:        gql.FieldConfigArgument{"restaurant": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}},
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			restaurant := p.Args["restaurant"].(string)
+			return sushiField(func(s *sushiapi.Sushi) interface{} {
+				if price := s.PriceAt(restaurant); price != nil {
+					return *price
+				}
+				return nil
+			})(p)
+		},
+	})
+
+	allergenValues := gql.EnumValueConfigMap{}
+	for _, a := range sushiapi.Allergens {
+		allergenValues[enumName(string(a))] = &gql.EnumValueConfig{Value: a}
+	}
+	allergenType := gql.NewEnum(gql.EnumConfig{
+		Name:        "Allergen",
+		Description: "One of the 14 allergens the EU requires restaurants to declare",
+		Values:      allergenValues,
+	})
+	dietValues := gql.EnumValueConfigMap{}
+	for _, d := range sushiapi.Diets {
+		dietValues[enumName(string(d))] = &gql.EnumValueConfig{Value: d}
+	}
+	dietType := gql.NewEnum(gql.EnumConfig{
+		Name:        "Diet",
+		Description: "A dietary label, RAW_FISH warns rather than restricts",
+		Values:      dietValues,
+	})
+	labelsType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Labels",
+		Description: "The allergens and diets of a sushi, computed from its ingredients",
+		Fields: gql.Fields{
+			"allergens": &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(allergenType))), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*sushiapi.Labels).Allergens, nil
+			}},
+			"diets": &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(dietType))), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*sushiapi.Labels).Diets, nil
+			}},
+			"unknownIngredients": &gql.Field{
+				Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.String))),
+				Description: "The ingredients nothing is known about, the sushi may contain more allergens than listed",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					if unknown := p.Source.(*sushiapi.Labels).Unknown; unknown != nil {
+						return unknown, nil
+					}
+					return []string{}, nil
+				},
+			},
+		},
+	})
+	sushiType.AddFieldConfig("labels", &gql.Field{
+		Type: labelsType,
+		Resolve: sushiField(func(s *sushiapi.Sushi) interface{} {
+			if s.Labels == nil {
+				return nil
+			}
+			return s.Labels
+		}),
+	})
+
+	ingredientType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Ingredient",
+		Description: "An ingredient of the sushis",
+		Fields: gql.Fields{
+			"name": &gql.Field{Type: gql.NewNonNull(gql.String), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return string(p.Source.(ingredient)), nil
+			}},
+			"sushis": &gql.Field{
+				Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(sushiType))),
+				Description: "The sushis made with the ingredient, ordered by ID",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return r.list(p.Context, filter{ingredient: string(p.Source.(ingredient))})
+				},
+			},
+		},
+	})
+
+	sushiType.AddFieldConfig("ingredients", &gql.Field{
+		Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(ingredientType))),
+		Resolve: sushiField(func(s *sushiapi.Sushi) interface{} {
+			ingredients := make([]ingredient, 0, len(s.Ingredients))
+			for _, name := range s.Ingredients {
+				ingredients = append(ingredients, ingredient(name))
+			}
+			return ingredients
+		}),
+	})
+
+	pageInfoType := gql.NewObject(gql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: gql.Fields{
+			"endCursor": &gql.Field{Type: gql.String, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				pg := p.Source.(*page)
+				if len(pg.sushis) == 0 {
+					return nil, nil
+				}
+				return cursor(pg.sushis[len(pg.sushis)-1].ID), nil
+			}},
+			"hasNextPage": &gql.Field{Type: gql.NewNonNull(gql.Boolean), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*page).hasNextPage, nil
+			}},
+		},
+	})
+
+	connectionType := gql.NewObject(gql.ObjectConfig{
+		Name:        "SushiConnection",
+		Description: "A page of sushis",
+		Fields: gql.Fields{
+			"nodes": &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(sushiType))), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*page).sushis, nil
+			}},
+			"totalCount": &gql.Field{Type: gql.NewNonNull(gql.Int), Description: "The number of sushis matching the filter", Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*page).total, nil
+			}},
+			"pageInfo": &gql.Field{Type: gql.NewNonNull(pageInfoType), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source, nil
+			}},
+		},
+	})
+
+	filterType := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "SushiFilter",
+		Fields: gql.InputObjectConfigFieldMap{
+			"ids":              &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(gql.ID)), Description: "Only the sushis with these IDs"},
+			"name":             &gql.InputObjectFieldConfig{Type: gql.String, Description: "Only the sushis whose name contains this text, ignoring case"},
+			"ingredient":       &gql.InputObjectFieldConfig{Type: gql.String, Description: "Only the sushis made with this ingredient, ignoring case"},
+			"priceMin":         &gql.InputObjectFieldConfig{Type: gql.Int, Description: "Only the sushis costing at least this amount, in minor units of the currency"},
+			"priceMax":         &gql.InputObjectFieldConfig{Type: gql.Int, Description: "Only the sushis costing at most this amount, in minor units of the currency"},
+			"currency":         &gql.InputObjectFieldConfig{Type: gql.String, Description: "Only the sushis priced in this currency, required by priceMin and priceMax"},
+			"restaurant":       &gql.InputObjectFieldConfig{Type: gql.ID, Description: "Compare the prices of this restaurant rather than the base prices"},
+			"excludeAllergens": &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(allergenType)), Description: "Leave out the sushis containing any of these allergens or unknown ingredients, and those without ingredients"},
+			"diets":            &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(dietType)), Description: "Only the sushis labelled with all these diets"},
+		},
+	})
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"sushi": &gql.Field{
+				Type: sushiType,
+				Args: gql.FieldConfigArgument{"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					// a missing sushi is null rather than an error
+					if s := r.getting.GetSushiByID(p.Context, p.Args["id"].(string)); s != nil {
+						return s, nil
+					}
+					return nil, nil
+				},
+			},
+			"sushis": &gql.Field{
+				Type:        gql.NewNonNull(connectionType),
+				Description: "The sushis matching the filter, ordered by ID and paged",
+				Args: gql.FieldConfigArgument{
+					"filter": &gql.ArgumentConfig{Type: filterType},
+					"first":  &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultPageSize, Description: "The page size, at most 100"},
+					"after":  &gql.ArgumentConfig{Type: gql.String, Description: "The endCursor of the previous page"},
+				},
+				Resolve: r.sushis,
+			},
+			"ingredients": &gql.Field{
+				Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(ingredientType))),
+				Description: "Every ingredient of the menu, ordered by name",
+				Resolve:     r.ingredients,
+			},
+		},
+	})
+
+	moneyInput := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "MoneyInput",
+		Fields: gql.InputObjectConfigFieldMap{
+			"amount":   &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.Int), Description: "The amount in the minor unit of the currency"},
+			"currency": &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.String), Description: "The ISO 4217 code of the currency"},
+		},
+	})
+	restaurantPriceInput := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "RestaurantPriceInput",
+		Fields: gql.InputObjectConfigFieldMap{
+			"restaurant": &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.ID)},
+			"price":      &gql.InputObjectFieldConfig{Type: gql.NewNonNull(moneyInput)},
+		},
+	})
+	addInput := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "AddSushiInput",
+		Fields: gql.InputObjectConfigFieldMap{
+			"id":          &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.ID)},
+			"imageNumber": &gql.InputObjectFieldConfig{Type: gql.String},
+			"name":        &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.String)},
+			"ingredients": &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(gql.String))},
+			"price":       &gql.InputObjectFieldConfig{Type: moneyInput},
+			"prices":      &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(restaurantPriceInput))},
+		},
+	})
+	modifyInput := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "ModifySushiInput",
+		Fields: gql.InputObjectConfigFieldMap{
+			"imageNumber": &gql.InputObjectFieldConfig{Type: gql.String},
+			"name":        &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.String)},
+			"ingredients": &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(gql.String))},
+			"price":       &gql.InputObjectFieldConfig{Type: moneyInput},
+			"prices":      &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(restaurantPriceInput))},
+		},
+	})
+
+	mutation := gql.NewObject(gql.ObjectConfig{
+		Name: "Mutation",
+		Fields: gql.Fields{
+			"addSushi": &gql.Field{
+				Type:    gql.NewNonNull(sushiType),
+				Args:    gql.FieldConfigArgument{"input": &gql.ArgumentConfig{Type: gql.NewNonNull(addInput)}},
+				Resolve: r.addSushi,
+			},
+			"modifySushi": &gql.Field{
+				Type: gql.NewNonNull(sushiType),
+				Args: gql.FieldConfigArgument{
+					"id":        &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+					"input":     &gql.ArgumentConfig{Type: gql.NewNonNull(modifyInput)},
+					"lockToken": &gql.ArgumentConfig{Type: gql.String, Description: "The token of the edit lock held on the sushi, if any"},
+				},
+				Resolve: r.modifySushi,
+			},
+			"removeSushi": &gql.Field{
+				Type:        gql.NewNonNull(gql.ID),
+				Description: "Removes a sushi, returning its ID",
+				Args:        gql.FieldConfigArgument{"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}},
+				Resolve:     r.removeSushi,
+			},
+		},
+	})
+
+	config := gql.SchemaConfig{Query: query, Mutation: mutation}
+	if r.bus != nil {
+		config.Subscription = r.subscriptionType(sushiType)
+	}
+	return gql.NewSchema(config)
+}
+
+func (r *resolver) subscriptionType(sushiType *gql.Object) *gql.Object {
+	eventTypeEnum := gql.NewEnum(gql.EnumConfig{
+		Name: "SushiEventType",
+		Values: gql.EnumValueConfigMap{
+			"CREATED":  &gql.EnumValueConfig{Value: events.SushiCreated},
+			"MODIFIED": &gql.EnumValueConfig{Value: events.SushiModified},
+			"REMOVED":  &gql.EnumValueConfig{Value: events.SushiRemoved},
+		},
+	})
+
+	eventType := gql.NewObject(gql.ObjectConfig{
+		Name:        "SushiEvent",
+		Description: "A change of the menu",
+		Fields: gql.Fields{
+			"id":      &gql.Field{Type: gql.NewNonNull(gql.ID), Resolve: eventField(func(e events.Event) interface{} { return e.ID })},
+			"type":    &gql.Field{Type: gql.NewNonNull(eventTypeEnum), Resolve: eventField(func(e events.Event) interface{} { return e.Type })},
+			"time":    &gql.Field{Type: gql.NewNonNull(gql.DateTime), Resolve: eventField(func(e events.Event) interface{} { return e.Time })},
+			"sushiId": &gql.Field{Type: gql.NewNonNull(gql.ID), Resolve: eventField(func(e events.Event) interface{} { return e.SushiID })},
+			"sushi": &gql.Field{Type: sushiType, Description: "The new data of a created or modified sushi", Resolve: eventField(func(e events.Event) interface{} {
+				if e.Sushi == nil {
+					return nil
+				}
+				return e.Sushi
+			})},
+		},
+	})
+
+	return gql.NewObject(gql.ObjectConfig{
+		Name: "Subscription",
+		Fields: gql.Fields{
+			"sushiChanged": &gql.Field{
+				Type:        gql.NewNonNull(eventType),
+				Description: "The changes of the given sushis as they happen, of every sushi when no ids are given",
+				Args:        gql.FieldConfigArgument{"ids": &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(gql.ID))}},
+				Subscribe:   r.sushiChanged,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+}
+
+func (r *resolver) sushis(p gql.ResolveParams) (interface{}, error) {
+	var f filter
+	if args, ok := p.Args["filter"].(map[string]interface{}); ok {
+		f.ids = stringList(args["ids"])
+		f.name, _ = args["name"].(string)
+		f.ingredient, _ = args["ingredient"].(string)
+		f.service.Currency, _ = args["currency"].(string)
+		f.service.Restaurant, _ = args["restaurant"].(string)
+		if min, ok := args["priceMin"].(int); ok {
+			f.service.PriceMin = amount(min)
+		}
+		if max, ok := args["priceMax"].(int); ok {
+			f.service.PriceMax = amount(max)
+		}
+		if allergens, ok := args["excludeAllergens"].([]interface{}); ok {
+			for _, a := range allergens {
+				f.service.ExcludeAllergens = append(f.service.ExcludeAllergens, a.(sushiapi.Allergen))
+			}
+		}
+		if diets, ok := args["diets"].([]interface{}); ok {
+			for _, d := range diets {
+				f.service.Diets = append(f.service.Diets, d.(sushiapi.Diet))
+			}
+		}
+		if err := f.service.Validate(); err != nil {
+			return nil, &Error{Message: err.Error(), Code: "BAD_USER_INPUT"}
+		}
+	}
+
+	first, _ := p.Args["first"].(int)
+	switch {
+	case first < 0:
+		return nil, &Error{Message: "first can't be negative", Code: "BAD_USER_INPUT"}
+	case first > maxPageSize:
+		first = maxPageSize
+	}
+
+	var after string
+	if token, ok := p.Args["after"].(string); ok && token != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil || len(decoded) == 0 {
+			return nil, &Error{Message: "invalid cursor", Code: "BAD_USER_INPUT"}
+		}
+		after = string(decoded)
+	}
+
+	sushis, err := r.list(p.Context, f)
+	if err != nil {
+		return nil, err
+	}
+
+	pg := &page{total: len(sushis)}
+	start := sort.Search(len(sushis), func(i int) bool { return sushis[i].ID > after })
+	sushis = sushis[start:]
+	if len(sushis) > first {
+		sushis = sushis[:first]
+		pg.hasNextPage = true
+	}
+	pg.sushis = sushis
+	return pg, nil
+}
+
+func (r *resolver) ingredients(p gql.ResolveParams) (interface{}, error) {
+	sushis, err := r.list(p.Context, filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var ingredients []ingredient
+	for _, s := range sushis {
+		for _, name := range s.Ingredients {
+			if key := strings.ToLower(name); !seen[key] {
+				seen[key] = true
+				ingredients = append(ingredients, ingredient(name))
+			}
+		}
+	}
+	slices.SortFunc(ingredients, func(a, b ingredient) int {
+		return strings.Compare(strings.ToLower(string(a)), strings.ToLower(string(b)))
+	})
+	return ingredients, nil
+}
+
+func (r *resolver) addSushi(p gql.ResolveParams) (interface{}, error) {
+	input := p.Args["input"].(map[string]interface{})
+	ID := input["id"].(string)
+	imageNumber, _ := input["imageNumber"].(string)
+	name, _ := input["name"].(string)
+
+	if err := r.adding.AddSushi(p.Context, ID, imageNumber, name, stringList(input["ingredients"]), pricing(input)); err != nil {
+		return nil, toError(err)
+	}
+	return r.getting.GetSushiByID(p.Context, ID), nil
+}
+
+func (r *resolver) modifySushi(p gql.ResolveParams) (interface{}, error) {
+	ID := p.Args["id"].(string)
+	input := p.Args["input"].(map[string]interface{})
+	imageNumber, _ := input["imageNumber"].(string)
+	name, _ := input["name"].(string)
+	token, _ := p.Args["lockToken"].(string)
+
+	ctx := locking.WithToken(p.Context, token)
+	if err := r.modifying.ModifySushi(ctx, ID, imageNumber, name, stringList(input["ingredients"]), pricing(input)); err != nil {
+		return nil, toError(err)
+	}
+	return r.getting.GetSushiByID(p.Context, ID), nil
+}
+
+func (r *resolver) removeSushi(p gql.ResolveParams) (interface{}, error) {
+	ID := p.Args["id"].(string)
+	if err := r.removing.RemoveSushi(p.Context, ID); err != nil {
+		return nil, toError(err)
+	}
+	return ID, nil
+}
+
+// sushiChanged forwards the events of the bus until the subscription ends
+func (r *resolver) sushiChanged(p gql.ResolveParams) (interface{}, error) {
+	IDs := stringList(p.Args["ids"])
+	subscription, _, _ := r.bus.Subscribe("")
+
+	changes := make(chan interface{})
+	go func() {
+		defer close(changes)
+		defer r.bus.Unsubscribe(subscription)
+		for {
+			select {
+			case <-p.Context.Done():
+				return
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+				if len(IDs) > 0 && !slices.Contains(IDs, event.SushiID) {
+					continue
+				}
+				select {
+				case changes <- event:
+				case <-p.Context.Done():
+					return
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+type filter struct {
+	ids        []string
+	name       string
+	ingredient string
+	// service is applied by the getting service
+	service getting.Filter
+}
+
+func (f filter) matches(s sushiapi.Sushi) bool {
+	if len(f.ids) > 0 && !slices.Contains(f.ids, s.ID) {
+		return false
+	}
+	if f.name != "" && !strings.Contains(strings.ToLower(s.Name), strings.ToLower(f.name)) {
+		return false
+	}
+	if f.ingredient != "" && !slices.ContainsFunc(s.Ingredients, func(i string) bool { return strings.EqualFold(i, f.ingredient) }) {
+		return false
+	}
+	return true
+}
+
+// list returns the sushis matching the filter, ordered by ID
+func (r *resolver) list(ctx context.Context, f filter) ([]sushiapi.Sushi, error) {
+	var sushis []sushiapi.Sushi
+	for s, err := range r.getting.StreamSushis(ctx, f.service) {
+		if err != nil {
+			return nil, &Error{Message: "the sushis can't be listed", Code: "UNAVAILABLE"}
+		}
+		if f.matches(s) {
+			sushis = append(sushis, s)
+		}
+	}
+	slices.SortFunc(sushis, func(a, b sushiapi.Sushi) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return sushis, nil
+}
+
+// enumName returns the name of the enum value of a label, like GLUTEN_FREE
+func enumName(label string) string {
+	return strings.ToUpper(strings.ReplaceAll(label, "-", "_"))
+}
+
+func cursor(ID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(ID))
+}
+
+// sushiField resolves a field of the sushis, given by value or by pointer
+func sushiField(get func(s *sushiapi.Sushi) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		switch s := p.Source.(type) {
+		case *sushiapi.Sushi:
+			return get(s), nil
+		case sushiapi.Sushi:
+			return get(&s), nil
+		}
+		return nil, nil
+	}
+}
+
+func moneyField(get func(m sushiapi.Money) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(sushiapi.Money)), nil
+	}
+}
+
+func eventField(get func(e events.Event) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(events.Event)), nil
+	}
+}
+
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// stringList converts a list argument, decoded as []interface{}
+func stringList(v interface{}) []string {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+func amount(value int) *int64 {
+	a := int64(value)
+	return &a
+}
+
+// pricing converts the price and prices of a sushi input
+func pricing(input map[string]interface{}) sushiapi.Pricing {
+	var p sushiapi.Pricing
+	if price, ok := input["price"].(map[string]interface{}); ok {
+		m := money(price)
+		p.Price = &m
+	}
+	if prices, ok := input["prices"].([]interface{}); ok && len(prices) > 0 {
+		p.Prices = make(map[string]sushiapi.Money, len(prices))
+		for _, item := range prices {
+			if rp, ok := item.(map[string]interface{}); ok {
+				restaurant, _ := rp["restaurant"].(string)
+				price, _ := rp["price"].(map[string]interface{})
+				p.Prices[restaurant] = money(price)
+			}
+		}
+	}
+	return p
+}
+
+func money(input map[string]interface{}) sushiapi.Money {
+	amount, _ := input["amount"].(int)
+	currency, _ := input["currency"].(string)
+	return sushiapi.Money{Amount: int64(amount), Currency: currency}
+}
@@ -0,0 +1,48 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+)
+
+// Backoff defines how often a dependency is checked until it's reachable
+type Backoff struct {
+	Initial  time.Duration
+	Max      time.Duration
+	Attempts int
+}
+
+// DefaultBackoff checks during about one minute, doubling the wait each time
+func DefaultBackoff() Backoff {
+	return Backoff{Initial: 500 * time.Millisecond, Max: 10 * time.Second, Attempts: 10}
+}
+
+// Wait pings the dependency until it answers, waiting longer after every
+// failure. It returns the last error once the attempts are exhausted.
+func Wait(ctx context.Context, pinger sushi.Pinger, backoff Backoff, logger log.Logger) error {
+	delay := backoff.Initial
+	for attempt := 1; ; attempt++ {
+		err := pinger.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		logger.RepositoryUnavailable(ctx, err)
+		if attempt >= backoff.Attempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > backoff.Max {
+			delay = backoff.Max
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+)
+
+type flakyPinger struct {
+	failures int
+	pings    int
+}
+
+func (p *flakyPinger) Ping(ctx context.Context) error {
+	p.pings++
+	if p.pings <= p.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func Test_Wait_Recovers(t *testing.T) {
+	pinger := &flakyPinger{failures: 2}
+	backoff := Backoff{Initial: time.Millisecond, Max: 2 * time.Millisecond, Attempts: 5}
+
+	err := Wait(context.Background(), pinger, backoff, log.NewNoopLogger())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pinger.pings)
+}
+
+func Test_Wait_GivesUp(t *testing.T) {
+	pinger := &flakyPinger{failures: 10}
+	backoff := Backoff{Initial: time.Millisecond, Max: 2 * time.Millisecond, Attempts: 3}
+
+	err := Wait(context.Background(), pinger, backoff, log.NewNoopLogger())
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, pinger.pings)
+}
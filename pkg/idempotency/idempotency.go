@@ -0,0 +1,28 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Record is what a store keeps per idempotency key: the fingerprint of the
+// first request and, once it has been handled, its response
+type Record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Pending     bool        `json:"pending,omitempty"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Store keeps the records of the idempotency keys for a while
+type Store interface {
+	// Reserve claims the key with a pending record for the given fingerprint.
+	// When the key is already taken it returns its record and false.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (Record, bool, error)
+	// Complete stores the response of the request that reserved the key
+	Complete(ctx context.Context, key string, record Record, ttl time.Duration) error
+	// Release frees the key so the request can be retried
+	Release(ctx context.Context, key string) error
+}
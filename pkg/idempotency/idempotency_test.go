@@ -0,0 +1,75 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	record, ok, err := store.Reserve(ctx, "key", "fingerprint", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, record.Pending)
+
+	record, ok, err = store.Reserve(ctx, "key", "other", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok, "the key is taken")
+	assert.Equal(t, Record{Fingerprint: "fingerprint", Pending: true}, record)
+
+	response := Record{
+		Fingerprint: "fingerprint",
+		Status:      http.StatusCreated,
+		Header:      http.Header{"Content-Type": {"application/json"}},
+		Body:        []byte(`"created"`),
+	}
+	require.NoError(t, store.Complete(ctx, "key", response, time.Minute))
+	record, ok, err = store.Reserve(ctx, "key", "fingerprint", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, response, record)
+
+	require.NoError(t, store.Release(ctx, "key"))
+	_, ok, err = store.Reserve(ctx, "key", "other", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok, "a released key can be reserved again")
+}
+
+func Test_MemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func Test_MemoryStore_Expiry(t *testing.T) {
+	now := time.Now()
+	store := &memoryStore{records: make(map[string]memoryRecord), now: func() time.Time { return now }}
+
+	_, ok, _ := store.Reserve(context.Background(), "key", "fingerprint", time.Minute)
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok, _ = store.Reserve(context.Background(), "key", "other", time.Minute)
+	assert.True(t, ok, "the expired record is replaced")
+}
+
+func Test_RedisStore(t *testing.T) {
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", s.Addr()) }}
+	testStore(t, NewRedisStore(pool, "idempotency:"))
+
+	assert.True(t, s.Exists("idempotency:key"))
+	s.FastForward(time.Minute)
+	assert.False(t, s.Exists("idempotency:key"), "the records expire")
+}
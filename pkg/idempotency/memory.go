@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is the amount of reservations between two sweeps of expired records
+const sweepEvery = 1024
+
+type memoryRecord struct {
+	Record
+	expires time.Time
+}
+
+type memoryStore struct {
+	mtx      sync.Mutex
+	records  map[string]memoryRecord
+	reserves int
+	now      func() time.Time
+}
+
+// NewMemoryStore creates a Store that keeps the records in process memory
+func NewMemoryStore() Store {
+	return &memoryStore{
+		records: make(map[string]memoryRecord),
+		now:     time.Now,
+	}
+}
+
+// Reserve satisfies the Store interface
+func (s *memoryStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (Record, bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if record, ok := s.records[key]; ok && now.Before(record.expires) {
+		return record.Record, false, nil
+	}
+
+	record := Record{Fingerprint: fingerprint, Pending: true}
+	s.records[key] = memoryRecord{Record: record, expires: now.Add(ttl)}
+	return record, true, nil
+}
+
+// Complete satisfies the Store interface
+func (s *memoryStore) Complete(ctx context.Context, key string, record Record, ttl time.Duration) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	record.Pending = false
+	s.records[key] = memoryRecord{Record: record, expires: s.now().Add(ttl)}
+	return nil
+}
+
+// Release satisfies the Store interface
+func (s *memoryStore) Release(ctx context.Context, key string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep drops the expired records
+func (s *memoryStore) sweep(now time.Time) {
+	s.reserves++
+	if s.reserves%sweepEvery != 0 {
+		return
+	}
+
+	for key, record := range s.records {
+		if !now.Before(record.expires) {
+			delete(s.records, key)
+		}
+	}
+}
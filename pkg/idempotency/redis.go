@@ -0,0 +1,89 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+type redisStore struct {
+	pool   *redis.Pool
+	prefix string
+}
+
+// NewRedisStore creates a Store that keeps the records in Redis so retries
+// reaching another replica are recognised
+func NewRedisStore(pool *redis.Pool, prefix string) Store {
+	return &redisStore{pool: pool, prefix: prefix}
+}
+
+// Reserve satisfies the Store interface
+func (s *redisStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (Record, bool, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return Record{}, false, err
+	}
+	defer conn.Close()
+
+	record := Record{Fingerprint: fingerprint, Pending: true}
+	value, err := json.Marshal(record)
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	// the reservation and the lookup can't be a single command, a key expiring
+	// in between is reserved again
+	for {
+		_, err := redis.String(conn.Do("SET", s.prefix+key, value, "NX", "PX", ttl.Milliseconds()))
+		if err == nil {
+			return record, true, nil
+		}
+		if err != redis.ErrNil {
+			return Record{}, false, err
+		}
+
+		existing, err := redis.Bytes(conn.Do("GET", s.prefix+key))
+		if err == redis.ErrNil {
+			continue
+		}
+		if err != nil {
+			return Record{}, false, err
+		}
+		var stored Record
+		if err := json.Unmarshal(existing, &stored); err != nil {
+			return Record{}, false, err
+		}
+		return stored, false, nil
+	}
+}
+
+// Complete satisfies the Store interface
+func (s *redisStore) Complete(ctx context.Context, key string, record Record, ttl time.Duration) error {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	record.Pending = false
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Do("SET", s.prefix+key, value, "PX", ttl.Milliseconds())
+	return err
+}
+
+// Release satisfies the Store interface
+func (s *redisStore) Release(ctx context.Context, key string) error {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("DEL", s.prefix+key)
+	return err
+}
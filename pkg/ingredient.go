@@ -0,0 +1,98 @@
+package sushi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Ingredient is an entry of the ingredient catalogue, the sushis reference
+// it by ID so its name is only stored once
+type Ingredient struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	IngredientInfo
+	CreatedAt *time.Time `json:"-"`
+	UpdatedAt *time.Time `json:"-"`
+}
+
+// NewIngredient creates an ingredient
+func NewIngredient(ID, Name string, Info IngredientInfo) *Ingredient {
+	return &Ingredient{
+		ID:             ID,
+		Name:           Name,
+		IngredientInfo: Info,
+	}
+}
+
+var (
+	// ErrInvalidIngredient is returned when an ingredient can't be catalogued
+	ErrInvalidIngredient = errors.New("invalid ingredient")
+	// ErrIngredientNotFound is returned for the IDs missing from the catalogue
+	ErrIngredientNotFound = errors.New("ingredient not found")
+	// ErrIngredientInUse is returned when removing an ingredient some sushis
+	// are still made with
+	ErrIngredientInUse = errors.New("ingredient in use")
+)
+
+// Validate checks the ingredient can be catalogued
+func (i *Ingredient) Validate() error {
+	if !idPattern.MatchString(i.ID) {
+		return fmt.Errorf("%w: id %q must be alphanumeric", ErrInvalidIngredient, i.ID)
+	}
+	if strings.TrimSpace(i.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidIngredient)
+	}
+	for _, a := range i.Allergens {
+		if _, err := ParseAllergen(string(a)); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidIngredient, err)
+		}
+	}
+	if i.Vegan && !i.Vegetarian {
+		return fmt.Errorf("%w: a vegan ingredient is vegetarian", ErrInvalidIngredient)
+	}
+	return nil
+}
+
+// IngredientRepository provides access to the ingredient catalogue.
+// GetIngredientByID returns ErrIngredientNotFound for unknown IDs.
+type IngredientRepository interface {
+	CreateIngredient(ctx context.Context, i *Ingredient) error
+	GetIngredients(ctx context.Context) ([]Ingredient, error)
+	GetIngredientByID(ctx context.Context, ID string) (*Ingredient, error)
+	UpdateIngredient(ctx context.Context, ID string, i *Ingredient) error
+	DeleteIngredient(ctx context.Context, ID string) error
+}
+
+// UnreferencedDeleter is implemented by the ingredient repositories sharing
+// the database of the sushis. DeleteUnreferencedIngredient checks no sushi is
+// made of the ingredient and deletes it in one transaction, returning an
+// InUse error otherwise.
+type UnreferencedDeleter interface {
+	DeleteUnreferencedIngredient(ctx context.Context, ID string) error
+}
+
+// InUse returns the ErrIngredientInUse error naming the sushis made of the
+// ingredient, sorted by ID
+func InUse(ID string, sushiIDs []string) error {
+	sushiIDs = slices.Sorted(slices.Values(sushiIDs))
+	return fmt.Errorf("%w: %s is an ingredient of %s", ErrIngredientInUse, ID, strings.Join(sushiIDs, ", "))
+}
+
+// CheckIngredients returns an ErrInvalidSushi error naming the first of the
+// ingredient IDs missing from the catalogue
+func CheckIngredients(ctx context.Context, repository IngredientRepository, IDs []string) error {
+	for _, ID := range IDs {
+		_, err := repository.GetIngredientByID(ctx, ID)
+		if errors.Is(err, ErrIngredientNotFound) {
+			return fmt.Errorf("%w: ingredient %q isn't in the catalogue", ErrInvalidSushi, ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
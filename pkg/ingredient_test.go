@@ -0,0 +1,32 @@
+package sushi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Ingredient_Validate(t *testing.T) {
+	testData := []struct {
+		name       string
+		ingredient *Ingredient
+		valid      bool
+	}{
+		{name: "valid", ingredient: NewIngredient("sesame_seeds", "Sesame seeds", IngredientInfo{Allergens: []Allergen{Sesame}, Vegetarian: true, Vegan: true}), valid: true},
+		{name: "invalid id", ingredient: NewIngredient("sesame seeds", "Sesame seeds", IngredientInfo{})},
+		{name: "blank name", ingredient: NewIngredient("sesame", " ", IngredientInfo{})},
+		{name: "unknown allergen", ingredient: NewIngredient("sesame", "Sesame", IngredientInfo{Allergens: []Allergen{"seeds"}})},
+		{name: "vegan but not vegetarian", ingredient: NewIngredient("sesame", "Sesame", IngredientInfo{Vegan: true})},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ingredient.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidIngredient)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package ingredients
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/tracing"
+)
+
+// Catalogue is the ingredient repository shared by the services writing the
+// sushis and the Service, it keeps the sushis from referencing an ingredient
+// while it's removed. The writes are serialized in process memory, not
+// between replicas, see the README.
+type Catalogue struct {
+	sushi.IngredientRepository
+	mtx sync.RWMutex
+}
+
+// NewCatalogue wraps the repository of the ingredients
+func NewCatalogue(repository sushi.IngredientRepository) *Catalogue {
+	return &Catalogue{IngredientRepository: repository}
+}
+
+// Version satisfies the sushi.Versioner interface, returning
+// sushi.ErrUnversioned when the repository isn't versioned
+func (c *Catalogue) Version(ctx context.Context) (sushi.Version, error) {
+	if versioner, ok := c.IngredientRepository.(sushi.Versioner); ok {
+		return versioner.Version(ctx)
+	}
+	return sushi.Version{}, sushi.ErrUnversioned
+}
+
+// Reference checks the ingredient IDs are catalogued, then runs write storing
+// the sushi made of them before any of them can be removed. The failed checks
+// are logged, write logs its own errors. A nil catalogue only runs write, the
+// ingredients being free-form.
+func (c *Catalogue) Reference(ctx context.Context, logger log.Logger, IDs []string, write func() error) error {
+	if c == nil {
+		return write()
+	}
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	err := sushi.CheckIngredients(ctx, c.IngredientRepository, IDs)
+	switch {
+	case errors.Is(err, sushi.ErrInvalidSushi):
+		logger.ValidationFailed(ctx, err)
+		return err
+	case err != nil:
+		tracing.Fail(ctx, err)
+		logger.UnexpectedError(ctx, err)
+		return err
+	}
+	return write()
+}
@@ -0,0 +1,53 @@
+package ingredients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/storage/inmem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Catalogue_RemoveWaitsForReference(t *testing.T) {
+	sushis := inmem.NewRepository(map[string]sushi.Sushi{})
+	catalogue := NewCatalogue(inmem.NewIngredientRepository([]sushi.Ingredient{
+		*sushi.NewIngredient("salmon", "Salmon", sushi.IngredientInfo{Allergens: []sushi.Allergen{sushi.Fish}}),
+	}))
+	logger := log.NewNoopLogger()
+	service := NewService(catalogue, sushis, logger, events.NewNoopPublisher())
+	ctx := context.Background()
+
+	checked, write := make(chan struct{}), make(chan struct{})
+	referenced := make(chan error)
+	go func() {
+		referenced <- catalogue.Reference(ctx, logger, []string{"salmon"}, func() error {
+			close(checked)
+			<-write
+			return sushis.CreateSushi(ctx, sushi.New("nigiri", "1", "Nigiri", []string{"salmon"}))
+		})
+	}()
+	<-checked
+
+	removed := make(chan error)
+	go func() { removed <- service.RemoveIngredient(ctx, "salmon") }()
+	select {
+	case err := <-removed:
+		t.Fatalf("the ingredient was removed while referenced: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(write)
+	require.NoError(t, <-referenced)
+	assert.ErrorIs(t, <-removed, sushi.ErrIngredientInUse)
+
+	err := catalogue.Reference(ctx, logger, []string{"tuna"}, func() error {
+		t.Fatal("the sushi was written with an ingredient missing from the catalogue")
+		return nil
+	})
+	assert.ErrorIs(t, err, sushi.ErrInvalidSushi)
+}
@@ -0,0 +1,217 @@
+// Package ingredients manages the catalogue of the ingredients the sushis are
+// made of. The sushis reference the ingredients by ID, so a rename shows in
+// all of them and an ingredient still in use can't be removed.
+package ingredients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/tracing"
+	"go.opentelemetry.io/otel"
+)
+
+// Service provides the operations of the ingredient catalogue
+type Service interface {
+	AddIngredient(ctx context.Context, ID, Name string, Info sushi.IngredientInfo) error
+	GetIngredients(ctx context.Context) ([]sushi.Ingredient, error)
+	GetIngredientByID(ctx context.Context, ID string) (*sushi.Ingredient, error)
+	ModifyIngredient(ctx context.Context, ID, Name string, Info sushi.IngredientInfo) error
+	RemoveIngredient(ctx context.Context, ID string) error
+}
+
+var tracer = otel.Tracer("github.com/sergiorra/sushi-api-go/pkg/ingredients")
+
+type service struct {
+	repository *Catalogue
+	sushis     sushi.Repository
+	logger     log.Logger
+	publisher  events.Publisher
+}
+
+// NewService creates an ingredients service with the necessary dependencies,
+// sushis being the repository of the sushis made of the ingredients
+func NewService(repository *Catalogue, sushis sushi.Repository, logger log.Logger, publisher events.Publisher) Service {
+	return &service{repository, sushis, logger, publisher}
+}
+
+// AddIngredient adds the given ingredient to the catalogue, its ID and its
+// name ignoring case must be free
+func (s *service) AddIngredient(ctx context.Context, ID, Name string, Info sushi.IngredientInfo) error {
+	ctx, span := tracer.Start(ctx, "ingredients.AddIngredient")
+	defer span.End()
+
+	ingredient := sushi.NewIngredient(ID, Name, Info)
+	now := time.Now()
+	ingredient.CreatedAt = &now
+	if err := ingredient.Validate(); err != nil {
+		s.logger.ValidationFailed(ctx, err)
+		return err
+	}
+	if err := s.checkFree(ctx, ingredient, true); err != nil {
+		return err
+	}
+
+	if err := s.repository.CreateIngredient(ctx, ingredient); err != nil {
+		tracing.Fail(ctx, err)
+		s.logger.UnexpectedError(ctx, err)
+		return err
+	}
+	return nil
+}
+
+// GetIngredients returns the catalogue, ordered by ID
+func (s *service) GetIngredients(ctx context.Context) ([]sushi.Ingredient, error) {
+	ctx, span := tracer.Start(ctx, "ingredients.GetIngredients")
+	defer span.End()
+
+	ingredients, err := s.repository.GetIngredients(ctx)
+	if err != nil {
+		tracing.Fail(ctx, err)
+		s.logger.RepositoryUnavailable(ctx, err)
+		return nil, err
+	}
+	slices.SortFunc(ingredients, func(a, b sushi.Ingredient) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return ingredients, nil
+}
+
+// GetIngredientByID returns an ingredient, sushi.ErrIngredientNotFound when
+// it isn't in the catalogue
+func (s *service) GetIngredientByID(ctx context.Context, ID string) (*sushi.Ingredient, error) {
+	ctx, span := tracer.Start(ctx, "ingredients.GetIngredientByID")
+	defer span.End()
+
+	ingredient, err := s.repository.GetIngredientByID(ctx, ID)
+	if err != nil {
+		tracing.Fail(ctx, err)
+		return nil, err
+	}
+	return ingredient, nil
+}
+
+// ModifyIngredient modifies the data of an ingredient. The sushis made of it
+// are published as modified, their labels changed with it.
+func (s *service) ModifyIngredient(ctx context.Context, ID, Name string, Info sushi.IngredientInfo) error {
+	ctx, span := tracer.Start(ctx, "ingredients.ModifyIngredient")
+	defer span.End()
+
+	current, err := s.repository.GetIngredientByID(ctx, ID)
+	if err != nil {
+		tracing.Fail(ctx, err)
+		return err
+	}
+	ingredient := sushi.NewIngredient(ID, Name, Info)
+	now := time.Now()
+	ingredient.CreatedAt, ingredient.UpdatedAt = current.CreatedAt, &now
+	if err := ingredient.Validate(); err != nil {
+		s.logger.ValidationFailed(ctx, err)
+		return err
+	}
+	if err := s.checkFree(ctx, ingredient, false); err != nil {
+		return err
+	}
+
+	if err := s.repository.UpdateIngredient(ctx, ID, ingredient); err != nil {
+		tracing.Fail(ctx, err)
+		s.logger.UnexpectedError(ctx, err)
+		return err
+	}
+
+	sushis, err := s.madeWith(ctx, ID)
+	if err != nil {
+		// the ingredient is modified, only the notifications are lost
+		s.logger.UnexpectedError(ctx, err)
+		return nil
+	}
+	for _, g := range sushis {
+		s.publisher.Publish(ctx, events.New(events.SushiModified, g.ID, &g))
+	}
+	return nil
+}
+
+// RemoveIngredient removes an ingredient from the catalogue, refusing with
+// sushi.ErrIngredientInUse while some sushis are made of it. The repositories
+// sharing the database of the sushis check it in the transaction deleting it.
+func (s *service) RemoveIngredient(ctx context.Context, ID string) error {
+	ctx, span := tracer.Start(ctx, "ingredients.RemoveIngredient")
+	defer span.End()
+
+	// no sushi references the ingredient between the check and the delete
+	s.repository.mtx.Lock()
+	defer s.repository.mtx.Unlock()
+
+	var err error
+	if deleter, ok := s.repository.IngredientRepository.(sushi.UnreferencedDeleter); ok {
+		err = deleter.DeleteUnreferencedIngredient(ctx, ID)
+	} else {
+		err = s.deleteUnreferenced(ctx, ID)
+	}
+	if err != nil && !errors.Is(err, sushi.ErrIngredientInUse) {
+		tracing.Fail(ctx, err)
+		s.logger.UnexpectedError(ctx, err)
+	}
+	return err
+}
+
+// deleteUnreferenced deletes the ingredient unless some sushis are made of it
+func (s *service) deleteUnreferenced(ctx context.Context, ID string) error {
+	sushis, err := s.madeWith(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if len(sushis) > 0 {
+		IDs := make([]string, 0, len(sushis))
+		for _, g := range sushis {
+			IDs = append(IDs, g.ID)
+		}
+		return sushi.InUse(ID, IDs)
+	}
+	return s.repository.DeleteIngredient(ctx, ID)
+}
+
+// checkFree refuses the ingredient when another one has its name ignoring
+// case, or its ID when it's created
+func (s *service) checkFree(ctx context.Context, ingredient *sushi.Ingredient, created bool) error {
+	ingredients, err := s.repository.GetIngredients(ctx)
+	if err != nil {
+		tracing.Fail(ctx, err)
+		s.logger.RepositoryUnavailable(ctx, err)
+		return err
+	}
+	for _, other := range ingredients {
+		switch {
+		case other.ID == ingredient.ID && created:
+			err = fmt.Errorf("%w: id %q is taken", sushi.ErrInvalidIngredient, ingredient.ID)
+		case other.ID != ingredient.ID && strings.EqualFold(strings.TrimSpace(other.Name), strings.TrimSpace(ingredient.Name)):
+			err = fmt.Errorf("%w: name %q is taken by %s", sushi.ErrInvalidIngredient, ingredient.Name, other.ID)
+		}
+		if err != nil {
+			s.logger.ValidationFailed(ctx, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// madeWith returns the sushis made of the ingredient
+func (s *service) madeWith(ctx context.Context, ID string) ([]sushi.Sushi, error) {
+	var sushis []sushi.Sushi
+	for g, err := range sushi.Stream(ctx, s.sushis) {
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(g.Ingredients, ID) {
+			sushis = append(sushis, g)
+		}
+	}
+	return sushis, nil
+}
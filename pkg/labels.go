@@ -0,0 +1,127 @@
+package sushi
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Allergen is one of the 14 allergens the EU requires restaurants to declare
+type Allergen string
+
+// The allergens of Annex II of the EU regulation 1169/2011
+const (
+	Gluten      Allergen = "gluten"
+	Crustaceans Allergen = "crustaceans"
+	Eggs        Allergen = "eggs"
+	Fish        Allergen = "fish"
+	Peanuts     Allergen = "peanuts"
+	Soybeans    Allergen = "soybeans"
+	Milk        Allergen = "milk"
+	Nuts        Allergen = "nuts"
+	Celery      Allergen = "celery"
+	Mustard     Allergen = "mustard"
+	Sesame      Allergen = "sesame"
+	Sulphites   Allergen = "sulphites"
+	Lupin       Allergen = "lupin"
+	Molluscs    Allergen = "molluscs"
+)
+
+// Allergens lists the allergens in the order of the regulation, the labels
+// follow it
+var Allergens = []Allergen{
+	Gluten, Crustaceans, Eggs, Fish, Peanuts, Soybeans, Milk,
+	Nuts, Celery, Mustard, Sesame, Sulphites, Lupin, Molluscs,
+}
+
+// ParseAllergen returns the allergen of the name
+func ParseAllergen(name string) (Allergen, error) {
+	if a := Allergen(name); slices.Contains(Allergens, a) {
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown allergen %q", name)
+}
+
+// Diet is a dietary label of a sushi
+type Diet string
+
+// The dietary labels, raw fish warns rather than restricts
+const (
+	Vegetarian Diet = "vegetarian"
+	Vegan      Diet = "vegan"
+	GlutenFree Diet = "gluten-free"
+	RawFish    Diet = "raw-fish"
+)
+
+// Diets lists the dietary labels in the order of the labels
+var Diets = []Diet{Vegetarian, Vegan, GlutenFree, RawFish}
+
+// ParseDiet returns the dietary label of the name
+func ParseDiet(name string) (Diet, error) {
+	if d := Diet(name); slices.Contains(Diets, d) {
+		return d, nil
+	}
+	return "", fmt.Errorf("unknown diet %q", name)
+}
+
+// IngredientInfo tells what an ingredient contains
+type IngredientInfo struct {
+	Allergens  []Allergen `json:"allergens,omitempty"`
+	Vegetarian bool       `json:"vegetarian"`
+	Vegan      bool       `json:"vegan"`
+	RawFish    bool       `json:"rawFish"`
+}
+
+// Labels are computed from the ingredients of a sushi, they aren't stored
+type Labels struct {
+	// Allergens lists the allergens of the known ingredients
+	Allergens []Allergen `json:"allergens"`
+	// Diets lists the dietary labels the sushi satisfies
+	Diets []Diet `json:"diets"`
+	// Unknown lists the ingredients nothing is known about, the sushi may
+	// contain more allergens than listed
+	Unknown []string `json:"unknownIngredients,omitempty"`
+}
+
+// ComputeLabels derives the labels of a sushi made of the ingredients, lookup
+// tells what an ingredient contains. A sushi is only labelled vegetarian,
+// vegan or gluten-free when all its ingredients are known, and it has at
+// least one.
+func ComputeLabels(ingredients []string, lookup func(name string) (IngredientInfo, bool)) Labels {
+	labels := Labels{Allergens: []Allergen{}, Diets: []Diet{}}
+	found := make(map[Allergen]bool)
+	vegetarian, vegan, rawFish := true, true, false
+	for _, name := range ingredients {
+		info, ok := lookup(name)
+		if !ok {
+			labels.Unknown = append(labels.Unknown, name)
+			continue
+		}
+		for _, a := range info.Allergens {
+			found[a] = true
+		}
+		vegetarian = vegetarian && info.Vegetarian
+		vegan = vegan && info.Vegan
+		rawFish = rawFish || info.RawFish
+	}
+
+	for _, a := range Allergens {
+		if found[a] {
+			labels.Allergens = append(labels.Allergens, a)
+		}
+	}
+	if len(ingredients) > 0 && len(labels.Unknown) == 0 {
+		if vegetarian {
+			labels.Diets = append(labels.Diets, Vegetarian)
+		}
+		if vegan {
+			labels.Diets = append(labels.Diets, Vegan)
+		}
+		if !found[Gluten] {
+			labels.Diets = append(labels.Diets, GlutenFree)
+		}
+	}
+	if rawFish {
+		labels.Diets = append(labels.Diets, RawFish)
+	}
+	return labels
+}
@@ -0,0 +1,54 @@
+package sushi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ComputeLabels(t *testing.T) {
+	infos := map[string]IngredientInfo{
+		"Rice":    {Vegetarian: true, Vegan: true},
+		"Sesame":  {Allergens: []Allergen{Sesame}, Vegetarian: true, Vegan: true},
+		"Tempura": {Allergens: []Allergen{Eggs, Gluten}, Vegetarian: true},
+		"Salmon":  {Allergens: []Allergen{Fish}, RawFish: true},
+		"Crab":    {Allergens: []Allergen{Crustaceans}},
+	}
+	lookup := func(name string) (IngredientInfo, bool) {
+		info, ok := infos[name]
+		return info, ok
+	}
+
+	testData := []struct {
+		name        string
+		ingredients []string
+		expected    Labels
+	}{
+		{name: "vegan", ingredients: []string{"Rice", "Sesame"}, expected: Labels{Allergens: []Allergen{Sesame}, Diets: []Diet{Vegetarian, Vegan, GlutenFree}}},
+		{name: "vegetarian", ingredients: []string{"Rice", "Tempura"}, expected: Labels{Allergens: []Allergen{Gluten, Eggs}, Diets: []Diet{Vegetarian}}},
+		{name: "raw fish", ingredients: []string{"Rice", "Salmon", "Crab"}, expected: Labels{Allergens: []Allergen{Crustaceans, Fish}, Diets: []Diet{GlutenFree, RawFish}}},
+		{name: "unknown ingredient", ingredients: []string{"Salmon", "Secret sauce"}, expected: Labels{Allergens: []Allergen{Fish}, Diets: []Diet{RawFish}, Unknown: []string{"Secret sauce"}}},
+		{name: "no ingredients", expected: Labels{Allergens: []Allergen{}, Diets: []Diet{}}},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ComputeLabels(tt.ingredients, lookup))
+		})
+	}
+}
+
+func Test_ParseLabels(t *testing.T) {
+	allergen, err := ParseAllergen("crustaceans")
+	require.NoError(t, err)
+	assert.Equal(t, Crustaceans, allergen)
+	_, err = ParseAllergen("Crustaceans")
+	assert.EqualError(t, err, `unknown allergen "Crustaceans"`)
+
+	diet, err := ParseDiet("gluten-free")
+	require.NoError(t, err)
+	assert.Equal(t, GlutenFree, diet)
+	_, err = ParseDiet("pescatarian")
+	assert.EqualError(t, err, `unknown diet "pescatarian"`)
+}
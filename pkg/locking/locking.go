@@ -0,0 +1,125 @@
+package locking
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLocked is returned when somebody else holds the lock of the sushi
+	ErrLocked = errors.New("sushi locked")
+	// ErrNotHeld is returned when releasing a lock the token doesn't hold
+	ErrNotHeld = errors.New("lock not held")
+)
+
+// Lock is an advisory lock on a sushi, writes not holding its token are
+// rejected until it expires
+type Lock struct {
+	SushiID string    `json:"id"`
+	Owner   string    `json:"owner"`
+	Token   string    `json:"token,omitempty"`
+	Expires time.Time `json:"expires"`
+}
+
+// Locker manages the edit locks of the sushis
+type Locker interface {
+	// Acquire locks the sushi for ttl. A token holding the lock already
+	// extends it, an empty token asks for a new lock.
+	Acquire(ctx context.Context, sushiID, owner, token string, ttl time.Duration) (Lock, error)
+	// Release unlocks the sushi when the token holds its lock
+	Release(ctx context.Context, sushiID, token string) error
+	// Check tells whether a write carrying the token may modify the sushi
+	Check(ctx context.Context, sushiID, token string) error
+}
+
+type contextKey struct{}
+
+// WithToken returns a copy of ctx carrying the lock token of the caller
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, contextKey{}, token)
+}
+
+// Token returns the lock token carried by ctx, if any
+func Token(ctx context.Context) string {
+	token, _ := ctx.Value(contextKey{}).(string)
+	return token
+}
+
+type memoryLocker struct {
+	mtx   sync.Mutex
+	locks map[string]Lock
+	now   func() time.Time
+}
+
+// NewMemoryLocker creates a Locker keeping the locks in process memory, they
+// aren't shared between replicas
+func NewMemoryLocker() Locker {
+	return &memoryLocker{locks: make(map[string]Lock), now: time.Now}
+}
+
+// Acquire satisfies the Locker interface
+func (l *memoryLocker) Acquire(ctx context.Context, sushiID, owner, token string, ttl time.Duration) (Lock, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.now()
+	lock, ok := l.held(sushiID, now)
+	switch {
+	case ok && (token == "" || lock.Token != token):
+		return Lock{}, locked(lock)
+	case !ok:
+		lock = Lock{SushiID: sushiID, Owner: owner, Token: newToken()}
+	}
+	lock.Expires = now.Add(ttl)
+	l.locks[sushiID] = lock
+	return lock, nil
+}
+
+// Release satisfies the Locker interface
+func (l *memoryLocker) Release(ctx context.Context, sushiID, token string) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	lock, ok := l.held(sushiID, l.now())
+	if !ok || lock.Token != token {
+		return ErrNotHeld
+	}
+	delete(l.locks, sushiID)
+	return nil
+}
+
+// Check satisfies the Locker interface
+func (l *memoryLocker) Check(ctx context.Context, sushiID, token string) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if lock, ok := l.held(sushiID, l.now()); ok && lock.Token != token {
+		return locked(lock)
+	}
+	return nil
+}
+
+// held returns the unexpired lock of the sushi, dropping an expired one
+func (l *memoryLocker) held(sushiID string, now time.Time) (Lock, bool) {
+	lock, ok := l.locks[sushiID]
+	if ok && !now.Before(lock.Expires) {
+		delete(l.locks, sushiID)
+		return Lock{}, false
+	}
+	return lock, ok
+}
+
+func locked(lock Lock) error {
+	return fmt.Errorf("%w by %s until %s", ErrLocked, lock.Owner, lock.Expires.UTC().Format(time.RFC3339))
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
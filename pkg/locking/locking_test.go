@@ -0,0 +1,59 @@
+package locking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MemoryLocker(t *testing.T) {
+	now := time.Now()
+	l := &memoryLocker{locks: make(map[string]Lock), now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	lock, err := l.Acquire(ctx, "01D3XZ38KDR", "alice", "", time.Minute)
+	require.NoError(t, err)
+	assert.NotEmpty(t, lock.Token)
+	assert.Equal(t, now.Add(time.Minute), lock.Expires)
+
+	_, err = l.Acquire(ctx, "01D3XZ38KDR", "bob", "", time.Minute)
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.Contains(t, err.Error(), "by alice")
+
+	assert.ErrorIs(t, l.Check(ctx, "01D3XZ38KDR", ""), ErrLocked)
+	assert.NoError(t, l.Check(ctx, "01D3XZ38KDR", lock.Token))
+	assert.NoError(t, l.Check(ctx, "other", ""), "other sushis aren't locked")
+
+	now = now.Add(30 * time.Second)
+	extended, err := l.Acquire(ctx, "01D3XZ38KDR", "alice", lock.Token, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, lock.Token, extended.Token)
+	assert.Equal(t, now.Add(time.Minute), extended.Expires)
+
+	assert.ErrorIs(t, l.Release(ctx, "01D3XZ38KDR", "forged"), ErrNotHeld)
+	require.NoError(t, l.Release(ctx, "01D3XZ38KDR", lock.Token))
+	assert.NoError(t, l.Check(ctx, "01D3XZ38KDR", ""))
+}
+
+func Test_MemoryLocker_Expiry(t *testing.T) {
+	now := time.Now()
+	l := &memoryLocker{locks: make(map[string]Lock), now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	lock, _ := l.Acquire(ctx, "01D3XZ38KDR", "alice", "", time.Minute)
+	now = now.Add(time.Minute)
+
+	assert.NoError(t, l.Check(ctx, "01D3XZ38KDR", ""), "expired locks don't hold")
+	assert.ErrorIs(t, l.Release(ctx, "01D3XZ38KDR", lock.Token), ErrNotHeld)
+	other, err := l.Acquire(ctx, "01D3XZ38KDR", "bob", "", time.Minute)
+	require.NoError(t, err)
+	assert.NotEqual(t, lock.Token, other.Token)
+}
+
+func Test_Token(t *testing.T) {
+	assert.Empty(t, Token(context.Background()))
+	assert.Equal(t, "t0k3n", Token(WithToken(context.Background(), "t0k3n")))
+}
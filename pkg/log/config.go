@@ -0,0 +1,81 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Level is the severity of a log message
+type Level int
+
+// Levels supported by the loggers, from the most to the least verbose
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel parses a level name such as "info"
+func ParseLevel(s string) (Level, error) {
+	for level, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+// Format is the encoding of the log output
+type Format string
+
+// Formats supported by the loggers
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat parses a format name such as "json"
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatText, FormatJSON:
+		return f, nil
+	}
+	return FormatText, fmt.Errorf("unknown log format %q", s)
+}
+
+// Config defines how the loggers write messages
+type Config struct {
+	Level  Level
+	Format Format
+	Output io.Writer
+}
+
+// DefaultConfig logs info messages and above as text to stderr
+func DefaultConfig() Config {
+	return Config{Level: LevelInfo, Format: FormatText, Output: os.Stderr}
+}
+
+// SyncOutput flushes the output when it's backed by a file
+func SyncOutput(output io.Writer) error {
+	if syncer, ok := output.(Syncer); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package log
+
+// Event is an entry of the log catalogue. IDs are stable, alerts and
+// dashboards can rely on them, so never reuse or change an existing one.
+type Event struct {
+	ID      string
+	Level   Level
+	Message string
+}
+
+// Catalogue of the events logged by the API
+var (
+	UnexpectedErrorEvent       = Event{"01D3XZ38KDR", LevelError, "Unexpected error: %v"}
+	AccessLogEvent             = Event{"01D3XZ38ACC", LevelInfo, "%s %s %d"}
+	SushiCreatedEvent          = Event{"01D3XZ38SCR", LevelInfo, "Sushi %s created"}
+	SushiModifiedEvent         = Event{"01D3XZ38SMD", LevelInfo, "Sushi %s modified"}
+	SushiRemovedEvent          = Event{"01D3XZ38SRM", LevelInfo, "Sushi %s removed"}
+	ValidationFailedEvent      = Event{"01D3XZ38VAL", LevelWarn, "Validation failed: %v"}
+	RepositoryUnavailableEvent = Event{"01D3XZ38REP", LevelError, "Repository unavailable: %v"}
+	WebhookFailedEvent         = Event{"01D3XZ38WHF", LevelWarn, "Webhook %s failed: %v"}
+)
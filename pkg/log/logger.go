@@ -2,10 +2,41 @@ package log
 
 import (
 	"context"
+	"time"
 )
 
 // Logger determine the way to centralize log messages format
 type Logger interface {
 	// UnexpectedError is a standard error message for unexpected errors
 	UnexpectedError(ctx context.Context, err error)
-}
\ No newline at end of file
+	// AccessLog is a standard message for every served request
+	AccessLog(ctx context.Context, access Access)
+	// SushiCreated is a standard message for a sushi added to the menu
+	SushiCreated(ctx context.Context, ID string)
+	// SushiModified is a standard message for a sushi whose data changed
+	SushiModified(ctx context.Context, ID string)
+	// SushiRemoved is a standard message for a sushi removed from the menu
+	SushiRemoved(ctx context.Context, ID string)
+	// ValidationFailed is a standard message for rejected input
+	ValidationFailed(ctx context.Context, err error)
+	// RepositoryUnavailable is a standard message for a storage that can't be reached
+	RepositoryUnavailable(ctx context.Context, err error)
+	// WebhookFailed is a standard message for an event a webhook gave up delivering
+	WebhookFailed(ctx context.Context, subscriptionID string, err error)
+}
+
+// Syncer is implemented by the loggers that buffer messages, Sync must be
+// called before exiting so nothing is lost
+type Syncer interface {
+	Sync() error
+}
+
+// Access describes a served HTTP request
+type Access struct {
+	Method    string
+	Route     string
+	Status    int
+	Bytes     int64
+	Latency   time.Duration
+	Principal string
+}
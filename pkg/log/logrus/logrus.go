@@ -2,46 +2,100 @@ package logrus
 
 import (
 	"context"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/sergiorra/sushi-api-go/pkg/log"
 	"github.com/sergiorra/sushi-api-go/pkg/server"
 )
 
-// Event stores messages to log later, from our standard interface
-type Event struct {
-	id      string
-	message string
-}
-
 // Logger centralize log messages format
 type logger struct {
 	*logrus.Logger
 }
 
 // NewLogger initializes the standard logger
-func NewLogger(hooks ...logrus.Hook) log.Logger {
-	return &logger{logrus.New()}
+func NewLogger(cfg log.Config, hooks ...logrus.Hook) log.Logger {
+	l := logrus.New()
+	if cfg.Output != nil {
+		l.SetOutput(cfg.Output)
+	}
+	l.SetLevel(levels[cfg.Level])
+	if cfg.Format == log.FormatJSON {
+		l.SetFormatter(&logrus.JSONFormatter{})
+	}
+	for _, hook := range hooks {
+		l.AddHook(hook)
+	}
+	return &logger{l}
 }
 
-// Declare variables to store log messages as new Events
-var (
-	unexpectedErrorMessage = Event{"01D3XZ38KDR", "Unexpected error: %v"}
-)
+var levels = map[log.Level]logrus.Level{
+	log.LevelDebug: logrus.DebugLevel,
+	log.LevelInfo:  logrus.InfoLevel,
+	log.LevelWarn:  logrus.WarnLevel,
+	log.LevelError: logrus.ErrorLevel,
+}
 
 func (l *logger) UnexpectedError(ctx context.Context, err error) {
-	l.WithDefaultFields(ctx).WithField("LogId", unexpectedErrorMessage.id).
-		Printf(unexpectedErrorMessage.message, err)
+	l.log(l.WithDefaultFields(ctx), log.UnexpectedErrorEvent, err)
+}
+
+func (l *logger) AccessLog(ctx context.Context, access log.Access) {
+	entry := l.WithDefaultFields(ctx).WithFields(logrus.Fields{
+		"Method":    access.Method,
+		"Route":     access.Route,
+		"Status":    access.Status,
+		"Bytes":     access.Bytes,
+		"LatencyMs": float64(access.Latency.Microseconds()) / 1000,
+		"Principal": access.Principal,
+	})
+	l.log(entry, log.AccessLogEvent, access.Method, access.Route, access.Status)
+}
+
+func (l *logger) SushiCreated(ctx context.Context, ID string) {
+	l.log(l.WithDefaultFields(ctx).WithField("SushiId", ID), log.SushiCreatedEvent, ID)
+}
+
+func (l *logger) SushiModified(ctx context.Context, ID string) {
+	l.log(l.WithDefaultFields(ctx).WithField("SushiId", ID), log.SushiModifiedEvent, ID)
+}
+
+func (l *logger) SushiRemoved(ctx context.Context, ID string) {
+	l.log(l.WithDefaultFields(ctx).WithField("SushiId", ID), log.SushiRemovedEvent, ID)
+}
+
+func (l *logger) ValidationFailed(ctx context.Context, err error) {
+	l.log(l.WithDefaultFields(ctx), log.ValidationFailedEvent, err)
+}
+
+func (l *logger) RepositoryUnavailable(ctx context.Context, err error) {
+	l.log(l.WithDefaultFields(ctx), log.RepositoryUnavailableEvent, err)
+}
+
+func (l *logger) WebhookFailed(ctx context.Context, subscriptionID string, err error) {
+	l.log(l.WithDefaultFields(ctx).WithField("SubscriptionId", subscriptionID), log.WebhookFailedEvent, subscriptionID, err)
+}
+
+// Sync satisfies the log.Syncer interface
+func (l *logger) Sync() error {
+	return log.SyncOutput(l.Out)
+}
+
+func (l *logger) log(entry *logrus.Entry, event log.Event, args ...interface{}) {
+	entry.WithField("LogId", event.ID).Logf(levels[event.Level], event.Message, args...)
 }
 
 func (l *logger) WithDefaultFields(ctx context.Context) *logrus.Entry {
 	serverID, _ := server.ID(ctx)
 	endpoint, _ := server.Endpoint(ctx)
 	clientIP, _ := server.ClientIP(ctx)
+	requestID, _ := server.RequestID(ctx)
 	fields := logrus.Fields{
-		"ServerId": serverID,
-		"Endpoint": endpoint,
-		"ClientIp": clientIP,
+		"ServerId":  serverID,
+		"Endpoint":  endpoint,
+		"ClientIp":  clientIP,
+		"RequestId": requestID,
 	}
 
 	if xForwardedFor, ok := server.XForwardedFor(ctx); ok {
@@ -50,6 +104,9 @@ func (l *logger) WithDefaultFields(ctx context.Context) *logrus.Entry {
 	if xForwardedProto, ok := server.XForwardedProto(ctx); ok {
 		fields["xforwardedproto"] = xForwardedProto
 	}
+	if subject, ok := server.ClientCertSubject(ctx); ok {
+		fields["ClientCertSubject"] = subject
+	}
 
 	return l.WithFields(fields)
 }
@@ -0,0 +1,37 @@
+package logrus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Logger_JSONEvents(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger(log.Config{Level: log.LevelInfo, Format: log.FormatJSON, Output: &out})
+
+	logger.SushiRemoved(context.Background(), "01D3XZ38KDR")
+
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, log.SushiRemovedEvent.ID, line["LogId"])
+	assert.Equal(t, "01D3XZ38KDR", line["SushiId"])
+	assert.Equal(t, "Sushi 01D3XZ38KDR removed", line["msg"])
+	assert.Equal(t, "info", line["level"])
+}
+
+func Test_Logger_Level(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger(log.Config{Level: log.LevelError, Format: log.FormatText, Output: &out})
+
+	logger.ValidationFailed(context.Background(), errors.New("name is required"))
+	assert.Empty(t, out.String())
+
+	logger.UnexpectedError(context.Background(), errors.New("boom"))
+	assert.Contains(t, out.String(), "LogId="+log.UnexpectedErrorEvent.ID)
+}
@@ -12,4 +12,32 @@ func NewNoopLogger() Logger {
 
 func (l *noop) UnexpectedError(ctx context.Context, err error) {
 	// nothing to do here, use for test
-}
\ No newline at end of file
+}
+
+func (l *noop) AccessLog(ctx context.Context, access Access) {
+	// nothing to do here, use for test
+}
+
+func (l *noop) SushiCreated(ctx context.Context, ID string) {
+	// nothing to do here, use for test
+}
+
+func (l *noop) SushiModified(ctx context.Context, ID string) {
+	// nothing to do here, use for test
+}
+
+func (l *noop) SushiRemoved(ctx context.Context, ID string) {
+	// nothing to do here, use for test
+}
+
+func (l *noop) ValidationFailed(ctx context.Context, err error) {
+	// nothing to do here, use for test
+}
+
+func (l *noop) RepositoryUnavailable(ctx context.Context, err error) {
+	// nothing to do here, use for test
+}
+
+func (l *noop) WebhookFailed(ctx context.Context, subscriptionID string, err error) {
+	// nothing to do here, use for test
+}
@@ -0,0 +1,121 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/server"
+)
+
+// Logger centralize log messages format on top of the standard library
+type logger struct {
+	*slog.Logger
+	output io.Writer
+}
+
+// NewLogger initializes a logger backed by log/slog
+func NewLogger(cfg log.Config) log.Logger {
+	output := cfg.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	opts := &slog.HandlerOptions{Level: levels[cfg.Level]}
+	var handler slog.Handler = slog.NewTextHandler(output, opts)
+	if cfg.Format == log.FormatJSON {
+		handler = slog.NewJSONHandler(output, opts)
+	}
+	return &logger{slog.New(handler), output}
+}
+
+var levels = map[log.Level]slog.Level{
+	log.LevelDebug: slog.LevelDebug,
+	log.LevelInfo:  slog.LevelInfo,
+	log.LevelWarn:  slog.LevelWarn,
+	log.LevelError: slog.LevelError,
+}
+
+func (l *logger) UnexpectedError(ctx context.Context, err error) {
+	l.log(ctx, log.UnexpectedErrorEvent, nil, err)
+}
+
+func (l *logger) AccessLog(ctx context.Context, access log.Access) {
+	attrs := []slog.Attr{
+		slog.String("Method", access.Method),
+		slog.String("Route", access.Route),
+		slog.Int("Status", access.Status),
+		slog.Int64("Bytes", access.Bytes),
+		slog.Float64("LatencyMs", float64(access.Latency.Microseconds())/1000),
+		slog.String("Principal", access.Principal),
+	}
+	l.log(ctx, log.AccessLogEvent, attrs, access.Method, access.Route, access.Status)
+}
+
+func (l *logger) SushiCreated(ctx context.Context, ID string) {
+	l.log(ctx, log.SushiCreatedEvent, []slog.Attr{slog.String("SushiId", ID)}, ID)
+}
+
+func (l *logger) SushiModified(ctx context.Context, ID string) {
+	l.log(ctx, log.SushiModifiedEvent, []slog.Attr{slog.String("SushiId", ID)}, ID)
+}
+
+func (l *logger) SushiRemoved(ctx context.Context, ID string) {
+	l.log(ctx, log.SushiRemovedEvent, []slog.Attr{slog.String("SushiId", ID)}, ID)
+}
+
+func (l *logger) ValidationFailed(ctx context.Context, err error) {
+	l.log(ctx, log.ValidationFailedEvent, nil, err)
+}
+
+func (l *logger) RepositoryUnavailable(ctx context.Context, err error) {
+	l.log(ctx, log.RepositoryUnavailableEvent, nil, err)
+}
+
+func (l *logger) WebhookFailed(ctx context.Context, subscriptionID string, err error) {
+	l.log(ctx, log.WebhookFailedEvent, []slog.Attr{slog.String("SubscriptionId", subscriptionID)}, subscriptionID, err)
+}
+
+// Sync satisfies the log.Syncer interface
+func (l *logger) Sync() error {
+	return log.SyncOutput(l.output)
+}
+
+func (l *logger) log(ctx context.Context, event log.Event, attrs []slog.Attr, args ...interface{}) {
+	level := levels[event.Level]
+	if !l.Enabled(ctx, level) {
+		return
+	}
+
+	attrs = append(defaultAttrs(ctx), attrs...)
+	attrs = append(attrs, slog.String("LogId", event.ID))
+	l.LogAttrs(ctx, level, fmt.Sprintf(event.Message, args...), attrs...)
+}
+
+func defaultAttrs(ctx context.Context) []slog.Attr {
+	serverID, _ := server.ID(ctx)
+	endpoint, _ := server.Endpoint(ctx)
+	clientIP, _ := server.ClientIP(ctx)
+	requestID, _ := server.RequestID(ctx)
+	attrs := []slog.Attr{
+		slog.String("ServerId", serverID),
+		slog.String("Endpoint", endpoint),
+		slog.String("ClientIp", clientIP),
+		slog.String("RequestId", requestID),
+	}
+
+	if xForwardedFor, ok := server.XForwardedFor(ctx); ok {
+		attrs = append(attrs, slog.String("xforwardedfor", xForwardedFor))
+	}
+	if xForwardedProto, ok := server.XForwardedProto(ctx); ok {
+		attrs = append(attrs, slog.String("xforwardedproto", xForwardedProto))
+	}
+	if subject, ok := server.ClientCertSubject(ctx); ok {
+		attrs = append(attrs, slog.String("ClientCertSubject", subject))
+	}
+
+	return attrs
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+type repository struct {
+	next    sushi.Repository
+	backend string
+
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+}
+
+// NewRepository decorates a sushi.Repository recording the count, errors and
+// latency of every operation, labelled with the given backend name
+func NewRepository(next sushi.Repository, backend string, reg prometheus.Registerer) sushi.Repository {
+	r := &repository{
+		next:    next,
+		backend: backend,
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "operations_total",
+			Help:      "Number of repository operations, by backend and operation.",
+		}, []string{"backend", "operation"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "errors_total",
+			Help:      "Number of failed repository operations, by backend and operation.",
+		}, []string{"backend", "operation"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of the repository operations, by backend and operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"backend", "operation"}),
+	}
+	reg.MustRegister(r.operations, r.errors, r.latency)
+	return r
+}
+
+// CreateSushi satisfies the sushi.Repository interface
+func (r *repository) CreateSushi(ctx context.Context, s *sushi.Sushi) error {
+	start := time.Now()
+	err := r.next.CreateSushi(ctx, s)
+	r.observe("create", start, err)
+	return err
+}
+
+// GetSushis satisfies the sushi.Repository interface
+func (r *repository) GetSushis(ctx context.Context) ([]sushi.Sushi, error) {
+	start := time.Now()
+	sushis, err := r.next.GetSushis(ctx)
+	r.observe("list", start, err)
+	return sushis, err
+}
+
+// StreamSushis satisfies the sushi.Streamer interface, the latency covers
+// the whole iteration
+func (r *repository) StreamSushis(ctx context.Context) iter.Seq2[sushi.Sushi, error] {
+	return func(yield func(sushi.Sushi, error) bool) {
+		start := time.Now()
+		var streamErr error
+		defer func() { r.observe("stream", start, streamErr) }()
+		for s, err := range sushi.Stream(ctx, r.next) {
+			if err != nil {
+				streamErr = err
+			}
+			if !yield(s, err) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteSushi satisfies the sushi.Repository interface
+func (r *repository) DeleteSushi(ctx context.Context, ID string) error {
+	start := time.Now()
+	err := r.next.DeleteSushi(ctx, ID)
+	r.observe("delete", start, err)
+	return err
+}
+
+// UpdateSushi satisfies the sushi.Repository interface
+func (r *repository) UpdateSushi(ctx context.Context, ID string, s *sushi.Sushi) error {
+	start := time.Now()
+	err := r.next.UpdateSushi(ctx, ID, s)
+	r.observe("update", start, err)
+	return err
+}
+
+// GetSushiByID satisfies the sushi.Repository interface
+func (r *repository) GetSushiByID(ctx context.Context, ID string) (*sushi.Sushi, error) {
+	start := time.Now()
+	s, err := r.next.GetSushiByID(ctx, ID)
+	r.observe("get", start, err)
+	return s, err
+}
+
+// Version satisfies the sushi.Versioner interface when the decorated
+// repository does, returning sushi.ErrUnversioned otherwise
+func (r *repository) Version(ctx context.Context) (sushi.Version, error) {
+	start := time.Now()
+	version, err := sushi.CurrentVersion(ctx, r.next)
+	if !errors.Is(err, sushi.ErrUnversioned) {
+		r.observe("version", start, err)
+	}
+	return version, err
+}
+
+func (r *repository) observe(operation string, start time.Time, err error) {
+	r.operations.WithLabelValues(r.backend, operation).Inc()
+	r.latency.WithLabelValues(r.backend, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		r.errors.WithLabelValues(r.backend, operation).Inc()
+	}
+}
+
+// Ping satisfies the sushi.Pinger interface when the decorated repository does
+func (r *repository) Ping(ctx context.Context) error {
+	if pinger, ok := r.next.(sushi.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Close releases the decorated repository when it holds resources
+func (r *repository) Close() error {
+	if closer, ok := r.next.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/storage/inmem"
+)
+
+func Test_Repository_RecordsOperations(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	repo := NewRepository(inmem.NewRepository(nil), "inmem", reg).(*repository)
+
+	s := sushi.New("01D3XZ38KDR", "1", "California Roll", nil)
+	assert.NoError(t, repo.CreateSushi(context.Background(), s))
+	assert.Error(t, repo.CreateSushi(context.Background(), s))
+	_, err := repo.GetSushis(context.Background())
+	assert.NoError(t, err)
+	streamed := 0
+	for _, err := range repo.StreamSushis(context.Background()) {
+		assert.NoError(t, err)
+		streamed++
+	}
+	assert.Equal(t, 1, streamed)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(repo.operations.WithLabelValues("inmem", "create")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(repo.errors.WithLabelValues("inmem", "create")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(repo.operations.WithLabelValues("inmem", "list")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(repo.errors.WithLabelValues("inmem", "list")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(repo.operations.WithLabelValues("inmem", "stream")))
+	assert.Equal(t, 3, testutil.CollectAndCount(repo.latency))
+}
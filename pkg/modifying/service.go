@@ -2,25 +2,85 @@ package modifying
 
 import (
 	"context"
+	"time"
+
 	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/ingredients"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/tracing"
+	"go.opentelemetry.io/otel"
 )
 
 // Service provides modifying operations
 type Service interface {
-	ModifySushi(ctx context.Context, ID, ImageNumber, Name string, Ingredients []string) error
+	ModifySushi(ctx context.Context, ID, ImageNumber, Name string, Ingredients []string, Pricing sushi.Pricing) error
 }
 
+var tracer = otel.Tracer("github.com/sergiorra/sushi-api-go/pkg/modifying")
+
 type service struct {
 	repository sushi.Repository
+	logger     log.Logger
+	publisher  events.Publisher
+	locker     locking.Locker
+	// ingredients is nil when the ingredients are free-form
+	ingredients *ingredients.Catalogue
+}
+
+// Option configures a modifying service
+type Option func(*service)
+
+// WithIngredients refuses the sushis made of ingredients missing from the
+// catalogue, the ingredients are the IDs of its entries
+func WithIngredients(catalogue *ingredients.Catalogue) Option {
+	return func(s *service) {
+		s.ingredients = catalogue
+	}
 }
 
 // NewService creates a modifying service with the necessary dependencies
-func NewService(repository sushi.Repository) Service {
-	return &service{repository}
+func NewService(repository sushi.Repository, logger log.Logger, publisher events.Publisher, locker locking.Locker, opts ...Option) Service {
+	s := &service{repository: repository, logger: logger, publisher: publisher, locker: locker}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // ModifySushi modify a sushi data
-func (s *service) ModifySushi(ctx context.Context, ID, ImageNumber, Name string, Ingredients []string) error {
+func (s *service) ModifySushi(ctx context.Context, ID, ImageNumber, Name string, Ingredients []string, Pricing sushi.Pricing) error {
+	ctx, span := tracer.Start(ctx, "modifying.ModifySushi")
+	defer span.End()
+
 	sushi := sushi.New(ID, ImageNumber, Name, Ingredients)
-	return s.repository.UpdateSushi(ctx, ID, sushi)
+	sushi.Pricing = Pricing
+	now := time.Now()
+	sushi.UpdatedAt = &now
+	if err := sushi.Validate(); err != nil {
+		s.logger.ValidationFailed(ctx, err)
+		return err
+	}
+
+	err := s.ingredients.Reference(ctx, s.logger, sushi.Ingredients, func() error {
+		// somebody else editing the sushi holds its lock
+		if err := s.locker.Check(ctx, ID, locking.Token(ctx)); err != nil {
+			return err
+		}
+
+		err := s.repository.UpdateSushi(ctx, ID, sushi)
+		if err != nil {
+			tracing.Fail(ctx, err)
+			s.logger.UnexpectedError(ctx, err)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.SushiModified(ctx, ID)
+	s.publisher.Publish(ctx, events.New(events.SushiModified, ID, sushi))
+	return nil
 }
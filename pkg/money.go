@@ -0,0 +1,95 @@
+package sushi
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// Money is an amount in the minor unit of its ISO 4217 currency, cents of
+// euro or yens, so prices never go through floats
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// exponents lists the currencies whose minor unit isn't a hundredth
+var exponents = map[string]int{
+	"BHD": 3, "BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IQD": 3, "ISK": 0,
+	"JOD": 3, "JPY": 0, "KMF": 0, "KRW": 0, "KWD": 3, "LYD": 3, "OMR": 3,
+	"PYG": 0, "RWF": 0, "TND": 3, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// Validate checks the currency is an ISO 4217 code and the amount isn't
+// negative
+func (m Money) Validate() error {
+	if !currencyPattern.MatchString(m.Currency) {
+		return fmt.Errorf("currency %q must be an ISO 4217 code", m.Currency)
+	}
+	if m.Amount < 0 {
+		return fmt.Errorf("amount %d can't be negative", m.Amount)
+	}
+	return nil
+}
+
+// String formats the amount in the major unit, like "12.50 EUR"
+func (m Money) String() string {
+	exponent, ok := exponents[m.Currency]
+	if !ok {
+		exponent = 2
+	}
+	digits := strconv.FormatInt(m.Amount, 10)
+	sign := ""
+	if digits[0] == '-' {
+		sign, digits = "-", digits[1:]
+	}
+	if exponent > 0 {
+		for len(digits) <= exponent {
+			digits = "0" + digits
+		}
+		digits = digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+	}
+	return sign + digits + " " + m.Currency
+}
+
+// Pricing is what a sushi costs, the restaurants may charge their own price
+type Pricing struct {
+	// Price is the base price, nil when the sushi isn't priced
+	Price *Money `json:"price,omitempty"`
+	// Prices overrides the base price in the restaurants, by restaurant ID
+	Prices map[string]Money `json:"prices,omitempty"`
+}
+
+// PriceAt returns the price of the sushi in the restaurant, its base price
+// when the restaurant has no price of its own or is empty. It is nil when the
+// sushi isn't priced there.
+func (p Pricing) PriceAt(restaurant string) *Money {
+	if price, ok := p.Prices[restaurant]; ok && restaurant != "" {
+		return &price
+	}
+	return p.Price
+}
+
+// Validate checks the prices and the restaurant IDs
+func (p Pricing) Validate() error {
+	if p.Price != nil {
+		if err := p.Price.Validate(); err != nil {
+			return fmt.Errorf("%w: price: %v", ErrInvalidSushi, err)
+		}
+	}
+	for _, restaurant := range slices.Sorted(maps.Keys(p.Prices)) {
+		price := p.Prices[restaurant]
+		if !idPattern.MatchString(restaurant) {
+			return fmt.Errorf("%w: restaurant %q must be alphanumeric", ErrInvalidSushi, restaurant)
+		}
+		if err := price.Validate(); err != nil {
+			return fmt.Errorf("%w: price in %s: %v", ErrInvalidSushi, restaurant, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package sushi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Money_String(t *testing.T) {
+	assert.Equal(t, "12.50 EUR", Money{Amount: 1250, Currency: "EUR"}.String())
+	assert.Equal(t, "0.05 USD", Money{Amount: 5, Currency: "USD"}.String())
+	assert.Equal(t, "1200 JPY", Money{Amount: 1200, Currency: "JPY"}.String())
+	assert.Equal(t, "1.250 KWD", Money{Amount: 1250, Currency: "KWD"}.String())
+	assert.Equal(t, "-0.50 EUR", Money{Amount: -50, Currency: "EUR"}.String())
+}
+
+func Test_Pricing_PriceAt(t *testing.T) {
+	p := Pricing{
+		Price:  &Money{Amount: 850, Currency: "EUR"},
+		Prices: map[string]Money{"tokyo": {Amount: 1200, Currency: "JPY"}},
+	}
+	assert.Equal(t, &Money{Amount: 1200, Currency: "JPY"}, p.PriceAt("tokyo"))
+	assert.Equal(t, &Money{Amount: 850, Currency: "EUR"}, p.PriceAt("paris"))
+	assert.Equal(t, &Money{Amount: 850, Currency: "EUR"}, p.PriceAt(""))
+	assert.Nil(t, Pricing{}.PriceAt("tokyo"))
+}
+
+func Test_Validate_Pricing(t *testing.T) {
+	valid := Sushi{ID: "nigiri", Name: "Nigiri", Pricing: Pricing{
+		Price:  &Money{Amount: 0, Currency: "EUR"},
+		Prices: map[string]Money{"tokyo": {Amount: 1200, Currency: "JPY"}},
+	}}
+	assert.NoError(t, valid.Validate())
+
+	for name, p := range map[string]Pricing{
+		`currency "eur" must be an ISO 4217 code`:    {Price: &Money{Amount: 100, Currency: "eur"}},
+		"amount -1 can't be negative":                {Price: &Money{Amount: -1, Currency: "EUR"}},
+		`restaurant "new york" must be alphanumeric`: {Prices: map[string]Money{"new york": {Amount: 100, Currency: "USD"}}},
+		`price in tokyo: currency "" must be`:        {Prices: map[string]Money{"tokyo": {Amount: 100}}},
+	} {
+		s := Sushi{ID: "nigiri", Name: "Nigiri", Pricing: p}
+		err := s.Validate()
+		assert.ErrorIs(t, err, ErrInvalidSushi)
+		assert.ErrorContains(t, err, name)
+	}
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is the amount of takes between two sweeps of idle buckets
+const sweepEvery = 1024
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	idle   time.Duration
+}
+
+type memoryStore struct {
+	mtx     sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+	now     func() time.Time
+}
+
+// NewMemoryStore creates a Store that keeps the buckets in process memory
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take satisfies the Store interface
+func (s *memoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	var res Result
+	b.tokens, res = take(b.tokens, b.last, now, limit)
+	b.last = now
+	b.idle = res.Reset
+
+	return res, nil
+}
+
+// sweep drops the buckets that have been refilled completely, they are
+// equivalent to a missing bucket
+func (s *memoryStore) sweep(now time.Time) {
+	s.takes++
+	if s.takes%sweepEvery != 0 {
+		return
+	}
+
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.idle {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit defines a token bucket: Rate tokens are added per second up to Burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Enabled reports whether the limit restricts anything at all
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// ParseLimit parses a limit written as "rate:burst", e.g. "5:10"
+func ParseLimit(s string) (Limit, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected rate:burst", s)
+	}
+
+	rate, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || rate < 0 {
+		return Limit{}, fmt.Errorf("invalid rate in rate limit %q", s)
+	}
+	burst, err := strconv.Atoi(parts[1])
+	if err != nil || burst < 0 {
+		return Limit{}, fmt.Errorf("invalid burst in rate limit %q", s)
+	}
+
+	return Limit{Rate: rate, Burst: burst}, nil
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store keeps the state of the buckets
+type Store interface {
+	// Take removes a token from the bucket identified by key
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Policy defines the limits applied per route, routes are identified by
+// "METHOD /path/template", e.g. "GET /sushi/{ID}"
+type Policy struct {
+	Default Limit
+	Routes  map[string]Limit
+}
+
+// ParsePolicy parses route limits written as "GET /sushi=5:10,POST /sushi=1:2"
+func ParsePolicy(defaultLimit Limit, routes string) (Policy, error) {
+	policy := Policy{Default: defaultLimit, Routes: make(map[string]Limit)}
+	if strings.TrimSpace(routes) == "" {
+		return policy, nil
+	}
+
+	for _, route := range strings.Split(routes, ",") {
+		i := strings.LastIndex(route, "=")
+		if i < 0 {
+			return Policy{}, fmt.Errorf("invalid route rate limit %q, expected route=rate:burst", route)
+		}
+		limit, err := ParseLimit(route[i+1:])
+		if err != nil {
+			return Policy{}, err
+		}
+		policy.Routes[strings.TrimSpace(route[:i])] = limit
+	}
+
+	return policy, nil
+}
+
+// LimitFor returns the limit that applies to the given route
+func (p Policy) LimitFor(route string) Limit {
+	if limit, ok := p.Routes[route]; ok {
+		return limit
+	}
+	return p.Default
+}
+
+// take refills the bucket since last and tries to remove a token from it
+func take(tokens float64, last, now time.Time, limit Limit) (float64, Result) {
+	elapsed := now.Sub(last).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	tokens = math.Min(float64(limit.Burst), tokens+elapsed*limit.Rate)
+
+	res := Result{Limit: limit.Burst}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+	res.Remaining = int(math.Floor(tokens))
+	res.Reset = seconds((float64(limit.Burst) - tokens) / limit.Rate)
+
+	return tokens, res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy(Limit{Rate: 1, Burst: 2}, "GET /sushi=5:10, POST /sushi=0.5:1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Rate: 5, Burst: 10}, policy.LimitFor("GET /sushi"))
+	assert.Equal(t, Limit{Rate: 0.5, Burst: 1}, policy.LimitFor("POST /sushi"))
+	assert.Equal(t, Limit{Rate: 1, Burst: 2}, policy.LimitFor("DELETE /sushi/{ID}"))
+
+	_, err = ParsePolicy(Limit{}, "GET /sushi")
+	assert.Error(t, err)
+}
+
+func Test_MemoryStore_Take(t *testing.T) {
+	now := time.Now()
+	store := &memoryStore{buckets: make(map[string]*bucket), now: func() time.Time { return now }}
+	limit := Limit{Rate: 1, Burst: 2}
+
+	res, _ := store.Take(context.Background(), "client", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+
+	res, _ = store.Take(context.Background(), "client", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	res, _ = store.Take(context.Background(), "client", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	res, _ = store.Take(context.Background(), "other", limit)
+	assert.True(t, res.Allowed)
+
+	now = now.Add(time.Second)
+	res, _ = store.Take(context.Background(), "client", limit)
+	assert.True(t, res.Allowed)
+}
+
+func Test_RedisStore_Take(t *testing.T) {
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", s.Addr()) }}
+	now := time.Now()
+	replicaA := &redisStore{pool: pool, prefix: "ratelimit:", now: func() time.Time { return now }}
+	replicaB := &redisStore{pool: pool, prefix: "ratelimit:", now: func() time.Time { return now }}
+	limit := Limit{Rate: 1, Burst: 2}
+
+	res, err := replicaA.Take(context.Background(), "client", limit)
+	assert.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+
+	res, err = replicaB.Take(context.Background(), "client", limit)
+	assert.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = replicaA.Take(context.Background(), "client", limit)
+	assert.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	now = now.Add(time.Second)
+	res, err = replicaB.Take(context.Background(), "client", limit)
+	assert.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// tokenBucketScript refills and takes a token atomically, so replicas sharing
+// the same Redis see a single bucket per key. Numbers are returned as strings
+// because Redis truncates Lua numbers to integers.
+var tokenBucketScript = redis.NewScript(1, `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1]) or burst
+local last = tonumber(state[2]) or now
+
+local elapsed = math.max(0, now - last) / 1000
+tokens = math.min(burst, tokens + elapsed * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "last", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+type redisStore struct {
+	pool   *redis.Pool
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisStore creates a Store that keeps the buckets in Redis so limits are
+// shared across replicas
+func NewRedisStore(pool *redis.Pool, prefix string) Store {
+	return &redisStore{pool: pool, prefix: prefix, now: time.Now}
+}
+
+// Take satisfies the Store interface
+func (s *redisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+
+	now := s.now().UnixNano() / int64(time.Millisecond)
+	reply, err := redis.Values(tokenBucketScript.Do(conn, s.prefix+key, limit.Rate, limit.Burst, now))
+	if err != nil {
+		return Result{}, err
+	}
+
+	var (
+		allowed int
+		raw     string
+	)
+	if _, err := redis.Scan(reply, &allowed, &raw); err != nil {
+		return Result{}, err
+	}
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{
+		Allowed:   allowed == 1,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !res.Allowed {
+		res.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+
+	return res, nil
+}
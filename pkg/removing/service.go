@@ -2,7 +2,12 @@ package removing
 
 import (
 	"context"
+
 	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/tracing"
+	"go.opentelemetry.io/otel"
 )
 
 // Service provides removing operations
@@ -10,16 +15,31 @@ type Service interface {
 	RemoveSushi(ctx context.Context, ID string) error
 }
 
+var tracer = otel.Tracer("github.com/sergiorra/sushi-api-go/pkg/removing")
+
 type service struct {
 	repository sushi.Repository
+	logger     log.Logger
+	publisher  events.Publisher
 }
 
 // NewService creates a removing service with the necessary dependencies
-func NewService(repository sushi.Repository) Service {
-	return &service{repository}
+func NewService(repository sushi.Repository, logger log.Logger, publisher events.Publisher) Service {
+	return &service{repository, logger, publisher}
 }
 
 // RemoveSushi remove sushi from the storage
 func (s *service) RemoveSushi(ctx context.Context, ID string) error {
-	return s.repository.DeleteSushi(ctx, ID)
+	ctx, span := tracer.Start(ctx, "removing.RemoveSushi")
+	defer span.End()
+
+	if err := s.repository.DeleteSushi(ctx, ID); err != nil {
+		tracing.Fail(ctx, err)
+		s.logger.UnexpectedError(ctx, err)
+		return err
+	}
+
+	s.logger.SushiRemoved(ctx, ID)
+	s.publisher.Publish(ctx, events.New(events.SushiRemoved, ID, nil))
+	return nil
 }
@@ -0,0 +1,356 @@
+// Package rpc serves the sushi service over gRPC, on top of the same services
+// as the REST API
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/adding"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/getting"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/modifying"
+	"github.com/sergiorra/sushi-api-go/pkg/removing"
+	sushipb "github.com/sergiorra/sushi-api-go/pkg/rpc/sushi/v1"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+type service struct {
+	sushipb.UnimplementedSushiServiceServer
+
+	getting   getting.Service
+	adding    adding.Service
+	modifying modifying.Service
+	removing  removing.Service
+	bus       *events.Bus
+	logger    log.Logger
+}
+
+// Server is a gRPC server exposing the sushi service along with the health
+// and reflection services
+type Server struct {
+	*grpc.Server
+	health *health.Server
+}
+
+// NewServer creates the gRPC server, the options are given to grpc.NewServer.
+// WatchSushis is unimplemented when bus is nil.
+func NewServer(gS getting.Service, aS adding.Service, mS modifying.Service, rS removing.Service, bus *events.Bus, logger log.Logger, opts ...grpc.ServerOption) *Server {
+	s := &service{getting: gS, adding: aS, modifying: mS, removing: rS, bus: bus, logger: logger}
+
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(s.recoverUnary),
+		grpc.ChainStreamInterceptor(s.recoverStream),
+	)
+	server := grpc.NewServer(opts...)
+	sushipb.RegisterSushiServiceServer(server, s)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(sushipb.SushiService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	return &Server{Server: server, health: healthServer}
+}
+
+// Drain reports every service as not serving, so the clients checking the
+// health go elsewhere while the server keeps answering
+func (s *Server) Drain() {
+	s.health.Shutdown()
+}
+
+// Shutdown drains the server then waits for the running calls to finish. Once
+// ctx is done the remaining calls are cancelled.
+func (s *Server) Shutdown(ctx context.Context) {
+	s.Drain()
+
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
+
+// GetSushi satisfies the SushiServiceServer interface
+func (s *service) GetSushi(ctx context.Context, req *sushipb.GetSushiRequest) (*sushipb.GetSushiResponse, error) {
+	sushi := s.getting.GetSushiByID(ctx, req.GetId())
+	if sushi == nil {
+		return nil, status.Errorf(codes.NotFound, "sushi %q not found", req.GetId())
+	}
+	return &sushipb.GetSushiResponse{Sushi: toProto(sushi)}, nil
+}
+
+// ListSushis satisfies the SushiServiceServer interface. The pages are
+// ordered by ID and the page token is the last ID of the previous page, so
+// paging stays consistent while sushis are added or removed.
+func (s *service) ListSushis(ctx context.Context, req *sushipb.ListSushisRequest) (*sushipb.ListSushisResponse, error) {
+	size := int(req.GetPageSize())
+	switch {
+	case size < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size can't be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+
+	var after string
+	if token := req.GetPageToken(); token != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil || len(decoded) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		after = string(decoded)
+	}
+
+	filter := getting.Filter{
+		PriceMin:   req.PriceMin,
+		PriceMax:   req.PriceMax,
+		Currency:   req.GetCurrency(),
+		Restaurant: req.GetRestaurant(),
+	}
+	for _, a := range req.GetExcludeAllergens() {
+		filter.ExcludeAllergens = append(filter.ExcludeAllergens, sushiapi.Allergen(a))
+	}
+	for _, d := range req.GetDiets() {
+		filter.Diets = append(filter.Diets, sushiapi.Diet(d))
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var sushis []sushiapi.Sushi
+	for sushi, err := range s.getting.StreamSushis(ctx, filter) {
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, "the sushis can't be listed")
+		}
+		if after == "" || sushi.ID > after {
+			sushis = append(sushis, sushi)
+		}
+	}
+	slices.SortFunc(sushis, func(a, b sushiapi.Sushi) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	res := &sushipb.ListSushisResponse{}
+	if len(sushis) > size {
+		sushis = sushis[:size]
+		res.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(sushis[size-1].ID))
+	}
+	for i := range sushis {
+		res.Sushis = append(res.Sushis, toProto(&sushis[i]))
+	}
+	return res, nil
+}
+
+// AddSushi satisfies the SushiServiceServer interface
+func (s *service) AddSushi(ctx context.Context, req *sushipb.AddSushiRequest) (*sushipb.AddSushiResponse, error) {
+	if err := s.adding.AddSushi(ctx, req.GetId(), req.GetImageNumber(), req.GetName(), req.GetIngredients(), pricingFromProto(req.GetPrice(), req.GetPrices())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &sushipb.AddSushiResponse{}, nil
+}
+
+// ModifySushi satisfies the SushiServiceServer interface
+func (s *service) ModifySushi(ctx context.Context, req *sushipb.ModifySushiRequest) (*sushipb.ModifySushiResponse, error) {
+	ctx = locking.WithToken(ctx, req.GetLockToken())
+	if err := s.modifying.ModifySushi(ctx, req.GetId(), req.GetImageNumber(), req.GetName(), req.GetIngredients(), pricingFromProto(req.GetPrice(), req.GetPrices())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &sushipb.ModifySushiResponse{}, nil
+}
+
+// RemoveSushi satisfies the SushiServiceServer interface
+func (s *service) RemoveSushi(ctx context.Context, req *sushipb.RemoveSushiRequest) (*sushipb.RemoveSushiResponse, error) {
+	if err := s.removing.RemoveSushi(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &sushipb.RemoveSushiResponse{}, nil
+}
+
+// WatchSushis satisfies the SushiServiceServer interface. A client resuming
+// with last_event_id gets the events it missed first, or a reset event when
+// they were forgotten so it lists the menu again.
+func (s *service) WatchSushis(req *sushipb.WatchSushisRequest, stream sushipb.SushiService_WatchSushisServer) error {
+	if s.bus == nil {
+		return status.Error(codes.Unimplemented, "the events are disabled")
+	}
+
+	subscription, missed, resumed := s.bus.Subscribe(req.GetLastEventId())
+	defer s.bus.Unsubscribe(subscription)
+	// the client knows it is subscribed once it gets the headers
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	send := func(event events.Event) error {
+		if len(req.GetIds()) > 0 && !slices.Contains(req.GetIds(), event.SushiID) {
+			return nil
+		}
+		return stream.Send(&sushipb.WatchSushisResponse{Event: eventToProto(event)})
+	}
+
+	if !resumed {
+		reset := &sushipb.SushiEvent{Type: sushipb.SushiEvent_TYPE_RESET, Time: timestamppb.Now()}
+		if err := stream.Send(&sushipb.WatchSushisResponse{Event: reset}); err != nil {
+			return err
+		}
+	}
+	for _, event := range missed {
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// dropped for falling behind or shutting down, the client
+				// resumes from its last event
+				if errors.Is(subscription.Err(), events.ErrOverflow) {
+					return status.Error(codes.ResourceExhausted, subscription.Err().Error())
+				}
+				return status.Error(codes.Unavailable, "the event stream ended")
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *service) recoverUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			s.logger.UnexpectedError(ctx, fmt.Errorf("panic in %s: %v", info.FullMethod, p))
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func (s *service) recoverStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			s.logger.UnexpectedError(ss.Context(), fmt.Errorf("panic in %s: %v", info.FullMethod, p))
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// toStatus maps the errors of the services to the gRPC codes, the services
+// already logged them
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, sushiapi.ErrInvalidSushi):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, locking.ErrLocked):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func toProto(s *sushiapi.Sushi) *sushipb.Sushi {
+	pb := &sushipb.Sushi{
+		Id:          s.ID,
+		ImageNumber: s.ImageNumber,
+		Name:        s.Name,
+		Ingredients: s.Ingredients,
+	}
+	if s.CreatedAt != nil {
+		pb.CreateTime = timestamppb.New(*s.CreatedAt)
+	}
+	if s.UpdatedAt != nil {
+		pb.UpdateTime = timestamppb.New(*s.UpdatedAt)
+	}
+	if s.Price != nil {
+		pb.Price = moneyToProto(*s.Price)
+	}
+	if len(s.Prices) > 0 {
+		pb.Prices = make(map[string]*sushipb.Money, len(s.Prices))
+		for restaurant, price := range s.Prices {
+			pb.Prices[restaurant] = moneyToProto(price)
+		}
+	}
+	if s.Labels != nil {
+		pb.Labels = &sushipb.Labels{UnknownIngredients: s.Labels.Unknown}
+		for _, a := range s.Labels.Allergens {
+			pb.Labels.Allergens = append(pb.Labels.Allergens, string(a))
+		}
+		for _, d := range s.Labels.Diets {
+			pb.Labels.Diets = append(pb.Labels.Diets, string(d))
+		}
+	}
+	return pb
+}
+
+func moneyToProto(m sushiapi.Money) *sushipb.Money {
+	return &sushipb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+func pricingFromProto(price *sushipb.Money, prices map[string]*sushipb.Money) sushiapi.Pricing {
+	var p sushiapi.Pricing
+	if price != nil {
+		p.Price = &sushiapi.Money{Amount: price.GetAmount(), Currency: price.GetCurrency()}
+	}
+	if len(prices) > 0 {
+		p.Prices = make(map[string]sushiapi.Money, len(prices))
+		for restaurant, price := range prices {
+			p.Prices[restaurant] = sushiapi.Money{Amount: price.GetAmount(), Currency: price.GetCurrency()}
+		}
+	}
+	return p
+}
+
+var eventTypes = map[events.Type]sushipb.SushiEvent_Type{
+	events.SushiCreated:  sushipb.SushiEvent_TYPE_CREATED,
+	events.SushiModified: sushipb.SushiEvent_TYPE_MODIFIED,
+	events.SushiRemoved:  sushipb.SushiEvent_TYPE_REMOVED,
+}
+
+func eventToProto(event events.Event) *sushipb.SushiEvent {
+	pb := &sushipb.SushiEvent{
+		Id:      event.ID,
+		Type:    eventTypes[event.Type],
+		Time:    timestamppb.New(event.Time),
+		SushiId: event.SushiID,
+	}
+	if event.Sushi != nil {
+		pb.Sushi = toProto(event.Sushi)
+	}
+	return pb
+}
@@ -0,0 +1,368 @@
+package rpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	sushipb "github.com/sergiorra/sushi-api-go/pkg/rpc/sushi/v1"
+	"github.com/sergiorra/sushi-api-go/pkg/servicetest"
+	"github.com/sergiorra/sushi-api-go/pkg/tlsconfig"
+)
+
+type fixture struct {
+	client   sushipb.SushiServiceClient
+	health   healthpb.HealthClient
+	server   *Server
+	locker   locking.Locker
+	bus      *events.Bus
+	listener *bufconn.Listener
+}
+
+// newFixture serves the sushis, the client of the fixture connects without
+// TLS
+func newFixture(t *testing.T, sushis map[string]sushiapi.Sushi, opts ...grpc.ServerOption) *fixture {
+	t.Helper()
+
+	services := servicetest.New(sushis)
+	server := NewServer(services.Getting, services.Adding, services.Modifying, services.Removing, services.Bus, services.Logger, opts...)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	f := &fixture{server: server, locker: services.Locker, bus: services.Bus, listener: listener}
+	conn := f.dial(t, insecure.NewCredentials())
+	f.client = sushipb.NewSushiServiceClient(conn)
+	f.health = healthpb.NewHealthClient(conn)
+	return f
+}
+
+// dial connects to the server of the fixture with the credentials
+func (f *fixture) dial(t *testing.T, creds credentials.TransportCredentials) *grpc.ClientConn {
+	t.Helper()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return f.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(creds),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func Test_GetSushi(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{
+		"nigiri": {ID: "nigiri", Name: "Nigiri", Ingredients: []string{"rice", "salmon"}},
+	})
+
+	res, err := f.client.GetSushi(context.Background(), &sushipb.GetSushiRequest{Id: "nigiri"})
+	require.NoError(t, err)
+	assert.Equal(t, "Nigiri", res.GetSushi().GetName())
+	assert.Equal(t, []string{"rice", "salmon"}, res.GetSushi().GetIngredients())
+
+	_, err = f.client.GetSushi(context.Background(), &sushipb.GetSushiRequest{Id: "uramaki"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func Test_ListSushis_Pages(t *testing.T) {
+	sushis := make(map[string]sushiapi.Sushi)
+	for i := 0; i < 5; i++ {
+		ID := fmt.Sprintf("sushi_%d", i)
+		sushis[ID] = sushiapi.Sushi{ID: ID, Name: ID}
+	}
+	f := newFixture(t, sushis)
+
+	var IDs []string
+	var token string
+	pages := 0
+	for {
+		res, err := f.client.ListSushis(context.Background(), &sushipb.ListSushisRequest{PageSize: 2, PageToken: token})
+		require.NoError(t, err)
+		pages++
+		for _, s := range res.GetSushis() {
+			IDs = append(IDs, s.GetId())
+		}
+		if token = res.GetNextPageToken(); token == "" {
+			break
+		}
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []string{"sushi_0", "sushi_1", "sushi_2", "sushi_3", "sushi_4"}, IDs)
+}
+
+func Test_ListSushis_InvalidArguments(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{})
+
+	_, err := f.client.ListSushis(context.Background(), &sushipb.ListSushisRequest{PageSize: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = f.client.ListSushis(context.Background(), &sushipb.ListSushisRequest{PageToken: "not base64!"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_ListSushis_Prices(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{
+		"nigiri": {ID: "nigiri", Name: "Nigiri", Pricing: sushiapi.Pricing{
+			Price:  &sushiapi.Money{Amount: 450, Currency: "EUR"},
+			Prices: map[string]sushiapi.Money{"tokyo": {Amount: 600, Currency: "JPY"}},
+		}},
+		"uramaki": {ID: "uramaki", Name: "Uramaki", Pricing: sushiapi.Pricing{Price: &sushiapi.Money{Amount: 900, Currency: "EUR"}}},
+		"temaki":  {ID: "temaki", Name: "Temaki"},
+	})
+	ctx := context.Background()
+
+	IDs := func(req *sushipb.ListSushisRequest) []string {
+		res, err := f.client.ListSushis(ctx, req)
+		require.NoError(t, err)
+		var IDs []string
+		for _, s := range res.GetSushis() {
+			IDs = append(IDs, s.GetId())
+		}
+		return IDs
+	}
+	assert.Equal(t, []string{"nigiri", "temaki", "uramaki"}, IDs(&sushipb.ListSushisRequest{}))
+	assert.Equal(t, []string{"nigiri"}, IDs(&sushipb.ListSushisRequest{Currency: "EUR", PriceMax: proto.Int64(500)}))
+	assert.Equal(t, []string{"uramaki"}, IDs(&sushipb.ListSushisRequest{Restaurant: "tokyo", Currency: "EUR", PriceMin: proto.Int64(0)}))
+
+	_, err := f.client.ListSushis(ctx, &sushipb.ListSushisRequest{Currency: "EUR", PriceMin: proto.Int64(-1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = f.client.ListSushis(ctx, &sushipb.ListSushisRequest{PriceMax: proto.Int64(500)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	res, err := f.client.GetSushi(ctx, &sushipb.GetSushiRequest{Id: "nigiri"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(450), res.GetSushi().GetPrice().GetAmount())
+	assert.Equal(t, "JPY", res.GetSushi().GetPrices()["tokyo"].GetCurrency())
+}
+
+func Test_ListSushis_Labels(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{
+		"nigiri":  {ID: "nigiri", Name: "Nigiri", Ingredients: []string{"Rice", "Salmon"}},
+		"uramaki": {ID: "uramaki", Name: "Uramaki", Ingredients: []string{"Rice", "Crab", "Avocado"}},
+		"kappa":   {ID: "kappa", Name: "Kappa maki", Ingredients: []string{"Rice", "Nori", "Cucumber"}},
+		"gunkan":  {ID: "gunkan", Name: "Gunkan"},
+	})
+	ctx := context.Background()
+
+	IDs := func(req *sushipb.ListSushisRequest) []string {
+		res, err := f.client.ListSushis(ctx, req)
+		require.NoError(t, err)
+		var IDs []string
+		for _, s := range res.GetSushis() {
+			IDs = append(IDs, s.GetId())
+		}
+		return IDs
+	}
+	assert.Equal(t, []string{"kappa", "nigiri"}, IDs(&sushipb.ListSushisRequest{ExcludeAllergens: []string{"crustaceans"}}))
+	assert.Equal(t, []string{"kappa"}, IDs(&sushipb.ListSushisRequest{Diets: []string{"vegan"}}))
+
+	_, err := f.client.ListSushis(ctx, &sushipb.ListSushisRequest{Diets: []string{"pescatarian"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	res, err := f.client.GetSushi(ctx, &sushipb.GetSushiRequest{Id: "uramaki"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"crustaceans"}, res.GetSushi().GetLabels().GetAllergens())
+	assert.Equal(t, []string{"gluten-free"}, res.GetSushi().GetLabels().GetDiets())
+}
+
+func Test_AddModifyRemoveSushi(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{})
+	ctx := context.Background()
+
+	_, err := f.client.AddSushi(ctx, &sushipb.AddSushiRequest{Id: "temaki", Name: "Temaki"})
+	require.NoError(t, err)
+
+	_, err = f.client.AddSushi(ctx, &sushipb.AddSushiRequest{Id: "uramaki", Name: "Uramaki", Price: &sushipb.Money{Amount: -300, Currency: "EUR"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = f.client.ModifySushi(ctx, &sushipb.ModifySushiRequest{
+		Id:     "temaki",
+		Name:   "Temaki roll",
+		Price:  &sushipb.Money{Amount: 500, Currency: "EUR"},
+		Prices: map[string]*sushipb.Money{"tokyo": {Amount: 700, Currency: "JPY"}},
+	})
+	require.NoError(t, err)
+	res, err := f.client.GetSushi(ctx, &sushipb.GetSushiRequest{Id: "temaki"})
+	require.NoError(t, err)
+	assert.Equal(t, "Temaki roll", res.GetSushi().GetName())
+	assert.Equal(t, "EUR", res.GetSushi().GetPrice().GetCurrency())
+	assert.Equal(t, int64(700), res.GetSushi().GetPrices()["tokyo"].GetAmount())
+
+	_, err = f.client.ModifySushi(ctx, &sushipb.ModifySushiRequest{Id: "temaki", Name: "Temaki roll", Price: &sushipb.Money{Amount: -5, Currency: "EUR"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = f.client.RemoveSushi(ctx, &sushipb.RemoveSushiRequest{Id: "temaki"})
+	require.NoError(t, err)
+	_, err = f.client.GetSushi(ctx, &sushipb.GetSushiRequest{Id: "temaki"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func Test_ModifySushi_Locked(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{"nigiri": {ID: "nigiri", Name: "Nigiri"}})
+	ctx := context.Background()
+	lock, err := f.locker.Acquire(ctx, "nigiri", "chef", "", time.Minute)
+	require.NoError(t, err)
+
+	_, err = f.client.ModifySushi(ctx, &sushipb.ModifySushiRequest{Id: "nigiri", Name: "Nigiri"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = f.client.ModifySushi(ctx, &sushipb.ModifySushiRequest{Id: "nigiri", Name: "Nigiri", LockToken: lock.Token})
+	assert.NoError(t, err)
+}
+
+func Test_WatchSushis(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := f.client.WatchSushis(ctx, &sushipb.WatchSushisRequest{Ids: []string{"temaki"}})
+	require.NoError(t, err)
+	// the headers are sent once subscribed
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	_, err = f.client.AddSushi(ctx, &sushipb.AddSushiRequest{Id: "ignored", Name: "Ignored"})
+	require.NoError(t, err)
+	_, err = f.client.AddSushi(ctx, &sushipb.AddSushiRequest{Id: "temaki", Name: "Temaki"})
+	require.NoError(t, err)
+
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, sushipb.SushiEvent_TYPE_CREATED, res.GetEvent().GetType())
+	assert.Equal(t, "temaki", res.GetEvent().GetSushiId())
+	assert.Equal(t, "Temaki", res.GetEvent().GetSushi().GetName())
+}
+
+func Test_WatchSushis_Reset(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := f.client.WatchSushis(ctx, &sushipb.WatchSushisRequest{LastEventId: "forgotten"})
+	require.NoError(t, err)
+
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, sushipb.SushiEvent_TYPE_RESET, res.GetEvent().GetType())
+}
+
+func Test_WatchSushis_Resume(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{})
+	first := events.New(events.SushiCreated, "nigiri", &sushiapi.Sushi{ID: "nigiri"})
+	second := events.New(events.SushiRemoved, "nigiri", nil)
+	f.bus.Publish(context.Background(), first)
+	f.bus.Publish(context.Background(), second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := f.client.WatchSushis(ctx, &sushipb.WatchSushisRequest{LastEventId: first.ID})
+	require.NoError(t, err)
+
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, res.GetEvent().GetId())
+	assert.Equal(t, sushipb.SushiEvent_TYPE_REMOVED, res.GetEvent().GetType())
+}
+
+func Test_WatchSushis_BusClosed(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{})
+	f.bus.Close()
+
+	stream, err := f.client.WatchSushis(context.Background(), &sushipb.WatchSushisRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func Test_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	roots := writeCertificate(t, certFile, keyFile)
+	reloader, err := tlsconfig.NewReloader(certFile, keyFile, "")
+	require.NoError(t, err)
+
+	// the server shares the TLS configuration of the HTTP server
+	f := newFixture(t, map[string]sushiapi.Sushi{"nigiri": {ID: "nigiri", Name: "Nigiri"}},
+		grpc.Creds(credentials.NewTLS(reloader.Config(tls.NoClientCert))))
+	conn := f.dial(t, credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: "localhost"}))
+
+	res, err := sushipb.NewSushiServiceClient(conn).GetSushi(context.Background(), &sushipb.GetSushiRequest{Id: "nigiri"})
+	require.NoError(t, err)
+	assert.Equal(t, "Nigiri", res.GetSushi().GetName())
+}
+
+// writeCertificate writes a self-signed certificate of localhost, returning
+// the pool trusting it
+func writeCertificate(t *testing.T, certFile, keyFile string) *x509.CertPool {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return roots
+}
+
+func Test_Health(t *testing.T) {
+	f := newFixture(t, map[string]sushiapi.Sushi{})
+	req := &healthpb.HealthCheckRequest{Service: sushipb.SushiService_ServiceDesc.ServiceName}
+
+	res, err := f.health.Check(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+
+	f.server.Drain()
+	res, err = f.health.Check(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, res.GetStatus())
+}
@@ -0,0 +1,1270 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: sushi/v1/sushi.proto
+
+package sushipb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SushiEvent_Type int32
+
+const (
+	SushiEvent_TYPE_UNSPECIFIED SushiEvent_Type = 0
+	SushiEvent_TYPE_CREATED     SushiEvent_Type = 1
+	SushiEvent_TYPE_MODIFIED    SushiEvent_Type = 2
+	SushiEvent_TYPE_REMOVED     SushiEvent_Type = 3
+	// TYPE_RESET is sent when the stream couldn't be resumed, events may
+	// have been missed so the menu must be listed again
+	SushiEvent_TYPE_RESET SushiEvent_Type = 4
+)
+
+// Enum value maps for SushiEvent_Type.
+var (
+	SushiEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_CREATED",
+		2: "TYPE_MODIFIED",
+		3: "TYPE_REMOVED",
+		4: "TYPE_RESET",
+	}
+	SushiEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_CREATED":     1,
+		"TYPE_MODIFIED":    2,
+		"TYPE_REMOVED":     3,
+		"TYPE_RESET":       4,
+	}
+)
+
+func (x SushiEvent_Type) Enum() *SushiEvent_Type {
+	p := new(SushiEvent_Type)
+	*p = x
+	return p
+}
+
+func (x SushiEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SushiEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_sushi_v1_sushi_proto_enumTypes[0].Descriptor()
+}
+
+func (SushiEvent_Type) Type() protoreflect.EnumType {
+	return &file_sushi_v1_sushi_proto_enumTypes[0]
+}
+
+func (x SushiEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SushiEvent_Type.Descriptor instead.
+func (SushiEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{15, 0}
+}
+
+type Sushi struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ImageNumber string                 `protobuf:"bytes,2,opt,name=image_number,json=imageNumber,proto3" json:"image_number,omitempty"`
+	Name        string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Ingredients []string               `protobuf:"bytes,4,rep,name=ingredients,proto3" json:"ingredients,omitempty"`
+	CreateTime  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	UpdateTime  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	// price is the base price, unset when the sushi isn't priced
+	Price *Money `protobuf:"bytes,7,opt,name=price,proto3" json:"price,omitempty"`
+	// prices overrides the base price in the restaurants, by restaurant ID
+	Prices map[string]*Money `protobuf:"bytes,8,rep,name=prices,proto3" json:"prices,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// labels are computed from the ingredients
+	Labels        *Labels `protobuf:"bytes,9,opt,name=labels,proto3" json:"labels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Sushi) Reset() {
+	*x = Sushi{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Sushi) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sushi) ProtoMessage() {}
+
+func (x *Sushi) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sushi.ProtoReflect.Descriptor instead.
+func (*Sushi) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Sushi) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Sushi) GetImageNumber() string {
+	if x != nil {
+		return x.ImageNumber
+	}
+	return ""
+}
+
+func (x *Sushi) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Sushi) GetIngredients() []string {
+	if x != nil {
+		return x.Ingredients
+	}
+	return nil
+}
+
+func (x *Sushi) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Sushi) GetUpdateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+func (x *Sushi) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *Sushi) GetPrices() map[string]*Money {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+func (x *Sushi) GetLabels() *Labels {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+// Labels are the allergens and diets of a sushi, named like the REST API
+// does, gluten or gluten-free
+type Labels struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// allergens lists the EU allergens of the known ingredients
+	Allergens []string `protobuf:"bytes,1,rep,name=allergens,proto3" json:"allergens,omitempty"`
+	// diets lists the dietary labels the sushi satisfies
+	Diets []string `protobuf:"bytes,2,rep,name=diets,proto3" json:"diets,omitempty"`
+	// unknown_ingredients may contain more allergens than listed
+	UnknownIngredients []string `protobuf:"bytes,3,rep,name=unknown_ingredients,json=unknownIngredients,proto3" json:"unknown_ingredients,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Labels) Reset() {
+	*x = Labels{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Labels) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Labels) ProtoMessage() {}
+
+func (x *Labels) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Labels.ProtoReflect.Descriptor instead.
+func (*Labels) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Labels) GetAllergens() []string {
+	if x != nil {
+		return x.Allergens
+	}
+	return nil
+}
+
+func (x *Labels) GetDiets() []string {
+	if x != nil {
+		return x.Diets
+	}
+	return nil
+}
+
+func (x *Labels) GetUnknownIngredients() []string {
+	if x != nil {
+		return x.UnknownIngredients
+	}
+	return nil
+}
+
+// Money is an amount in the minor unit of its currency, cents of euro or yens
+type Money struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Amount int64                  `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	// currency is an ISO 4217 code
+	Currency      string `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Money) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Money) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type GetSushiRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSushiRequest) Reset() {
+	*x = GetSushiRequest{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSushiRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSushiRequest) ProtoMessage() {}
+
+func (x *GetSushiRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSushiRequest.ProtoReflect.Descriptor instead.
+func (*GetSushiRequest) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetSushiRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetSushiResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sushi         *Sushi                 `protobuf:"bytes,1,opt,name=sushi,proto3" json:"sushi,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSushiResponse) Reset() {
+	*x = GetSushiResponse{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSushiResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSushiResponse) ProtoMessage() {}
+
+func (x *GetSushiResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSushiResponse.ProtoReflect.Descriptor instead.
+func (*GetSushiResponse) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetSushiResponse) GetSushi() *Sushi {
+	if x != nil {
+		return x.Sushi
+	}
+	return nil
+}
+
+type ListSushisRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// page_size defaults to 50 and is capped at 1000
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// price_min and price_max bound the price in minor units of the currency
+	// they require, the sushis without a price are left out when either is set
+	PriceMin *int64 `protobuf:"varint,3,opt,name=price_min,json=priceMin,proto3,oneof" json:"price_min,omitempty"`
+	PriceMax *int64 `protobuf:"varint,4,opt,name=price_max,json=priceMax,proto3,oneof" json:"price_max,omitempty"`
+	// currency only keeps the sushis priced in this currency
+	Currency string `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	// restaurant picks the prices of the restaurant rather than the base prices
+	Restaurant string `protobuf:"bytes,6,opt,name=restaurant,proto3" json:"restaurant,omitempty"`
+	// exclude_allergens leaves out the sushis containing any of the allergens
+	// or ingredients nothing is known about, and those without ingredients
+	ExcludeAllergens []string `protobuf:"bytes,7,rep,name=exclude_allergens,json=excludeAllergens,proto3" json:"exclude_allergens,omitempty"`
+	// diets only keeps the sushis labelled with all the diets
+	Diets         []string `protobuf:"bytes,8,rep,name=diets,proto3" json:"diets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSushisRequest) Reset() {
+	*x = ListSushisRequest{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSushisRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSushisRequest) ProtoMessage() {}
+
+func (x *ListSushisRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSushisRequest.ProtoReflect.Descriptor instead.
+func (*ListSushisRequest) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListSushisRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSushisRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListSushisRequest) GetPriceMin() int64 {
+	if x != nil && x.PriceMin != nil {
+		return *x.PriceMin
+	}
+	return 0
+}
+
+func (x *ListSushisRequest) GetPriceMax() int64 {
+	if x != nil && x.PriceMax != nil {
+		return *x.PriceMax
+	}
+	return 0
+}
+
+func (x *ListSushisRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ListSushisRequest) GetRestaurant() string {
+	if x != nil {
+		return x.Restaurant
+	}
+	return ""
+}
+
+func (x *ListSushisRequest) GetExcludeAllergens() []string {
+	if x != nil {
+		return x.ExcludeAllergens
+	}
+	return nil
+}
+
+func (x *ListSushisRequest) GetDiets() []string {
+	if x != nil {
+		return x.Diets
+	}
+	return nil
+}
+
+type ListSushisResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Sushis []*Sushi               `protobuf:"bytes,1,rep,name=sushis,proto3" json:"sushis,omitempty"`
+	// next_page_token is empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSushisResponse) Reset() {
+	*x = ListSushisResponse{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSushisResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSushisResponse) ProtoMessage() {}
+
+func (x *ListSushisResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSushisResponse.ProtoReflect.Descriptor instead.
+func (*ListSushisResponse) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListSushisResponse) GetSushis() []*Sushi {
+	if x != nil {
+		return x.Sushis
+	}
+	return nil
+}
+
+func (x *ListSushisResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type AddSushiRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ImageNumber   string                 `protobuf:"bytes,2,opt,name=image_number,json=imageNumber,proto3" json:"image_number,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Ingredients   []string               `protobuf:"bytes,4,rep,name=ingredients,proto3" json:"ingredients,omitempty"`
+	Price         *Money                 `protobuf:"bytes,5,opt,name=price,proto3" json:"price,omitempty"`
+	Prices        map[string]*Money      `protobuf:"bytes,6,rep,name=prices,proto3" json:"prices,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddSushiRequest) Reset() {
+	*x = AddSushiRequest{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSushiRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSushiRequest) ProtoMessage() {}
+
+func (x *AddSushiRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSushiRequest.ProtoReflect.Descriptor instead.
+func (*AddSushiRequest) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AddSushiRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AddSushiRequest) GetImageNumber() string {
+	if x != nil {
+		return x.ImageNumber
+	}
+	return ""
+}
+
+func (x *AddSushiRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AddSushiRequest) GetIngredients() []string {
+	if x != nil {
+		return x.Ingredients
+	}
+	return nil
+}
+
+func (x *AddSushiRequest) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *AddSushiRequest) GetPrices() map[string]*Money {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+type AddSushiResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddSushiResponse) Reset() {
+	*x = AddSushiResponse{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSushiResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSushiResponse) ProtoMessage() {}
+
+func (x *AddSushiResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSushiResponse.ProtoReflect.Descriptor instead.
+func (*AddSushiResponse) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{8}
+}
+
+type ModifySushiRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ImageNumber string                 `protobuf:"bytes,2,opt,name=image_number,json=imageNumber,proto3" json:"image_number,omitempty"`
+	Name        string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Ingredients []string               `protobuf:"bytes,4,rep,name=ingredients,proto3" json:"ingredients,omitempty"`
+	// lock_token is the token of the edit lock held on the sushi, if any
+	LockToken     string            `protobuf:"bytes,5,opt,name=lock_token,json=lockToken,proto3" json:"lock_token,omitempty"`
+	Price         *Money            `protobuf:"bytes,6,opt,name=price,proto3" json:"price,omitempty"`
+	Prices        map[string]*Money `protobuf:"bytes,7,rep,name=prices,proto3" json:"prices,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModifySushiRequest) Reset() {
+	*x = ModifySushiRequest{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModifySushiRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModifySushiRequest) ProtoMessage() {}
+
+func (x *ModifySushiRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModifySushiRequest.ProtoReflect.Descriptor instead.
+func (*ModifySushiRequest) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ModifySushiRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ModifySushiRequest) GetImageNumber() string {
+	if x != nil {
+		return x.ImageNumber
+	}
+	return ""
+}
+
+func (x *ModifySushiRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ModifySushiRequest) GetIngredients() []string {
+	if x != nil {
+		return x.Ingredients
+	}
+	return nil
+}
+
+func (x *ModifySushiRequest) GetLockToken() string {
+	if x != nil {
+		return x.LockToken
+	}
+	return ""
+}
+
+func (x *ModifySushiRequest) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *ModifySushiRequest) GetPrices() map[string]*Money {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+type ModifySushiResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModifySushiResponse) Reset() {
+	*x = ModifySushiResponse{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModifySushiResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModifySushiResponse) ProtoMessage() {}
+
+func (x *ModifySushiResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModifySushiResponse.ProtoReflect.Descriptor instead.
+func (*ModifySushiResponse) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{10}
+}
+
+type RemoveSushiRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveSushiRequest) Reset() {
+	*x = RemoveSushiRequest{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveSushiRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveSushiRequest) ProtoMessage() {}
+
+func (x *RemoveSushiRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveSushiRequest.ProtoReflect.Descriptor instead.
+func (*RemoveSushiRequest) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RemoveSushiRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RemoveSushiResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveSushiResponse) Reset() {
+	*x = RemoveSushiResponse{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveSushiResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveSushiResponse) ProtoMessage() {}
+
+func (x *RemoveSushiResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveSushiResponse.ProtoReflect.Descriptor instead.
+func (*RemoveSushiResponse) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{12}
+}
+
+type WatchSushisRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ids restricts the stream to the given sushis, every sushi when empty
+	Ids []string `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	// last_event_id resumes the stream after the given event
+	LastEventId   string `protobuf:"bytes,2,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchSushisRequest) Reset() {
+	*x = WatchSushisRequest{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchSushisRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSushisRequest) ProtoMessage() {}
+
+func (x *WatchSushisRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSushisRequest.ProtoReflect.Descriptor instead.
+func (*WatchSushisRequest) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WatchSushisRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *WatchSushisRequest) GetLastEventId() string {
+	if x != nil {
+		return x.LastEventId
+	}
+	return ""
+}
+
+type WatchSushisResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *SushiEvent            `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchSushisResponse) Reset() {
+	*x = WatchSushisResponse{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchSushisResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSushisResponse) ProtoMessage() {}
+
+func (x *WatchSushisResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSushisResponse.ProtoReflect.Descriptor instead.
+func (*WatchSushisResponse) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *WatchSushisResponse) GetEvent() *SushiEvent {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type SushiEvent struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type    SushiEvent_Type        `protobuf:"varint,2,opt,name=type,proto3,enum=sushi.v1.SushiEvent_Type" json:"type,omitempty"`
+	Time    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	SushiId string                 `protobuf:"bytes,4,opt,name=sushi_id,json=sushiId,proto3" json:"sushi_id,omitempty"`
+	// sushi is the new data of a created or modified sushi
+	Sushi         *Sushi `protobuf:"bytes,5,opt,name=sushi,proto3" json:"sushi,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SushiEvent) Reset() {
+	*x = SushiEvent{}
+	mi := &file_sushi_v1_sushi_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SushiEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SushiEvent) ProtoMessage() {}
+
+func (x *SushiEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_sushi_v1_sushi_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SushiEvent.ProtoReflect.Descriptor instead.
+func (*SushiEvent) Descriptor() ([]byte, []int) {
+	return file_sushi_v1_sushi_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SushiEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SushiEvent) GetType() SushiEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return SushiEvent_TYPE_UNSPECIFIED
+}
+
+func (x *SushiEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *SushiEvent) GetSushiId() string {
+	if x != nil {
+		return x.SushiId
+	}
+	return ""
+}
+
+func (x *SushiEvent) GetSushi() *Sushi {
+	if x != nil {
+		return x.Sushi
+	}
+	return nil
+}
+
+var File_sushi_v1_sushi_proto protoreflect.FileDescriptor
+
+const file_sushi_v1_sushi_proto_rawDesc = "" +
+	"\n" +
+	"\x14sushi/v1/sushi.proto\x12\bsushi.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xbc\x03\n" +
+	"\x05Sushi\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fimage_number\x18\x02 \x01(\tR\vimageNumber\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12 \n" +
+	"\vingredients\x18\x04 \x03(\tR\vingredients\x12;\n" +
+	"\vcreate_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12;\n" +
+	"\vupdate_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"updateTime\x12%\n" +
+	"\x05price\x18\a \x01(\v2\x0f.sushi.v1.MoneyR\x05price\x123\n" +
+	"\x06prices\x18\b \x03(\v2\x1b.sushi.v1.Sushi.PricesEntryR\x06prices\x12(\n" +
+	"\x06labels\x18\t \x01(\v2\x10.sushi.v1.LabelsR\x06labels\x1aJ\n" +
+	"\vPricesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12%\n" +
+	"\x05value\x18\x02 \x01(\v2\x0f.sushi.v1.MoneyR\x05value:\x028\x01\"m\n" +
+	"\x06Labels\x12\x1c\n" +
+	"\tallergens\x18\x01 \x03(\tR\tallergens\x12\x14\n" +
+	"\x05diets\x18\x02 \x03(\tR\x05diets\x12/\n" +
+	"\x13unknown_ingredients\x18\x03 \x03(\tR\x12unknownIngredients\";\n" +
+	"\x05Money\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"!\n" +
+	"\x0fGetSushiRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"9\n" +
+	"\x10GetSushiResponse\x12%\n" +
+	"\x05sushi\x18\x01 \x01(\v2\x0f.sushi.v1.SushiR\x05sushi\"\xae\x02\n" +
+	"\x11ListSushisRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12 \n" +
+	"\tprice_min\x18\x03 \x01(\x03H\x00R\bpriceMin\x88\x01\x01\x12 \n" +
+	"\tprice_max\x18\x04 \x01(\x03H\x01R\bpriceMax\x88\x01\x01\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12\x1e\n" +
+	"\n" +
+	"restaurant\x18\x06 \x01(\tR\n" +
+	"restaurant\x12+\n" +
+	"\x11exclude_allergens\x18\a \x03(\tR\x10excludeAllergens\x12\x14\n" +
+	"\x05diets\x18\b \x03(\tR\x05dietsB\f\n" +
+	"\n" +
+	"_price_minB\f\n" +
+	"\n" +
+	"_price_max\"e\n" +
+	"\x12ListSushisResponse\x12'\n" +
+	"\x06sushis\x18\x01 \x03(\v2\x0f.sushi.v1.SushiR\x06sushis\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xac\x02\n" +
+	"\x0fAddSushiRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fimage_number\x18\x02 \x01(\tR\vimageNumber\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12 \n" +
+	"\vingredients\x18\x04 \x03(\tR\vingredients\x12%\n" +
+	"\x05price\x18\x05 \x01(\v2\x0f.sushi.v1.MoneyR\x05price\x12=\n" +
+	"\x06prices\x18\x06 \x03(\v2%.sushi.v1.AddSushiRequest.PricesEntryR\x06prices\x1aJ\n" +
+	"\vPricesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12%\n" +
+	"\x05value\x18\x02 \x01(\v2\x0f.sushi.v1.MoneyR\x05value:\x028\x01\"\x12\n" +
+	"\x10AddSushiResponse\"\xd1\x02\n" +
+	"\x12ModifySushiRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fimage_number\x18\x02 \x01(\tR\vimageNumber\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12 \n" +
+	"\vingredients\x18\x04 \x03(\tR\vingredients\x12\x1d\n" +
+	"\n" +
+	"lock_token\x18\x05 \x01(\tR\tlockToken\x12%\n" +
+	"\x05price\x18\x06 \x01(\v2\x0f.sushi.v1.MoneyR\x05price\x12@\n" +
+	"\x06prices\x18\a \x03(\v2(.sushi.v1.ModifySushiRequest.PricesEntryR\x06prices\x1aJ\n" +
+	"\vPricesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12%\n" +
+	"\x05value\x18\x02 \x01(\v2\x0f.sushi.v1.MoneyR\x05value:\x028\x01\"\x15\n" +
+	"\x13ModifySushiResponse\"$\n" +
+	"\x12RemoveSushiRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x15\n" +
+	"\x13RemoveSushiResponse\"J\n" +
+	"\x12WatchSushisRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\x12\"\n" +
+	"\rlast_event_id\x18\x02 \x01(\tR\vlastEventId\"A\n" +
+	"\x13WatchSushisResponse\x12*\n" +
+	"\x05event\x18\x01 \x01(\v2\x14.sushi.v1.SushiEventR\x05event\"\xa2\x02\n" +
+	"\n" +
+	"SushiEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12-\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x19.sushi.v1.SushiEvent.TypeR\x04type\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x19\n" +
+	"\bsushi_id\x18\x04 \x01(\tR\asushiId\x12%\n" +
+	"\x05sushi\x18\x05 \x01(\v2\x0f.sushi.v1.SushiR\x05sushi\"c\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fTYPE_CREATED\x10\x01\x12\x11\n" +
+	"\rTYPE_MODIFIED\x10\x02\x12\x10\n" +
+	"\fTYPE_REMOVED\x10\x03\x12\x0e\n" +
+	"\n" +
+	"TYPE_RESET\x10\x042\xc3\x03\n" +
+	"\fSushiService\x12A\n" +
+	"\bGetSushi\x12\x19.sushi.v1.GetSushiRequest\x1a\x1a.sushi.v1.GetSushiResponse\x12G\n" +
+	"\n" +
+	"ListSushis\x12\x1b.sushi.v1.ListSushisRequest\x1a\x1c.sushi.v1.ListSushisResponse\x12A\n" +
+	"\bAddSushi\x12\x19.sushi.v1.AddSushiRequest\x1a\x1a.sushi.v1.AddSushiResponse\x12J\n" +
+	"\vModifySushi\x12\x1c.sushi.v1.ModifySushiRequest\x1a\x1d.sushi.v1.ModifySushiResponse\x12J\n" +
+	"\vRemoveSushi\x12\x1c.sushi.v1.RemoveSushiRequest\x1a\x1d.sushi.v1.RemoveSushiResponse\x12L\n" +
+	"\vWatchSushis\x12\x1c.sushi.v1.WatchSushisRequest\x1a\x1d.sushi.v1.WatchSushisResponse0\x01B<Z:github.com/sergiorra/sushi-api-go/pkg/rpc/sushi/v1;sushipbb\x06proto3"
+
+var (
+	file_sushi_v1_sushi_proto_rawDescOnce sync.Once
+	file_sushi_v1_sushi_proto_rawDescData []byte
+)
+
+func file_sushi_v1_sushi_proto_rawDescGZIP() []byte {
+	file_sushi_v1_sushi_proto_rawDescOnce.Do(func() {
+		file_sushi_v1_sushi_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sushi_v1_sushi_proto_rawDesc), len(file_sushi_v1_sushi_proto_rawDesc)))
+	})
+	return file_sushi_v1_sushi_proto_rawDescData
+}
+
+var file_sushi_v1_sushi_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sushi_v1_sushi_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_sushi_v1_sushi_proto_goTypes = []any{
+	(SushiEvent_Type)(0),          // 0: sushi.v1.SushiEvent.Type
+	(*Sushi)(nil),                 // 1: sushi.v1.Sushi
+	(*Labels)(nil),                // 2: sushi.v1.Labels
+	(*Money)(nil),                 // 3: sushi.v1.Money
+	(*GetSushiRequest)(nil),       // 4: sushi.v1.GetSushiRequest
+	(*GetSushiResponse)(nil),      // 5: sushi.v1.GetSushiResponse
+	(*ListSushisRequest)(nil),     // 6: sushi.v1.ListSushisRequest
+	(*ListSushisResponse)(nil),    // 7: sushi.v1.ListSushisResponse
+	(*AddSushiRequest)(nil),       // 8: sushi.v1.AddSushiRequest
+	(*AddSushiResponse)(nil),      // 9: sushi.v1.AddSushiResponse
+	(*ModifySushiRequest)(nil),    // 10: sushi.v1.ModifySushiRequest
+	(*ModifySushiResponse)(nil),   // 11: sushi.v1.ModifySushiResponse
+	(*RemoveSushiRequest)(nil),    // 12: sushi.v1.RemoveSushiRequest
+	(*RemoveSushiResponse)(nil),   // 13: sushi.v1.RemoveSushiResponse
+	(*WatchSushisRequest)(nil),    // 14: sushi.v1.WatchSushisRequest
+	(*WatchSushisResponse)(nil),   // 15: sushi.v1.WatchSushisResponse
+	(*SushiEvent)(nil),            // 16: sushi.v1.SushiEvent
+	nil,                           // 17: sushi.v1.Sushi.PricesEntry
+	nil,                           // 18: sushi.v1.AddSushiRequest.PricesEntry
+	nil,                           // 19: sushi.v1.ModifySushiRequest.PricesEntry
+	(*timestamppb.Timestamp)(nil), // 20: google.protobuf.Timestamp
+}
+var file_sushi_v1_sushi_proto_depIdxs = []int32{
+	20, // 0: sushi.v1.Sushi.create_time:type_name -> google.protobuf.Timestamp
+	20, // 1: sushi.v1.Sushi.update_time:type_name -> google.protobuf.Timestamp
+	3,  // 2: sushi.v1.Sushi.price:type_name -> sushi.v1.Money
+	17, // 3: sushi.v1.Sushi.prices:type_name -> sushi.v1.Sushi.PricesEntry
+	2,  // 4: sushi.v1.Sushi.labels:type_name -> sushi.v1.Labels
+	1,  // 5: sushi.v1.GetSushiResponse.sushi:type_name -> sushi.v1.Sushi
+	1,  // 6: sushi.v1.ListSushisResponse.sushis:type_name -> sushi.v1.Sushi
+	3,  // 7: sushi.v1.AddSushiRequest.price:type_name -> sushi.v1.Money
+	18, // 8: sushi.v1.AddSushiRequest.prices:type_name -> sushi.v1.AddSushiRequest.PricesEntry
+	3,  // 9: sushi.v1.ModifySushiRequest.price:type_name -> sushi.v1.Money
+	19, // 10: sushi.v1.ModifySushiRequest.prices:type_name -> sushi.v1.ModifySushiRequest.PricesEntry
+	16, // 11: sushi.v1.WatchSushisResponse.event:type_name -> sushi.v1.SushiEvent
+	0,  // 12: sushi.v1.SushiEvent.type:type_name -> sushi.v1.SushiEvent.Type
+	20, // 13: sushi.v1.SushiEvent.time:type_name -> google.protobuf.Timestamp
+	1,  // 14: sushi.v1.SushiEvent.sushi:type_name -> sushi.v1.Sushi
+	3,  // 15: sushi.v1.Sushi.PricesEntry.value:type_name -> sushi.v1.Money
+	3,  // 16: sushi.v1.AddSushiRequest.PricesEntry.value:type_name -> sushi.v1.Money
+	3,  // 17: sushi.v1.ModifySushiRequest.PricesEntry.value:type_name -> sushi.v1.Money
+	4,  // 18: sushi.v1.SushiService.GetSushi:input_type -> sushi.v1.GetSushiRequest
+	6,  // 19: sushi.v1.SushiService.ListSushis:input_type -> sushi.v1.ListSushisRequest
+	8,  // 20: sushi.v1.SushiService.AddSushi:input_type -> sushi.v1.AddSushiRequest
+	10, // 21: sushi.v1.SushiService.ModifySushi:input_type -> sushi.v1.ModifySushiRequest
+	12, // 22: sushi.v1.SushiService.RemoveSushi:input_type -> sushi.v1.RemoveSushiRequest
+	14, // 23: sushi.v1.SushiService.WatchSushis:input_type -> sushi.v1.WatchSushisRequest
+	5,  // 24: sushi.v1.SushiService.GetSushi:output_type -> sushi.v1.GetSushiResponse
+	7,  // 25: sushi.v1.SushiService.ListSushis:output_type -> sushi.v1.ListSushisResponse
+	9,  // 26: sushi.v1.SushiService.AddSushi:output_type -> sushi.v1.AddSushiResponse
+	11, // 27: sushi.v1.SushiService.ModifySushi:output_type -> sushi.v1.ModifySushiResponse
+	13, // 28: sushi.v1.SushiService.RemoveSushi:output_type -> sushi.v1.RemoveSushiResponse
+	15, // 29: sushi.v1.SushiService.WatchSushis:output_type -> sushi.v1.WatchSushisResponse
+	24, // [24:30] is the sub-list for method output_type
+	18, // [18:24] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_sushi_v1_sushi_proto_init() }
+func file_sushi_v1_sushi_proto_init() {
+	if File_sushi_v1_sushi_proto != nil {
+		return
+	}
+	file_sushi_v1_sushi_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sushi_v1_sushi_proto_rawDesc), len(file_sushi_v1_sushi_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sushi_v1_sushi_proto_goTypes,
+		DependencyIndexes: file_sushi_v1_sushi_proto_depIdxs,
+		EnumInfos:         file_sushi_v1_sushi_proto_enumTypes,
+		MessageInfos:      file_sushi_v1_sushi_proto_msgTypes,
+	}.Build()
+	File_sushi_v1_sushi_proto = out.File
+	file_sushi_v1_sushi_proto_goTypes = nil
+	file_sushi_v1_sushi_proto_depIdxs = nil
+}
@@ -0,0 +1,333 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: sushi/v1/sushi.proto
+
+package sushipb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SushiService_GetSushi_FullMethodName    = "/sushi.v1.SushiService/GetSushi"
+	SushiService_ListSushis_FullMethodName  = "/sushi.v1.SushiService/ListSushis"
+	SushiService_AddSushi_FullMethodName    = "/sushi.v1.SushiService/AddSushi"
+	SushiService_ModifySushi_FullMethodName = "/sushi.v1.SushiService/ModifySushi"
+	SushiService_RemoveSushi_FullMethodName = "/sushi.v1.SushiService/RemoveSushi"
+	SushiService_WatchSushis_FullMethodName = "/sushi.v1.SushiService/WatchSushis"
+)
+
+// SushiServiceClient is the client API for SushiService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SushiService manages the sushis of the menu, like the /sushi REST API
+type SushiServiceClient interface {
+	// GetSushi returns a sushi, NOT_FOUND when it doesn't exist
+	GetSushi(ctx context.Context, in *GetSushiRequest, opts ...grpc.CallOption) (*GetSushiResponse, error)
+	// ListSushis returns a page of the sushis, ordered by ID
+	ListSushis(ctx context.Context, in *ListSushisRequest, opts ...grpc.CallOption) (*ListSushisResponse, error)
+	// AddSushi adds a sushi to the menu
+	AddSushi(ctx context.Context, in *AddSushiRequest, opts ...grpc.CallOption) (*AddSushiResponse, error)
+	// ModifySushi replaces the data of a sushi, FAILED_PRECONDITION when
+	// somebody else holds its edit lock
+	ModifySushi(ctx context.Context, in *ModifySushiRequest, opts ...grpc.CallOption) (*ModifySushiResponse, error)
+	// RemoveSushi removes a sushi from the menu
+	RemoveSushi(ctx context.Context, in *RemoveSushiRequest, opts ...grpc.CallOption) (*RemoveSushiResponse, error)
+	// WatchSushis streams the changes of the menu as they happen
+	WatchSushis(ctx context.Context, in *WatchSushisRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchSushisResponse], error)
+}
+
+type sushiServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSushiServiceClient(cc grpc.ClientConnInterface) SushiServiceClient {
+	return &sushiServiceClient{cc}
+}
+
+func (c *sushiServiceClient) GetSushi(ctx context.Context, in *GetSushiRequest, opts ...grpc.CallOption) (*GetSushiResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSushiResponse)
+	err := c.cc.Invoke(ctx, SushiService_GetSushi_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sushiServiceClient) ListSushis(ctx context.Context, in *ListSushisRequest, opts ...grpc.CallOption) (*ListSushisResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSushisResponse)
+	err := c.cc.Invoke(ctx, SushiService_ListSushis_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sushiServiceClient) AddSushi(ctx context.Context, in *AddSushiRequest, opts ...grpc.CallOption) (*AddSushiResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddSushiResponse)
+	err := c.cc.Invoke(ctx, SushiService_AddSushi_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sushiServiceClient) ModifySushi(ctx context.Context, in *ModifySushiRequest, opts ...grpc.CallOption) (*ModifySushiResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ModifySushiResponse)
+	err := c.cc.Invoke(ctx, SushiService_ModifySushi_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sushiServiceClient) RemoveSushi(ctx context.Context, in *RemoveSushiRequest, opts ...grpc.CallOption) (*RemoveSushiResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveSushiResponse)
+	err := c.cc.Invoke(ctx, SushiService_RemoveSushi_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sushiServiceClient) WatchSushis(ctx context.Context, in *WatchSushisRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchSushisResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SushiService_ServiceDesc.Streams[0], SushiService_WatchSushis_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchSushisRequest, WatchSushisResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SushiService_WatchSushisClient = grpc.ServerStreamingClient[WatchSushisResponse]
+
+// SushiServiceServer is the server API for SushiService service.
+// All implementations must embed UnimplementedSushiServiceServer
+// for forward compatibility.
+//
+// SushiService manages the sushis of the menu, like the /sushi REST API
+type SushiServiceServer interface {
+	// GetSushi returns a sushi, NOT_FOUND when it doesn't exist
+	GetSushi(context.Context, *GetSushiRequest) (*GetSushiResponse, error)
+	// ListSushis returns a page of the sushis, ordered by ID
+	ListSushis(context.Context, *ListSushisRequest) (*ListSushisResponse, error)
+	// AddSushi adds a sushi to the menu
+	AddSushi(context.Context, *AddSushiRequest) (*AddSushiResponse, error)
+	// ModifySushi replaces the data of a sushi, FAILED_PRECONDITION when
+	// somebody else holds its edit lock
+	ModifySushi(context.Context, *ModifySushiRequest) (*ModifySushiResponse, error)
+	// RemoveSushi removes a sushi from the menu
+	RemoveSushi(context.Context, *RemoveSushiRequest) (*RemoveSushiResponse, error)
+	// WatchSushis streams the changes of the menu as they happen
+	WatchSushis(*WatchSushisRequest, grpc.ServerStreamingServer[WatchSushisResponse]) error
+	mustEmbedUnimplementedSushiServiceServer()
+}
+
+// UnimplementedSushiServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSushiServiceServer struct{}
+
+func (UnimplementedSushiServiceServer) GetSushi(context.Context, *GetSushiRequest) (*GetSushiResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSushi not implemented")
+}
+func (UnimplementedSushiServiceServer) ListSushis(context.Context, *ListSushisRequest) (*ListSushisResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSushis not implemented")
+}
+func (UnimplementedSushiServiceServer) AddSushi(context.Context, *AddSushiRequest) (*AddSushiResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddSushi not implemented")
+}
+func (UnimplementedSushiServiceServer) ModifySushi(context.Context, *ModifySushiRequest) (*ModifySushiResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ModifySushi not implemented")
+}
+func (UnimplementedSushiServiceServer) RemoveSushi(context.Context, *RemoveSushiRequest) (*RemoveSushiResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveSushi not implemented")
+}
+func (UnimplementedSushiServiceServer) WatchSushis(*WatchSushisRequest, grpc.ServerStreamingServer[WatchSushisResponse]) error {
+	return status.Error(codes.Unimplemented, "method WatchSushis not implemented")
+}
+func (UnimplementedSushiServiceServer) mustEmbedUnimplementedSushiServiceServer() {}
+func (UnimplementedSushiServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeSushiServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SushiServiceServer will
+// result in compilation errors.
+type UnsafeSushiServiceServer interface {
+	mustEmbedUnimplementedSushiServiceServer()
+}
+
+func RegisterSushiServiceServer(s grpc.ServiceRegistrar, srv SushiServiceServer) {
+	// If the following call panics, it indicates UnimplementedSushiServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SushiService_ServiceDesc, srv)
+}
+
+func _SushiService_GetSushi_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSushiRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SushiServiceServer).GetSushi(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SushiService_GetSushi_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SushiServiceServer).GetSushi(ctx, req.(*GetSushiRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SushiService_ListSushis_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSushisRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SushiServiceServer).ListSushis(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SushiService_ListSushis_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SushiServiceServer).ListSushis(ctx, req.(*ListSushisRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SushiService_AddSushi_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSushiRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SushiServiceServer).AddSushi(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SushiService_AddSushi_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SushiServiceServer).AddSushi(ctx, req.(*AddSushiRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SushiService_ModifySushi_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModifySushiRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SushiServiceServer).ModifySushi(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SushiService_ModifySushi_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SushiServiceServer).ModifySushi(ctx, req.(*ModifySushiRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SushiService_RemoveSushi_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveSushiRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SushiServiceServer).RemoveSushi(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SushiService_RemoveSushi_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SushiServiceServer).RemoveSushi(ctx, req.(*RemoveSushiRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SushiService_WatchSushis_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSushisRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SushiServiceServer).WatchSushis(m, &grpc.GenericServerStream[WatchSushisRequest, WatchSushisResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SushiService_WatchSushisServer = grpc.ServerStreamingServer[WatchSushisResponse]
+
+// SushiService_ServiceDesc is the grpc.ServiceDesc for SushiService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SushiService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sushi.v1.SushiService",
+	HandlerType: (*SushiServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSushi",
+			Handler:    _SushiService_GetSushi_Handler,
+		},
+		{
+			MethodName: "ListSushis",
+			Handler:    _SushiService_ListSushis_Handler,
+		},
+		{
+			MethodName: "AddSushi",
+			Handler:    _SushiService_AddSushi_Handler,
+		},
+		{
+			MethodName: "ModifySushi",
+			Handler:    _SushiService_ModifySushi_Handler,
+		},
+		{
+			MethodName: "RemoveSushi",
+			Handler:    _SushiService_RemoveSushi_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchSushis",
+			Handler:       _SushiService_WatchSushis_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sushi/v1/sushi.proto",
+}
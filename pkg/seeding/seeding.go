@@ -0,0 +1,164 @@
+// Package seeding loads fixture sushis into a repository, whatever the
+// backend
+package seeding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+// Result counts what seeding did to the repository
+type Result struct {
+	// Removed is the number of sushis removed by the reset
+	Removed int
+	// Created is the number of fixtures missing from the repository
+	Created int
+	// Skipped is the number of fixtures the repository had already
+	Skipped int
+}
+
+// Seed creates the fixtures missing from the repository, so seeding again is
+// harmless: the sushis already there are kept as they are, even when they
+// were modified since. With reset every sushi is removed first.
+//
+// The fixtures are all validated before the repository is touched, their
+// ingredients must be in the catalogue unless it's nil.
+func Seed(ctx context.Context, repository sushi.Repository, catalogue sushi.IngredientRepository, fixtures []sushi.Sushi, reset bool) (Result, error) {
+	var result Result
+	seen := make(map[string]bool, len(fixtures))
+	var errs []error
+	for i := range fixtures {
+		if err := fixtures[i].Validate(); err != nil {
+			errs = append(errs, err)
+		}
+		if catalogue != nil {
+			err := sushi.CheckIngredients(ctx, catalogue, fixtures[i].Ingredients)
+			if err != nil && !errors.Is(err, sushi.ErrInvalidSushi) {
+				return result, fmt.Errorf("can't check the ingredients: %w", err)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("the sushi %q: %w", fixtures[i].ID, err))
+			}
+		}
+		if seen[fixtures[i].ID] {
+			errs = append(errs, fmt.Errorf("the sushi %q is defined twice", fixtures[i].ID))
+		}
+		seen[fixtures[i].ID] = true
+	}
+	if len(errs) > 0 {
+		return result, errors.Join(errs...)
+	}
+
+	existing := make(map[string]bool)
+	for s, err := range sushi.Stream(ctx, repository) {
+		if err != nil {
+			return result, fmt.Errorf("can't list the sushis: %w", err)
+		}
+		existing[s.ID] = true
+	}
+
+	if reset {
+		for _, ID := range sortedIDs(existing) {
+			if err := repository.DeleteSushi(ctx, ID); err != nil {
+				return result, fmt.Errorf("can't remove the sushi %s: %w", ID, err)
+			}
+			delete(existing, ID)
+			result.Removed++
+		}
+	}
+
+	now := time.Now()
+	for _, fixture := range fixtures {
+		if existing[fixture.ID] {
+			result.Skipped++
+			continue
+		}
+		s := fixture
+		s.CreatedAt = &now
+		s.UpdatedAt = nil
+		if err := repository.CreateSushi(ctx, &s); err != nil {
+			return result, fmt.Errorf("can't create the sushi %s: %w", s.ID, err)
+		}
+		result.Created++
+	}
+	return result, nil
+}
+
+// SeedIngredients fills an empty catalogue with the ingredients, so a new
+// database starts with the usual ones. A catalogue with any ingredient is
+// left as it is, the removed ingredients aren't brought back. It returns the
+// number of ingredients created.
+func SeedIngredients(ctx context.Context, catalogue sushi.IngredientRepository, ingredients []sushi.Ingredient) (int, error) {
+	existing, err := catalogue.GetIngredients(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("can't list the ingredients: %w", err)
+	}
+	if len(existing) > 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	for n, ingredient := range ingredients {
+		i := ingredient
+		i.CreatedAt = &now
+		if err := catalogue.CreateIngredient(ctx, &i); err != nil {
+			return n, fmt.Errorf("can't create the ingredient %s: %w", i.ID, err)
+		}
+	}
+	return len(ingredients), nil
+}
+
+// FromMap returns the sushis of the map, like the sample menu, ordered by ID
+func FromMap(sushis map[string]sushi.Sushi) []sushi.Sushi {
+	fixtures := make([]sushi.Sushi, 0, len(sushis))
+	for _, s := range sushis {
+		fixtures = append(fixtures, s)
+	}
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].ID < fixtures[j].ID })
+	return fixtures
+}
+
+// LoadFile reads the fixtures of a JSON or YAML file holding a list of
+// sushis, their fields named as in the API
+func LoadFile(path string) ([]sushi.Sushi, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is YAML too, the document goes through JSON to get the names of
+	// the API
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s: %w", path, err)
+	}
+	if data, err = json.Marshal(document); err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s: %w", path, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var fixtures []sushi.Sushi
+	if err := decoder.Decode(&fixtures); err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s, it must list sushis: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+func sortedIDs(IDs map[string]bool) []string {
+	sorted := make([]string, 0, len(IDs))
+	for ID := range IDs {
+		sorted = append(sorted, ID)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,156 @@
+package seeding
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/storage/inmem"
+)
+
+var fixtures = []sushi.Sushi{
+	{ID: "nigiri", ImageNumber: "1", Name: "Salmon nigiri", Ingredients: []string{"Rice", "Salmon"}},
+	{ID: "uramaki", ImageNumber: "2", Name: "California uramaki", Ingredients: []string{"Rice", "Crab"}},
+}
+
+func Test_Seed_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewRepository(map[string]sushi.Sushi{
+		"uramaki": {ID: "uramaki", Name: "Uramaki of the chef"},
+	})
+
+	result, err := Seed(ctx, repo, nil, fixtures, false)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Created: 1, Skipped: 1}, result)
+
+	result, err = Seed(ctx, repo, nil, fixtures, false)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Skipped: 2}, result)
+
+	nigiri, err := repo.GetSushiByID(ctx, "nigiri")
+	require.NoError(t, err)
+	assert.Equal(t, "Salmon nigiri", nigiri.Name)
+	assert.NotNil(t, nigiri.CreatedAt)
+	// the sushis already there are kept as they are
+	uramaki, err := repo.GetSushiByID(ctx, "uramaki")
+	require.NoError(t, err)
+	assert.Equal(t, "Uramaki of the chef", uramaki.Name)
+}
+
+func Test_Seed_Reset(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewRepository(map[string]sushi.Sushi{
+		"uramaki": {ID: "uramaki", Name: "Uramaki of the chef"},
+		"temaki":  {ID: "temaki", Name: "Tuna temaki"},
+	})
+
+	result, err := Seed(ctx, repo, nil, fixtures, true)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Removed: 2, Created: 2}, result)
+
+	sushis, err := repo.GetSushis(ctx)
+	require.NoError(t, err)
+	assert.Len(t, sushis, 2)
+	uramaki, err := repo.GetSushiByID(ctx, "uramaki")
+	require.NoError(t, err)
+	assert.Equal(t, "California uramaki", uramaki.Name)
+}
+
+func Test_Seed_Invalid(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewRepository(map[string]sushi.Sushi{"temaki": {ID: "temaki", Name: "Tuna temaki"}})
+
+	_, err := Seed(ctx, repo, nil, append(fixtures, sushi.Sushi{ID: "hosomaki", Name: "Hosomaki", Pricing: sushi.Pricing{Price: &sushi.Money{Amount: 300, Currency: "euro"}}}, fixtures[0]), true)
+	assert.ErrorIs(t, err, sushi.ErrInvalidSushi)
+	assert.ErrorContains(t, err, `the sushi "nigiri" is defined twice`)
+
+	// nothing was written
+	sushis, err := repo.GetSushis(ctx)
+	require.NoError(t, err)
+	assert.Len(t, sushis, 1)
+}
+
+func Test_Seed_Catalogue(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewRepository(map[string]sushi.Sushi{})
+	catalogue := inmem.NewIngredientRepository([]sushi.Ingredient{
+		*sushi.NewIngredient("Rice", "Rice", sushi.IngredientInfo{Vegetarian: true, Vegan: true}),
+		*sushi.NewIngredient("Salmon", "Salmon", sushi.IngredientInfo{Allergens: []sushi.Allergen{sushi.Fish}, RawFish: true}),
+	})
+
+	_, err := Seed(ctx, repo, catalogue, fixtures, false)
+	assert.ErrorIs(t, err, sushi.ErrInvalidSushi)
+	assert.ErrorContains(t, err, `the sushi "uramaki": invalid sushi: ingredient "Crab" isn't in the catalogue`)
+	sushis, err := repo.GetSushis(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, sushis, "nothing was written")
+
+	result, err := Seed(ctx, repo, catalogue, fixtures[:1], false)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Created: 1}, result)
+}
+
+func Test_SeedIngredients(t *testing.T) {
+	ctx := context.Background()
+	catalogue := inmem.NewIngredientRepository(nil)
+	defaults := []sushi.Ingredient{
+		*sushi.NewIngredient("Rice", "Rice", sushi.IngredientInfo{Vegetarian: true, Vegan: true}),
+		*sushi.NewIngredient("Salmon", "Salmon", sushi.IngredientInfo{Allergens: []sushi.Allergen{sushi.Fish}, RawFish: true}),
+	}
+
+	created, err := SeedIngredients(ctx, catalogue, defaults)
+	require.NoError(t, err)
+	assert.Equal(t, 2, created)
+
+	// the removed ingredients aren't brought back
+	require.NoError(t, catalogue.DeleteIngredient(ctx, "Salmon"))
+	created, err = SeedIngredients(ctx, catalogue, defaults)
+	require.NoError(t, err)
+	assert.Zero(t, created)
+	_, err = catalogue.GetIngredientByID(ctx, "Salmon")
+	assert.ErrorIs(t, err, sushi.ErrIngredientNotFound)
+}
+
+func Test_LoadFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	loaded, err := LoadFile(write("menu.yaml", `
+- id: nigiri
+  imageNumber: "1"
+  name: Salmon nigiri
+  ingredients: [Rice, Salmon]
+- id: uramaki
+  imageNumber: "2"
+  name: California uramaki
+  ingredients: [Rice, Crab]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, fixtures, loaded)
+
+	loaded, err = LoadFile(write("menu.json", `[{"id":"nigiri","imageNumber":"1","name":"Salmon nigiri","ingredients":["Rice","Salmon"]}]`))
+	require.NoError(t, err)
+	assert.Equal(t, fixtures[:1], loaded)
+
+	_, err = LoadFile(write("typo.yaml", "- id: nigiri\n  nmae: Salmon nigiri\n"))
+	assert.ErrorContains(t, err, `unknown field "nmae"`)
+
+	_, err = LoadFile(write("object.json", `{"id":"nigiri"}`))
+	assert.ErrorContains(t, err, "it must list sushis")
+
+	_, err = LoadFile(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_FromMap(t *testing.T) {
+	assert.Equal(t, fixtures, FromMap(map[string]sushi.Sushi{"uramaki": fixtures[1], "nigiri": fixtures[0]}))
+}
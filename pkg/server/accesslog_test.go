@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+)
+
+type accessLogSpy struct {
+	log.Logger
+	accesses   []log.Access
+	requestIDs []string
+}
+
+func (l *accessLogSpy) AccessLog(ctx context.Context, access log.Access) {
+	requestID, _ := RequestID(ctx)
+	l.accesses = append(l.accesses, access)
+	l.requestIDs = append(l.requestIDs, requestID)
+}
+
+func TestAccessLog(t *testing.T) {
+	testData := []struct {
+		name      string
+		method    string
+		uri       string
+		requestID string
+		apiKey    string
+		route     string
+		status    int
+		principal string
+	}{
+		{name: "propagated request id", method: "GET", uri: "/sushi/01D3XZ38KDR", requestID: "abc-123", route: "/sushi/{ID}", status: http.StatusOK},
+		{name: "api key principal", method: "GET", uri: "/sushi", apiKey: "kiosk", route: "/sushi", status: http.StatusOK, principal: apiKeyPrincipal("kiosk")},
+		{name: "unknown api key", method: "GET", uri: "/sushi", apiKey: "forged", route: "/sushi", status: http.StatusOK},
+		{name: "unmatched route", method: "GET", uri: "/menu", route: "/menu", status: http.StatusNotFound},
+		{name: "invalid request id", method: "PATCH", uri: "/sushi", requestID: "bad id", route: "/sushi", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &accessLogSpy{Logger: log.NewNoopLogger()}
+			s := buildServer(WithLogger(logger), WithAPIKeys([]string{"kiosk"}))
+
+			req := httptest.NewRequest(tt.method, tt.uri, nil)
+			if tt.requestID != "" {
+				req.Header.Set(requestIDHeader, tt.requestID)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set(apiKeyHeader, tt.apiKey)
+			}
+			resRecorder := httptest.NewRecorder()
+			s.Router().ServeHTTP(resRecorder, req)
+
+			if len(logger.accesses) != 1 {
+				t.Fatalf("expected 1 access log line, got: %d", len(logger.accesses))
+			}
+			access := logger.accesses[0]
+			if access.Route != tt.route || access.Status != tt.status || access.Principal != tt.principal {
+				t.Errorf("unexpected access log %+v", access)
+			}
+
+			got := resRecorder.Result().Header.Get(requestIDHeader)
+			if got == "" || got != logger.requestIDs[0] {
+				t.Errorf("expected response request id %q to match the logged one %q", got, logger.requestIDs[0])
+			}
+			if tt.requestID == "abc-123" && got != tt.requestID {
+				t.Errorf("expected %s, got: %s", tt.requestID, got)
+			}
+			if tt.requestID == "bad id" && got == tt.requestID {
+				t.Errorf("expected a generated request id, got: %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	testData := []struct {
+		header   string
+		expected string
+	}{
+		{header: "", expected: ""},
+		{header: "identity", expected: ""},
+		{header: "gzip", expected: "gzip"},
+		{header: "gzip, deflate, br", expected: "br"},
+		{header: "gzip, deflate, br, zstd", expected: "zstd"},
+		{header: "br;q=0.5, gzip;q=0.8", expected: "gzip"},
+		{header: "zstd;q=0, gzip", expected: "gzip"},
+		{header: "*", expected: "zstd"},
+		{header: "*;q=0.1, zstd;q=0", expected: "br"},
+		{header: "GZIP", expected: "gzip"},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := negotiateEncoding(tt.header); got != tt.expected {
+				t.Errorf("expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompression(t *testing.T) {
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+		"zstd": func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+	}
+
+	s := buildServer(WithCompression(16))
+	for encoding, decode := range decoders {
+		t.Run(encoding, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/sushi", nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			resRecorder := httptest.NewRecorder()
+
+			s.Router().ServeHTTP(resRecorder, req)
+
+			res := resRecorder.Result()
+			defer res.Body.Close()
+			if got := res.Header.Get("Content-Encoding"); got != encoding {
+				t.Fatalf("expected %s encoding, got: %q", encoding, got)
+			}
+			if got := res.Header.Values("Vary"); !contains(got, "Accept-Encoding") {
+				t.Errorf("expected to vary on Accept-Encoding, got: %v", got)
+			}
+
+			body, err := decode(res.Body)
+			if err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+			var got []sushi.Sushi
+			if err := json.NewDecoder(body).Decode(&got); err != nil {
+				t.Fatalf("could not unmarshall response %v", err)
+			}
+			if len(got) == 0 {
+				t.Errorf("expected sushis, got none")
+			}
+		})
+	}
+}
+
+func TestCompressionSkipped(t *testing.T) {
+	testData := []struct {
+		name           string
+		acceptEncoding string
+		minSize        int
+	}{
+		{name: "not accepted", acceptEncoding: "", minSize: 16},
+		{name: "below the minimum size", acceptEncoding: "gzip", minSize: 1 << 20},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			s := buildServer(WithCompression(tt.minSize))
+			req := httptest.NewRequest("GET", "/sushi", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			resRecorder := httptest.NewRecorder()
+
+			s.Router().ServeHTTP(resRecorder, req)
+
+			res := resRecorder.Result()
+			defer res.Body.Close()
+			if got := res.Header.Get("Content-Encoding"); got != "" {
+				t.Errorf("expected no encoding, got: %q", got)
+			}
+			b, err := ioutil.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if !json.Valid(b) {
+				t.Errorf("expected plain JSON, got: %q", b)
+			}
+		})
+	}
+}
+
+func TestCompressionNoContent(t *testing.T) {
+	s := buildServer(WithCompression(0))
+	req := httptest.NewRequest("DELETE", "/sushi/unknown", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resRecorder := httptest.NewRecorder()
+
+	s.Router().ServeHTTP(resRecorder, req)
+
+	if resRecorder.Code != http.StatusNoContent {
+		t.Errorf("expected %d, got: %d", http.StatusNoContent, resRecorder.Code)
+	}
+	if got := resRecorder.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("expected no encoding, got: %q", got)
+	}
+	if resRecorder.Body.Len() != 0 {
+		t.Errorf("expected an empty body, got: %q", resRecorder.Body.String())
+	}
+}
+
+func TestCompressionFlush(t *testing.T) {
+	handler := newCompressionMiddleware(1 << 20)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("sushi", 10)))
+		http.NewResponseController(w).Flush()
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resRecorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(resRecorder, req)
+
+	if !resRecorder.Flushed {
+		t.Errorf("expected the response to be flushed")
+	}
+	if got := resRecorder.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected a flushed response to be compressed, got: %q", got)
+	}
+	body, err := gzip.NewReader(resRecorder.Body)
+	if err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	b, _ := ioutil.ReadAll(body)
+	if string(b) != strings.Repeat("sushi", 10) {
+		t.Errorf("unexpected body %q", b)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
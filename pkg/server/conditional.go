@@ -0,0 +1,135 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CacheControlPolicy maps route templates like "GET /sushi" to the
+// Cache-Control directives of their successful responses
+type CacheControlPolicy map[string]string
+
+// ParseCacheControl parses routes like
+// "GET /sushi=public, max-age=60; GET /sushi/{ID}=no-cache", the directives
+// are separated by commas so the routes are separated by semicolons
+func ParseCacheControl(routes string) (CacheControlPolicy, error) {
+	policy := make(CacheControlPolicy)
+	for _, route := range strings.Split(routes, ";") {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+		template, directives, ok := strings.Cut(route, "=")
+		template, directives = strings.TrimSpace(template), strings.TrimSpace(directives)
+		if !ok || template == "" || directives == "" {
+			return nil, fmt.Errorf("invalid cache control %q, expected ROUTE=DIRECTIVES", route)
+		}
+		policy[template] = directives
+	}
+	return policy, nil
+}
+
+func newCacheControlMiddleware(policy CacheControlPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			directives, ok := policy[routeTemplate(r)]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, directives: directives}, r)
+		})
+	}
+}
+
+// cacheControlWriter sets the Cache-Control header unless the response is an
+// error, caches mustn't keep those for as long
+type cacheControlWriter struct {
+	http.ResponseWriter
+	directives  string
+	wroteHeader bool
+}
+
+func (w *cacheControlWriter) WriteHeader(status int) {
+	if !w.wroteHeader && status >= http.StatusOK {
+		w.wroteHeader = true
+		if status < http.StatusBadRequest && w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", w.directives)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheControlWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers flush through the writer
+func (w *cacheControlWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// weakETag builds a weak entity tag, the representations are compressed on
+// the fly so they are only semantically equivalent
+func weakETag(tag string) string {
+	return `W/"` + tag + `"`
+}
+
+// contentETag derives the entity tag of a representation from its content
+func contentETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return weakETag(hex.EncodeToString(sum[:16]))
+}
+
+// notModified sets the validators of the representation and tells whether the
+// copy of the client is still fresh. If-Modified-Since is only evaluated when
+// there is no If-None-Match, as RFC 9110 section 13.2.2 requires.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etag != "" && matchesETag(ifNoneMatch, etag)
+	}
+
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		// the header has a one second precision
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// matchesETag compares the entity tags of an If-None-Match header with the
+// weak comparison function
+func matchesETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func get(s Server, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resRecorder := httptest.NewRecorder()
+	s.Router().ServeHTTP(resRecorder, req)
+	return resRecorder
+}
+
+func TestGetSushisConditional(t *testing.T) {
+	s := buildServer()
+
+	first := get(s, "/sushi", nil)
+	etag := first.Header().Get("ETag")
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("expected a weak ETag, got: %q", etag)
+	}
+	if first.Header().Get("Last-Modified") == "" {
+		t.Errorf("expected a Last-Modified header")
+	}
+
+	res := get(s, "/sushi", map[string]string{"If-None-Match": etag})
+	if res.Code != http.StatusNotModified {
+		t.Errorf("expected %d, got: %d", http.StatusNotModified, res.Code)
+	}
+	if res.Body.Len() != 0 {
+		t.Errorf("expected no body, got: %q", res.Body.String())
+	}
+
+	ndjson := get(s, "/sushi", map[string]string{"If-None-Match": etag, "Accept": ndjsonContentType})
+	if ndjson.Code != http.StatusOK {
+		t.Errorf("expected the NDJSON representation to have its own ETag, got: %d", ndjson.Code)
+	}
+
+	res = get(s, "/sushi", map[string]string{"If-Modified-Since": time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)})
+	if res.Code != http.StatusNotModified {
+		t.Errorf("expected %d, got: %d", http.StatusNotModified, res.Code)
+	}
+
+	req := httptest.NewRequest("POST", "/sushi", strings.NewReader(`{"id":"etag_test","name":"Temaki"}`))
+	s.Router().ServeHTTP(httptest.NewRecorder(), req)
+	defer s.Router().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/sushi/etag_test", nil))
+
+	res = get(s, "/sushi", map[string]string{"If-None-Match": etag})
+	if res.Code != http.StatusOK {
+		t.Errorf("expected the catalogue to change, got: %d", res.Code)
+	}
+	if res.Header().Get("ETag") == etag {
+		t.Errorf("expected a new ETag")
+	}
+}
+
+func TestGetSushiConditional(t *testing.T) {
+	s := buildServer()
+
+	first := get(s, "/sushi/01D3XZ38KDR", nil)
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected an ETag")
+	}
+
+	res := get(s, "/sushi/01D3XZ38KDR", map[string]string{"If-None-Match": `"other", ` + strings.TrimPrefix(etag, "W/")})
+	if res.Code != http.StatusNotModified {
+		t.Errorf("expected %d, got: %d", http.StatusNotModified, res.Code)
+	}
+
+	res = get(s, "/sushi/01D3XZ38KDR", map[string]string{"If-None-Match": `"other"`})
+	if res.Code != http.StatusOK {
+		t.Errorf("expected %d, got: %d", http.StatusOK, res.Code)
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	policy, err := ParseCacheControl("GET /sushi=public, max-age=60; GET /sushi/{ID}=no-cache")
+	if err != nil {
+		t.Fatalf("could not parse the policy: %v", err)
+	}
+	s := buildServer(WithCacheControl(policy))
+
+	testData := []struct {
+		path     string
+		headers  map[string]string
+		expected string
+	}{
+		{path: "/sushi", expected: "public, max-age=60"},
+		{path: "/sushi/01D3XZ38KDR", expected: "no-cache"},
+		{path: "/sushi/unknown", expected: ""},
+	}
+	for _, tt := range testData {
+		t.Run(tt.path, func(t *testing.T) {
+			res := get(s, tt.path, nil)
+			if got := res.Header().Get("Cache-Control"); got != tt.expected {
+				t.Errorf("expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+
+	etag := get(s, "/sushi", nil).Header().Get("ETag")
+	res := get(s, "/sushi", map[string]string{"If-None-Match": etag})
+	if got := res.Header().Get("Cache-Control"); res.Code != http.StatusNotModified || got != "public, max-age=60" {
+		t.Errorf("expected a 304 with the Cache-Control header, got: %d %q", res.Code, got)
+	}
+}
+
+func TestParseCacheControlInvalid(t *testing.T) {
+	if _, err := ParseCacheControl("GET /sushi"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -10,6 +10,9 @@ var (
 	contextKeyXForwardedProto = contextKey("xForwardedProto")
 	contextKeyEndpoint        = contextKey("Endpoint")
 	contextKeyClientIP        = contextKey("ClientIP")
+	contextKeyRequestID       = contextKey("RequestID")
+	contextKeyPrincipal       = contextKey("Principal")
+	contextKeyClientCert      = contextKey("ClientCertSubject")
 )
 
 type contextKey string
@@ -46,4 +49,22 @@ func Endpoint(ctx context.Context) (string, bool) {
 func ClientIP(ctx context.Context) (string, bool) {
 	clientIP, ok := ctx.Value(contextKeyClientIP).(string)
 	return clientIP, ok
-}
\ No newline at end of file
+}
+
+// RequestID gets the identifier of the request from context
+func RequestID(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(contextKeyRequestID).(string)
+	return requestID, ok
+}
+
+// Principal gets the identity of the caller from context
+func Principal(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(contextKeyPrincipal).(string)
+	return principal, ok
+}
+
+// ClientCertSubject gets the subject of the verified client certificate from context
+func ClientCertSubject(ctx context.Context) (string, bool) {
+	subject, ok := ctx.Value(contextKeyClientCert).(string)
+	return subject, ok
+}
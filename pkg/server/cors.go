@@ -0,0 +1,147 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CORSPolicy defines which browser origins may call the API and how
+type CORSPolicy struct {
+	// AllowedOrigins lists the origins allowed, "*" allows any origin and a
+	// single "*" inside an origin matches a part of it, e.g. https://*.example.com
+	AllowedOrigins []string
+	// AllowedMethods lists the methods a preflight may ask for
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers a preflight may ask for, "*" allows any
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers readable by the browser
+	ExposedHeaders []string
+	// AllowCredentials lets the browser send cookies and client certificates
+	AllowCredentials bool
+	// MaxAge is how long the browser may cache a preflight response
+	MaxAge time.Duration
+}
+
+// allowsOrigin tells whether the policy lists the origin
+func (p CORSPolicy) allowsOrigin(origin string) bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok &&
+			len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+			strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p CORSPolicy) allowsMethod(method string) bool {
+	for _, allowed := range p.AllowedMethods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p CORSPolicy) allowsHeaders(headers []string) bool {
+	for _, header := range headers {
+		allowed := false
+		for _, a := range p.AllowedHeaders {
+			if a == "*" || strings.EqualFold(a, header) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// newCORSHandler answers the preflight requests of every route of the router
+// and adds the CORS headers to the other responses. It sits in front of the
+// router because mux doesn't run the middlewares for OPTIONS requests unless
+// every route registers that method.
+func newCORSHandler(policy CORSPolicy, router *mux.Router) http.Handler {
+	allowedMethods := strings.Join(policy.AllowedMethods, ", ")
+	exposedHeaders := strings.Join(policy.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(policy.MaxAge.Seconds()))
+
+	allowOrigin := func(w http.ResponseWriter, origin string) {
+		// the wildcard can't be used with credentials, the origin is echoed instead
+		if !policy.AllowCredentials && len(policy.AllowedOrigins) == 1 && policy.AllowedOrigins[0] == "*" {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if policy.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// caches must keep apart the responses of every origin
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		requestedMethod := r.Header.Get("Access-Control-Request-Method")
+		if origin == "" {
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method != http.MethodOptions || requestedMethod == "" {
+			if policy.allowsOrigin(origin) {
+				allowOrigin(w, origin)
+				if exposedHeaders != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+				}
+			}
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+		// the preflight is answered for the routes serving the requested method
+		var match mux.RouteMatch
+		target := r.Clone(r.Context())
+		target.Method = requestedMethod
+		if router.Match(target, &match); match.MatchErr == mux.ErrNotFound {
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		requestedHeaders := splitHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+		if match.MatchErr == nil && policy.allowsOrigin(origin) && policy.allowsMethod(requestedMethod) && policy.allowsHeaders(requestedHeaders) {
+			allowOrigin(w, origin)
+			w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+			if len(requestedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+			}
+			if policy.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func splitHeaderList(list string) []string {
+	var headers []string
+	for _, header := range strings.Split(list, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func corsPolicy() CORSPolicy {
+	return CORSPolicy{
+		AllowedOrigins: []string{"https://menu.example.com", "https://*.sushi.dev"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+		AllowedHeaders: []string{"Content-Type", "X-API-Key"},
+		ExposedHeaders: []string{"X-Request-ID"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	testData := []struct {
+		name         string
+		path         string
+		origin       string
+		method       string
+		headers      string
+		status       int
+		allowOrigin  string
+		allowHeaders string
+	}{
+		{name: "allowed", path: "/sushi/01D3XZ38KDR", origin: "https://menu.example.com", method: "PUT", headers: "content-type, x-api-key", status: http.StatusNoContent, allowOrigin: "https://menu.example.com", allowHeaders: "content-type, x-api-key"},
+		{name: "wildcard origin", path: "/sushi", origin: "https://editor.sushi.dev", method: "POST", status: http.StatusNoContent, allowOrigin: "https://editor.sushi.dev"},
+		{name: "unknown origin", path: "/sushi", origin: "https://evil.example.com", method: "POST", status: http.StatusNoContent},
+		{name: "wildcard without subdomain", path: "/sushi", origin: "https://.sushi.dev", method: "POST", status: http.StatusNoContent},
+		{name: "header not allowed", path: "/sushi", origin: "https://menu.example.com", method: "POST", headers: "X-Secret", status: http.StatusNoContent},
+		{name: "method not served by the route", path: "/sushi", origin: "https://menu.example.com", method: "DELETE", status: http.StatusNoContent},
+		{name: "unknown route", path: "/ramen", origin: "https://menu.example.com", method: "GET", status: http.StatusNotFound},
+	}
+
+	s := buildServer(WithCORS(corsPolicy()))
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("OPTIONS", tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			resRecorder := httptest.NewRecorder()
+
+			s.Router().ServeHTTP(resRecorder, req)
+
+			res := resRecorder.Result()
+			if res.StatusCode != tt.status {
+				t.Errorf("expected %d, got: %d", tt.status, res.StatusCode)
+			}
+			if got := res.Header.Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("expected allowed origin %q, got: %q", tt.allowOrigin, got)
+			}
+			if got := res.Header.Get("Access-Control-Allow-Headers"); got != tt.allowHeaders {
+				t.Errorf("expected allowed headers %q, got: %q", tt.allowHeaders, got)
+			}
+			if tt.allowOrigin != "" {
+				if got := res.Header.Get("Access-Control-Allow-Methods"); got != "GET, POST, PUT, DELETE" {
+					t.Errorf("unexpected allowed methods %q", got)
+				}
+				if got := res.Header.Get("Access-Control-Max-Age"); got != "600" {
+					t.Errorf("expected a max age of 600, got: %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	s := buildServer(WithCORS(corsPolicy()))
+	req := httptest.NewRequest("GET", "/sushi", nil)
+	req.Header.Set("Origin", "https://menu.example.com")
+	resRecorder := httptest.NewRecorder()
+
+	s.Router().ServeHTTP(resRecorder, req)
+
+	res := resRecorder.Result()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected %d, got: %d", http.StatusOK, res.StatusCode)
+	}
+	if got := res.Header.Get("Access-Control-Allow-Origin"); got != "https://menu.example.com" {
+		t.Errorf("unexpected allowed origin %q", got)
+	}
+	if got := res.Header.Get("Access-Control-Expose-Headers"); got != "X-Request-ID" {
+		t.Errorf("unexpected exposed headers %q", got)
+	}
+	if got := res.Header.Values("Vary"); !contains(got, "Origin") {
+		t.Errorf("expected to vary on Origin, got: %v", got)
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	testData := []struct {
+		name        string
+		credentials bool
+		expected    string
+	}{
+		{name: "without credentials", expected: "*"},
+		{name: "with credentials", credentials: true, expected: "https://menu.example.com"},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := corsPolicy()
+			policy.AllowedOrigins = []string{"*"}
+			policy.AllowCredentials = tt.credentials
+			s := buildServer(WithCORS(policy))
+			req := httptest.NewRequest("GET", "/sushi", nil)
+			req.Header.Set("Origin", "https://menu.example.com")
+			resRecorder := httptest.NewRecorder()
+
+			s.Router().ServeHTTP(resRecorder, req)
+
+			if got := resRecorder.Header().Get("Access-Control-Allow-Origin"); got != tt.expected {
+				t.Errorf("expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sergiorra/sushi-api-go/pkg/graphql"
+)
+
+const (
+	// graphqlWSProtocol is the websocket subprotocol of the subscriptions,
+	// the one of the graphql-ws clients
+	graphqlWSProtocol = "graphql-transport-ws"
+
+	graphqlMaxBody     = 1 << 20
+	graphqlInitTimeout = 10 * time.Second
+)
+
+// close codes of graphql-transport-ws
+const (
+	graphqlInvalidMessage      = 4400
+	graphqlUnauthorized        = 4401
+	graphqlBadSubprotocol      = 4406
+	graphqlInitTimedOut        = 4408
+	graphqlSubscriberExists    = 4409
+	graphqlTooManyInitRequests = 4429
+)
+
+// GraphQL executes the queries, sent with GET or POST, and the mutations,
+// sent with POST. The subscriptions need a websocket.
+func (s *server) GraphQL(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		s.graphqlSocket(w, r)
+		return
+	}
+
+	var req graphql.Request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				writeJSON(w, http.StatusBadRequest, graphql.ErrorResult(errors.New("the variables must be a JSON object")))
+				return
+			}
+		}
+	} else {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, graphqlMaxBody)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, graphql.ErrorResult(errors.New("the body must be a JSON GraphQL request")))
+			return
+		}
+	}
+
+	operation, result := s.graphql.Parse(req)
+	if result != nil {
+		writeJSON(w, http.StatusBadRequest, result)
+		return
+	}
+	switch {
+	case operation.Kind() == graphql.KindSubscription:
+		writeJSON(w, http.StatusBadRequest, graphql.ErrorResult(errors.New("the subscriptions need a websocket")))
+		return
+	// GET must stay safe, the caches and the prefetches may send it
+	case operation.Kind() == graphql.KindMutation && r.Method == http.MethodGet:
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, graphql.ErrorResult(errors.New("the mutations need POST")))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, operation.Execute(r.Context()))
+}
+
+// graphqlMessage is a message of graphql-transport-ws, both ways
+type graphqlMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type graphqlConn struct {
+	conn *websocket.Conn
+	send chan graphqlMessage
+
+	mtx        sync.Mutex
+	operations map[string]context.CancelFunc
+}
+
+// reply queues a message, a client that doesn't read them is disconnected
+func (c *graphqlConn) reply(msg graphqlMessage) {
+	select {
+	case c.send <- msg:
+	default:
+		_ = c.conn.Close()
+	}
+}
+
+func (c *graphqlConn) replyWith(ID, kind string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		data, _ = json.Marshal(graphql.ErrorResult(err).Errors)
+		kind = "error"
+	}
+	c.reply(graphqlMessage{ID: ID, Type: kind, Payload: data})
+}
+
+// close ends the connection with one of the close codes of the protocol
+func (c *graphqlConn) close(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	_ = c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsWriteWait))
+	_ = c.conn.Close()
+}
+
+// graphqlSocket runs the operations of a graphql-transport-ws client, every
+// result of the subscriptions being sent as it comes
+func (s *server) graphqlSocket(w http.ResponseWriter, r *http.Request) {
+	// the upgrader replies to failed handshakes
+	conn, err := s.graphqlUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &graphqlConn{
+		conn:       conn,
+		send:       make(chan graphqlMessage, wsSendBuffer),
+		operations: make(map[string]context.CancelFunc),
+	}
+	if conn.Subprotocol() != graphqlWSProtocol {
+		c.close(graphqlBadSubprotocol, "Subprotocol not acceptable")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	var running sync.WaitGroup
+	done := make(chan struct{})
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		s.writeGraphQLSocket(c, done)
+	}()
+
+	s.readGraphQLSocket(ctx, c, &running)
+
+	// the operations end before the writer, they may still reply
+	cancel()
+	running.Wait()
+	close(done)
+	<-written
+}
+
+func (s *server) readGraphQLSocket(ctx context.Context, c *graphqlConn, running *sync.WaitGroup) {
+	c.conn.SetReadLimit(graphqlMaxBody)
+	_ = c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	initialised := false
+	initTimeout := time.AfterFunc(graphqlInitTimeout, func() {
+		c.close(graphqlInitTimedOut, "Connection initialisation timeout")
+	})
+	defer initTimeout.Stop()
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg graphqlMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.close(graphqlInvalidMessage, "Invalid message")
+			return
+		}
+
+		switch msg.Type {
+		case "connection_init":
+			if initialised {
+				c.close(graphqlTooManyInitRequests, "Too many initialisation requests")
+				return
+			}
+			// the handshake went through the middlewares, the connection is
+			// authenticated already
+			initTimeout.Stop()
+			initialised = true
+			c.reply(graphqlMessage{Type: "connection_ack"})
+
+		case "ping":
+			c.reply(graphqlMessage{Type: "pong", Payload: msg.Payload})
+
+		case "pong":
+			// the answer to a ping of the client library, nothing to do
+
+		case "subscribe":
+			if !initialised {
+				c.close(graphqlUnauthorized, "Unauthorized")
+				return
+			}
+			var req graphql.Request
+			if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil {
+				c.close(graphqlInvalidMessage, "Invalid message")
+				return
+			}
+			if !s.startGraphQLOperation(ctx, c, msg.ID, req, running) {
+				c.close(graphqlSubscriberExists, "Subscriber for "+msg.ID+" already exists")
+				return
+			}
+
+		case "complete":
+			c.mtx.Lock()
+			if stop, ok := c.operations[msg.ID]; ok {
+				stop()
+			}
+			c.mtx.Unlock()
+
+		default:
+			c.close(graphqlInvalidMessage, "Invalid message")
+			return
+		}
+	}
+}
+
+// startGraphQLOperation runs the operation in the background, it returns
+// false when the ID is already taken
+func (s *server) startGraphQLOperation(ctx context.Context, c *graphqlConn, ID string, req graphql.Request, running *sync.WaitGroup) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if _, ok := c.operations[ID]; ok {
+		return false
+	}
+
+	operation, result := s.graphql.Parse(req)
+	if result != nil {
+		c.replyWith(ID, "error", result.Errors)
+		return true
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	c.operations[ID] = stop
+	running.Add(1)
+	go func() {
+		defer running.Done()
+		defer func() {
+			c.mtx.Lock()
+			delete(c.operations, ID)
+			c.mtx.Unlock()
+			stop()
+		}()
+
+		if operation.Kind() == graphql.KindSubscription {
+			for result := range operation.Subscribe(ctx) {
+				if ctx.Err() == nil {
+					c.replyWith(ID, "next", result)
+				}
+			}
+		} else {
+			c.replyWith(ID, "next", operation.Execute(ctx))
+		}
+		// a client completing the operation expects nothing more
+		if ctx.Err() == nil {
+			c.reply(graphqlMessage{ID: ID, Type: "complete"})
+		}
+	}()
+	return true
+}
+
+// writeGraphQLSocket is the only writer of the connection but for the close
+// messages
+func (s *server) writeGraphQLSocket(c *graphqlConn, done <-chan struct{}) {
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+	// ends the reads when the writes fail
+	defer c.conn.Close()
+
+	for {
+		var err error
+		select {
+		case <-done:
+			return
+		case msg := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = c.conn.WriteJSON(msg)
+		case <-ping.C:
+			err = c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sergiorra/sushi-api-go/cmd/sample-data"
+	"github.com/sergiorra/sushi-api-go/pkg/adding"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/getting"
+	"github.com/sergiorra/sushi-api-go/pkg/graphql"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/modifying"
+	"github.com/sergiorra/sushi-api-go/pkg/removing"
+	"github.com/sergiorra/sushi-api-go/pkg/storage/inmem"
+)
+
+func buildServerWithGraphQL(t *testing.T, bus *events.Bus, opts ...Option) Server {
+	t.Helper()
+	repo := inmem.NewRepository(sample.Sushis)
+	logger := log.NewNoopLogger()
+	gS := getting.NewService(repo, logger)
+	aS := adding.NewService(repo, logger, bus)
+	mS := modifying.NewService(repo, logger, bus, locking.NewMemoryLocker())
+	rS := removing.NewService(repo, logger, bus)
+
+	schema, err := graphql.NewSchema(gS, aS, mS, rS, bus, graphql.Limits{MaxDepth: 5})
+	if err != nil {
+		t.Fatalf("could not build the schema: %v", err)
+	}
+	return New("test", gS, aS, mS, rS, append(opts, WithGraphQL(schema))...)
+}
+
+func TestGraphQL(t *testing.T) {
+	bus := events.NewBus(10, 10)
+	defer bus.Close()
+	s := buildServerWithGraphQL(t, bus)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+		expect string
+	}{
+		{"query posted", "POST", "/graphql", `{"query":"query($id: ID!) { sushi(id: $id) { name } }","variables":{"id":"01D3XZ38KDR"}}`,
+			http.StatusOK, `{"data":{"sushi":{"name":"California Roll"}}}`},
+		{"query in the url", "GET", "/graphql?query=" + url.QueryEscape(`{ sushi(id: "01D3XZ38TRE") { name } }`), "",
+			http.StatusOK, `{"data":{"sushi":{"name":"Tiger Roll"}}}`},
+		{"mutation in the url", "GET", "/graphql?query=" + url.QueryEscape(`mutation { removeSushi(id: "01D3XZ38KDR") }`), "",
+			http.StatusMethodNotAllowed, `{"data":null,"errors":[{"message":"the mutations need POST","locations":[]}]}`},
+		{"subscription posted", "POST", "/graphql", `{"query":"subscription { sushiChanged { id } }"}`,
+			http.StatusBadRequest, `{"data":null,"errors":[{"message":"the subscriptions need a websocket","locations":[]}]}`},
+		{"invalid body", "POST", "/graphql", `query { sushis }`,
+			http.StatusBadRequest, `{"data":null,"errors":[{"message":"the body must be a JSON GraphQL request","locations":[]}]}`},
+		{"too deep", "POST", "/graphql", `{"query":"{ sushis { nodes { ingredients { sushis { ingredients { name } } } } } }"}`,
+			http.StatusBadRequest, `{"data":null,"errors":[{"message":"the query depth 6 exceeds the limit of 5","locations":[]}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			resRecorder := httptest.NewRecorder()
+			s.Router().ServeHTTP(resRecorder, req)
+
+			if resRecorder.Code != tt.status {
+				t.Errorf("expected status %d, got: %d", tt.status, resRecorder.Code)
+			}
+			if got := mustCompact(resRecorder.Body.String()); got != mustCompact(tt.expect) {
+				t.Errorf("expected %s, got: %s", tt.expect, got)
+			}
+		})
+	}
+}
+
+// mustCompact normalizes the JSON document, ordering the keys
+func mustCompact(s string) string {
+	var v interface{}
+	_ = json.Unmarshal([]byte(s), &v)
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func dialGraphQL(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	dialer := websocket.Dialer{Subprotocols: []string{graphqlWSProtocol}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/graphql", nil)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func receiveGraphQL(t *testing.T, conn *websocket.Conn) graphqlMessage {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg graphqlMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("expected a message: %v", err)
+	}
+	return msg
+}
+
+func TestGraphQLSocket(t *testing.T) {
+	bus := events.NewBus(10, 10)
+	defer bus.Close()
+	s := buildServerWithGraphQL(t, bus)
+	srv := httptest.NewServer(s.Router())
+	defer srv.Close()
+
+	conn := dialGraphQL(t, srv.URL)
+	_ = conn.WriteJSON(graphqlMessage{Type: "connection_init"})
+	if msg := receiveGraphQL(t, conn); msg.Type != "connection_ack" {
+		t.Fatalf("expected the connection to be acknowledged, got: %+v", msg)
+	}
+
+	// the queries run too, completed after their result
+	_ = conn.WriteJSON(graphqlMessage{ID: "q", Type: "subscribe", Payload: json.RawMessage(`{"query":"{ sushi(id: \"01D3XZ38KLE\") { name } }"}`)})
+	if msg := receiveGraphQL(t, conn); msg.ID != "q" || msg.Type != "next" || string(msg.Payload) != `{"data":{"sushi":{"name":"Crunch Roll"}}}` {
+		t.Errorf("expected the result of the query, got: %+v %s", msg, msg.Payload)
+	}
+	if msg := receiveGraphQL(t, conn); msg.ID != "q" || msg.Type != "complete" {
+		t.Errorf("expected the query to complete, got: %+v", msg)
+	}
+
+	_ = conn.WriteJSON(graphqlMessage{ID: "s", Type: "subscribe", Payload: json.RawMessage(`{"query":"subscription { sushiChanged { type sushiId } }"}`)})
+	// the subscription starts asynchronously, publish until it's seen
+	go func() {
+		for i := 0; i < 100; i++ {
+			bus.Publish(context.Background(), events.New(events.SushiRemoved, "01D3XZ38GQL", nil))
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+	if msg := receiveGraphQL(t, conn); msg.ID != "s" || msg.Type != "next" || mustCompact(string(msg.Payload)) != mustCompact(`{"data":{"sushiChanged":{"type":"REMOVED","sushiId":"01D3XZ38GQL"}}}`) {
+		t.Errorf("expected an event, got: %+v %s", msg, msg.Payload)
+	}
+
+	_ = conn.WriteJSON(graphqlMessage{ID: "invalid", Type: "subscribe", Payload: json.RawMessage(`{"query":"{ sushi { name } }"}`)})
+	for {
+		msg := receiveGraphQL(t, conn)
+		if msg.ID == "s" {
+			continue
+		}
+		if msg.ID != "invalid" || msg.Type != "error" {
+			t.Errorf("expected the invalid query to be rejected, got: %+v", msg)
+		}
+		break
+	}
+
+	// reusing the ID of a running subscription is a protocol error
+	_ = conn.WriteJSON(graphqlMessage{ID: "s", Type: "subscribe", Payload: json.RawMessage(`{"query":"subscription { sushiChanged { id } }"}`)})
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var msg graphqlMessage
+		err := conn.ReadJSON(&msg)
+		if err == nil {
+			continue
+		}
+		if !websocket.IsCloseError(err, graphqlSubscriberExists) {
+			t.Errorf("expected the connection to be closed with %d, got: %v", graphqlSubscriberExists, err)
+		}
+		break
+	}
+}
+
+func TestGraphQLSocket_Unauthorized(t *testing.T) {
+	bus := events.NewBus(10, 10)
+	defer bus.Close()
+	s := buildServerWithGraphQL(t, bus)
+	srv := httptest.NewServer(s.Router())
+	defer srv.Close()
+
+	conn := dialGraphQL(t, srv.URL)
+	_ = conn.WriteJSON(graphqlMessage{ID: "1", Type: "subscribe", Payload: json.RawMessage(`{"query":"{ sushis { totalCount } }"}`)})
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, graphqlUnauthorized) {
+		t.Errorf("expected the connection to be closed with %d, got: %v", graphqlUnauthorized, err)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const readinessCheckTimeout = 2 * time.Second
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Liveness reports the process is up and serving
+func (s *server) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(healthResponse{Status: "ok"})
+}
+
+// Readiness reports whether the server can take traffic: it's not shutting
+// down and every dependency answers
+func (s *server) Readiness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if atomic.LoadInt32(&s.draining) == 1 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(healthResponse{Status: "shutting down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	res := healthResponse{Status: "ok", Checks: make(map[string]string, len(s.readinessChecks))}
+	for name, pinger := range s.readinessChecks {
+		if err := pinger.Ping(ctx); err != nil {
+			res.Status = "unavailable"
+			res.Checks[name] = err.Error()
+			continue
+		}
+		res.Checks[name] = "ok"
+	}
+
+	if res.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// SetReady flips the readiness, it must be set to false as soon as the
+// shutdown starts so load balancers stop routing new requests
+func (s *server) SetReady(ready bool) {
+	var draining int32
+	if !ready {
+		draining = 1
+	}
+	atomic.StoreInt32(&s.draining, draining)
+}
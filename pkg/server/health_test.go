@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestHealth(t *testing.T) {
+	reachable := pingerFunc(func(ctx context.Context) error { return nil })
+	unreachable := pingerFunc(func(ctx context.Context) error { return errors.New("connection refused") })
+
+	testData := []struct {
+		name     string
+		uri      string
+		opts     []Option
+		draining bool
+		status   int
+	}{
+		{name: "alive", uri: "/healthz", status: http.StatusOK},
+		{name: "alive while draining", uri: "/healthz", draining: true, status: http.StatusOK},
+		{name: "ready", uri: "/readyz", opts: []Option{WithReadinessCheck("repository", reachable)}, status: http.StatusOK},
+		{name: "dependency down", uri: "/readyz", opts: []Option{WithReadinessCheck("repository", unreachable)}, status: http.StatusServiceUnavailable},
+		{name: "draining", uri: "/readyz", opts: []Option{WithReadinessCheck("repository", reachable)}, draining: true, status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			s := buildServer(tt.opts...)
+			if tt.draining {
+				s.SetReady(false)
+			}
+
+			resRecorder := httptest.NewRecorder()
+			s.Router().ServeHTTP(resRecorder, httptest.NewRequest("GET", tt.uri, nil))
+
+			if tt.status != resRecorder.Code {
+				t.Errorf("expected %d, got: %d", tt.status, resRecorder.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sergiorra/sushi-api-go/pkg/idempotency"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKey    = 255
+)
+
+// newIdempotencyMiddleware replays the first response of the mutating
+// requests carrying an Idempotency-Key, so a client retrying after a network
+// failure doesn't apply its write twice
+func newIdempotencyMiddleware(store idempotency.Store, ttl time.Duration, maxBody int64, logger log.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" || !mutating(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKey {
+				writeJSON(w, http.StatusBadRequest, fmt.Sprintf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKey))
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeJSON(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("The body of a request carrying an %s must be at most %d bytes", idempotencyKeyHeader, maxBody))
+				return
+			}
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, "Can't read the request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// keys are only unique per client
+			key = idempotencyClient(r) + "|" + key
+			fingerprint := requestFingerprint(r, body)
+			record, reserved, err := store.Reserve(r.Context(), key, fingerprint, ttl)
+			if err != nil {
+				// an unavailable store must not take the API down with it
+				logger.UnexpectedError(r.Context(), err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			switch {
+			case record.Fingerprint != fingerprint:
+				writeJSON(w, http.StatusUnprocessableEntity, idempotencyKeyHeader+" was already used with another request")
+				return
+			case !reserved && record.Pending:
+				w.Header().Set("Retry-After", "1")
+				writeJSON(w, http.StatusConflict, "A request with the same "+idempotencyKeyHeader+" is in progress")
+				return
+			case !reserved:
+				replay(w, record)
+				return
+			}
+
+			// the outcome is stored even when the client gave up waiting for it
+			ctx := context.WithoutCancel(r.Context())
+			recorder := &idempotencyRecorder{ResponseWriter: w, header: make(http.Header)}
+			defer func() {
+				// a panicking handler leaves the key free for a retry
+				if p := recover(); p != nil {
+					_ = store.Release(ctx, key)
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+			if recorder.status == 0 {
+				recorder.WriteHeader(http.StatusOK)
+			}
+
+			// failures are released for a retry rather than replayed
+			if recorder.status >= http.StatusInternalServerError {
+				if err := store.Release(ctx, key); err != nil {
+					logger.UnexpectedError(ctx, err)
+				}
+				return
+			}
+			record = idempotency.Record{
+				Fingerprint: fingerprint,
+				Status:      recorder.status,
+				Header:      recorder.header,
+				Body:        recorder.body.Bytes(),
+			}
+			if err := store.Complete(ctx, key, record, ttl); err != nil {
+				logger.UnexpectedError(ctx, err)
+			}
+		})
+	}
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotencyClient identifies the caller by principal, falling back to the IP
+func idempotencyClient(r *http.Request) string {
+	if principal, ok := Principal(r.Context()); ok && principal != "" {
+		return "principal:" + principal
+	}
+	return rateLimitClient(r)
+}
+
+// requestFingerprint tells apart the requests reusing a key
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.RequestURI())
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, record idempotency.Record) {
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.Status)
+	_, _ = w.Write(record.Body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// idempotencyRecorder keeps a copy of the response written by the handler.
+// The handler gets its own header map so the headers set by the outer
+// middlewares, like the request ID, aren't replayed.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *idempotencyRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *idempotencyRecorder) WriteHeader(status int) {
+	if w.status == 0 && status >= http.StatusOK {
+		w.status = status
+		for name, values := range w.header {
+			w.ResponseWriter.Header()[name] = values
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *idempotencyRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergiorra/sushi-api-go/pkg/idempotency"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+)
+
+func TestIdempotency(t *testing.T) {
+	s := buildServer(WithIdempotency(idempotency.NewMemoryStore(), time.Minute, 1<<10), WithAPIKeys([]string{"kiosk"}))
+	body := `{"id":"01D3XZ38IDEM","imageNumber":"1","name":"Nigiri","ingredients":["rice"]}`
+	defer func() {
+		req := httptest.NewRequest("DELETE", "/sushi/01D3XZ38IDEM", nil)
+		s.Router().ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	testData := []struct {
+		name     string
+		key      string
+		apiKey   string
+		body     string
+		status   int
+		replayed bool
+	}{
+		{name: "first request", key: "retry-1", body: body, status: http.StatusCreated},
+		{name: "retry", key: "retry-1", body: body, status: http.StatusCreated, replayed: true},
+		{name: "same key with another body", key: "retry-1", body: strings.Replace(body, "Nigiri", "Maki", 1), status: http.StatusUnprocessableEntity},
+		{name: "same key from another client", key: "retry-1", apiKey: "kiosk", body: body, status: http.StatusInternalServerError},
+		{name: "same key with an unknown api key", key: "retry-1", apiKey: "forged", body: body, status: http.StatusCreated, replayed: true},
+		{name: "without key", body: body, status: http.StatusInternalServerError},
+		{name: "key too long", key: strings.Repeat("k", 256), body: body, status: http.StatusBadRequest},
+		{name: "body too large", key: "retry-2", body: strings.Replace(body, "Nigiri", strings.Repeat("n", 1<<10), 1), status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/sushi", strings.NewReader(tt.body))
+			if tt.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tt.key)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set(apiKeyHeader, tt.apiKey)
+			}
+
+			resRecorder := httptest.NewRecorder()
+			s.Router().ServeHTTP(resRecorder, req)
+
+			res := resRecorder.Result()
+			defer res.Body.Close()
+			if tt.status != res.StatusCode {
+				t.Errorf("expected %d, got: %d", tt.status, res.StatusCode)
+			}
+			if replayed := res.Header.Get("Idempotent-Replayed") == "true"; replayed != tt.replayed {
+				t.Errorf("expected replayed %v, got: %v", tt.replayed, replayed)
+			}
+			if res.Header.Get("X-Request-ID") == "" {
+				t.Errorf("expected a request ID")
+			}
+		})
+	}
+}
+
+func TestIdempotencyFailuresAreRetried(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	status := http.StatusInternalServerError
+	h := newIdempotencyMiddleware(store, time.Minute, 1<<10, log.NewNoopLogger())(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+	post := func() int {
+		req := httptest.NewRequest("POST", "/sushi", strings.NewReader("{}"))
+		req.Header.Set(idempotencyKeyHeader, "retry-1")
+		resRecorder := httptest.NewRecorder()
+		h.ServeHTTP(resRecorder, req)
+		return resRecorder.Code
+	}
+
+	if got := post(); got != http.StatusInternalServerError {
+		t.Fatalf("expected %d, got: %d", http.StatusInternalServerError, got)
+	}
+	status = http.StatusCreated
+	if got := post(); got != http.StatusCreated {
+		t.Errorf("expected the failure to be retried, got: %d", got)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	req := httptest.NewRequest("POST", "/sushi", strings.NewReader("{}"))
+	req.Header.Set(idempotencyKeyHeader, "retry-1")
+	key := rateLimitClient(req) + "|retry-1"
+	_, _, _ = store.Reserve(context.Background(), key, requestFingerprint(req, []byte("{}")), time.Minute)
+
+	h := newIdempotencyMiddleware(store, time.Minute, 1<<10, log.NewNoopLogger())(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("the handler mustn't run twice")
+		}))
+	resRecorder := httptest.NewRecorder()
+	h.ServeHTTP(resRecorder, req)
+
+	if resRecorder.Code != http.StatusConflict {
+		t.Errorf("expected %d, got: %d", http.StatusConflict, resRecorder.Code)
+	}
+	if resRecorder.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected Retry-After 1, got: %q", resRecorder.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+type ingredientRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	sushiapi.IngredientInfo
+}
+
+// GetIngredients lists the ingredient catalogue, ordered by ID
+func (s *server) GetIngredients(w http.ResponseWriter, r *http.Request) {
+	ingredients, err := s.ingredients.GetIngredients(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, "Can't list the ingredients")
+		return
+	}
+	writeJSON(w, http.StatusOK, ingredients)
+}
+
+// GetIngredient returns an ingredient of the catalogue
+func (s *server) GetIngredient(w http.ResponseWriter, r *http.Request) {
+	ingredient, err := s.ingredients.GetIngredientByID(r.Context(), mux.Vars(r)["ID"])
+	if err != nil {
+		writeIngredientError(w, err, "Can't read the ingredient")
+		return
+	}
+	writeJSON(w, http.StatusOK, ingredient)
+}
+
+// AddIngredient adds an ingredient to the catalogue
+func (s *server) AddIngredient(w http.ResponseWriter, r *http.Request) {
+	var req ingredientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, "Error unmarshalling request body")
+		return
+	}
+
+	if err := s.ingredients.AddIngredient(r.Context(), req.ID, req.Name, req.IngredientInfo); err != nil {
+		writeIngredientError(w, err, "Can't create an ingredient")
+		return
+	}
+
+	w.Header().Set("Location", "/ingredients/"+req.ID)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// ModifyIngredient modifies an ingredient, the sushis made of it show the
+// change
+func (s *server) ModifyIngredient(w http.ResponseWriter, r *http.Request) {
+	var req ingredientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, "Error unmarshalling request body")
+		return
+	}
+
+	if err := s.ingredients.ModifyIngredient(r.Context(), mux.Vars(r)["ID"], req.Name, req.IngredientInfo); err != nil {
+		writeIngredientError(w, err, "Can't modify an ingredient")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveIngredient removes an ingredient no sushi is made of
+func (s *server) RemoveIngredient(w http.ResponseWriter, r *http.Request) {
+	if err := s.ingredients.RemoveIngredient(r.Context(), mux.Vars(r)["ID"]); err != nil {
+		writeIngredientError(w, err, "Can't remove an ingredient")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeIngredientError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, sushiapi.ErrInvalidIngredient):
+		writeJSON(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, sushiapi.ErrIngredientNotFound):
+		writeJSON(w, http.StatusNotFound, "Ingredient Not found")
+	case errors.Is(err, sushiapi.ErrIngredientInUse):
+		writeJSON(w, http.StatusConflict, err.Error())
+	default:
+		writeJSON(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sergiorra/sushi-api-go/cmd/sample-data"
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/dietary"
+	"github.com/sergiorra/sushi-api-go/pkg/servicetest"
+	"github.com/sergiorra/sushi-api-go/pkg/storage/inmem"
+)
+
+// buildServerWithIngredients serves a copy of the sample sushis, made of the
+// ingredients of the catalogue
+func buildServerWithIngredients() Server {
+	services := servicetest.New(sample.Sushis, servicetest.WithIngredients(inmem.NewIngredientRepository(dietary.Ingredients())))
+	return New("test", services.Getting, services.Adding, services.Modifying, services.Removing,
+		WithIngredients(services.Ingredients))
+}
+
+func TestIngredients(t *testing.T) {
+	s := buildServerWithIngredients()
+	serve := func(method, uri, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, uri, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		s.Router().ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		uri    string
+		body   string
+		status int
+		err    string
+	}{
+		{name: "add", method: "POST", uri: "/ingredients", body: `{"id":"kombu","name":"Kombu","vegetarian":true,"vegan":true}`, status: http.StatusCreated},
+		{name: "add a taken id", method: "POST", uri: "/ingredients", body: `{"id":"kombu","name":"Dried kelp"}`, status: http.StatusBadRequest, err: `id "kombu" is taken`},
+		{name: "add a taken name", method: "POST", uri: "/ingredients", body: `{"id":"kelp","name":"KOMBU"}`, status: http.StatusBadRequest, err: `name "KOMBU" is taken by kombu`},
+		{name: "add an unknown allergen", method: "POST", uri: "/ingredients", body: `{"id":"kelp","name":"Kelp","allergens":["iodine"]}`, status: http.StatusBadRequest, err: `unknown allergen "iodine"`},
+		{name: "get", method: "GET", uri: "/ingredients/kombu", status: http.StatusOK},
+		{name: "get a missing one", method: "GET", uri: "/ingredients/kelp", status: http.StatusNotFound, err: "Ingredient Not found"},
+		{name: "add a sushi of the catalogue", method: "POST", uri: "/sushi", body: `{"id":"01D3XZ38KMB","name":"Kombu maki","ingredients":["rice","kombu"]}`, status: http.StatusCreated},
+		{name: "add a sushi of unknown ingredients", method: "POST", uri: "/sushi", body: `{"id":"01D3XZ38WKM","name":"Wakame maki","ingredients":["rice","wakame"]}`, status: http.StatusBadRequest, err: `ingredient "wakame" isn't in the catalogue`},
+		{name: "modify a sushi with unknown ingredients", method: "PUT", uri: "/sushi/01D3XZ38KMB", body: `{"name":"Kombu maki","ingredients":["Rice"]}`, status: http.StatusBadRequest, err: `ingredient "Rice" isn't in the catalogue`},
+		{name: "rename", method: "PUT", uri: "/ingredients/kombu", body: `{"name":"Kelp","vegetarian":true,"vegan":true}`, status: http.StatusNoContent},
+		{name: "modify a missing one", method: "PUT", uri: "/ingredients/kelp", body: `{"name":"Kelp"}`, status: http.StatusNotFound},
+		{name: "remove one in use", method: "DELETE", uri: "/ingredients/kombu", status: http.StatusConflict, err: "kombu is an ingredient of 01D3XZ38KMB"},
+		{name: "remove the sushi", method: "DELETE", uri: "/sushi/01D3XZ38KMB", status: http.StatusNoContent},
+		{name: "remove one no longer in use", method: "DELETE", uri: "/ingredients/kombu", status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		res := serve(tt.method, tt.uri, tt.body)
+		if res.Code != tt.status {
+			t.Fatalf("%s: expected %d, got: %d %s", tt.name, tt.status, res.Code, res.Body)
+		}
+		if tt.err == "" {
+			continue
+		}
+		var message string
+		if err := json.Unmarshal(res.Body.Bytes(), &message); err != nil {
+			t.Fatalf("%s: could not unmarshall response %v", tt.name, err)
+		}
+		if !strings.Contains(message, tt.err) {
+			t.Errorf("%s: expected the error %s, got: %s", tt.name, tt.err, message)
+		}
+	}
+}
+
+func TestIngredientsRename(t *testing.T) {
+	s := buildServerWithIngredients()
+	serve := func(method, uri, body string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, uri, strings.NewReader(body))
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		resRecorder := httptest.NewRecorder()
+		s.Router().ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	res := serve("GET", "/sushi", "", nil)
+	etag := res.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	res = serve("PUT", "/ingredients/avocado", `{"name":"Hass avocado","allergens":["sulphites"],"vegetarian":true,"vegan":true}`, nil)
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected %d, got: %d %s", http.StatusNoContent, res.Code, res.Body)
+	}
+
+	res = serve("GET", "/sushi", "", http.Header{"If-None-Match": {etag}})
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected the labels to change the version, got: %d", res.Code)
+	}
+
+	res = serve("GET", "/ingredients/avocado", "", nil)
+	var avocado sushi.Ingredient
+	if err := json.Unmarshal(res.Body.Bytes(), &avocado); err != nil {
+		t.Fatalf("could not unmarshall response %v", err)
+	}
+	if avocado.Name != "Hass avocado" {
+		t.Errorf("expected the new name, got: %s", avocado.Name)
+	}
+
+	res = serve("GET", "/sushi/01D3XZ38KDR", "", nil)
+	var california sushi.Sushi
+	if err := json.Unmarshal(res.Body.Bytes(), &california); err != nil {
+		t.Fatalf("could not unmarshall response %v", err)
+	}
+	if expected := "[crustaceans sesame sulphites]"; fmt.Sprint(california.Labels.Allergens) != expected {
+		t.Errorf("expected allergens %s, got: %v", expected, california.Labels.Allergens)
+	}
+}
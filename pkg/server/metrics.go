@@ -0,0 +1,21 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sergiorra/sushi-api-go/pkg/metrics"
+)
+
+func newMetricsMiddleware(m *metrics.HTTP) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rec, r)
+
+			m.Observe(r.Method, routePath(r), rec.statusCode(), time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetrics(t *testing.T) {
+	s := buildServer(WithMetrics(prometheus.NewRegistry()))
+
+	resRecorder := httptest.NewRecorder()
+	s.Router().ServeHTTP(resRecorder, httptest.NewRequest("GET", "/sushi/01D3XZ38KDR", nil))
+
+	resRecorder = httptest.NewRecorder()
+	s.Router().ServeHTTP(resRecorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	res := resRecorder.Result()
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, res.StatusCode)
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("could not read response: %v", err)
+	}
+
+	expected := `sushiapi_http_requests_total{method="GET",route="/sushi/{ID}",status="200"} 1`
+	if !strings.Contains(string(b), expected) {
+		t.Errorf("expected metrics to contain %s, got:\n%s", expected, b)
+	}
+}
@@ -2,28 +2,47 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"net"
 	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
 )
 
 type handler struct {
-	serverID string
-	next     http.Handler
+	serverID       string
+	trustedProxies []*net.IPNet
+	apiKeys        apiKeys
+	next           http.Handler
 }
 
-func newServerMiddleware(serverID string) func(http.Handler) http.Handler {
+func newServerMiddleware(serverID string, trustedProxies []*net.IPNet, apiKeys apiKeys) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		h := &handler{
-			serverID: serverID,
-			next:     next,
+			serverID:       serverID,
+			trustedProxies: trustedProxies,
+			apiKeys:        apiKeys,
+			next:           next,
 		}
 		return h
 	}
 }
 
+const (
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+	apiKeyHeader       = "X-API-Key"
+)
+
 // ServeHTTP implements http.Handler.
 func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := h.createRequestContext(r)
+	requestID, _ := RequestID(ctx)
+	w.Header().Set(requestIDHeader, requestID)
 	h.next.ServeHTTP(w, r.WithContext(ctx))
 }
 
@@ -42,11 +61,125 @@ func (h handler) createRequestContext(req *http.Request) context.Context {
 		ctx = context.WithValue(ctx, contextKeyXForwardedProto, xForwardedProto)
 	}
 
-	ip, _, _ := net.SplitHostPort(req.RemoteAddr)
-	ctx = context.WithValue(ctx, contextKeyClientIP, ip)
+	ctx = context.WithValue(ctx, contextKeyClientIP, h.clientIP(req.RemoteAddr, xForwardedFor))
 	ctx = context.WithValue(ctx, contextKeyEndpoint, req.URL.RequestURI())
+	ctx = context.WithValue(ctx, contextKeyRequestID, requestID(req.Header.Get(requestIDHeader)))
+
+	// a verified client certificate is a stronger identity than an API key
+	if req.TLS != nil && len(req.TLS.VerifiedChains) > 0 {
+		subject := req.TLS.VerifiedChains[0][0].Subject.String()
+		ctx = context.WithValue(ctx, contextKeyClientCert, subject)
+		ctx = context.WithValue(ctx, contextKeyPrincipal, "cert:"+subject)
+	} else if apiKey := req.Header.Get(apiKeyHeader); h.apiKeys.verify(apiKey) {
+		ctx = context.WithValue(ctx, contextKeyPrincipal, apiKeyPrincipal(apiKey))
+	}
 
 	ctx = context.WithValue(ctx, contextKeyServerID, h.serverID)
 
 	return ctx
-}
\ No newline at end of file
+}
+
+// requestID propagates the identifier sent by the caller when it is sane,
+// otherwise a new one is generated
+func requestID(received string) string {
+	if received != "" && len(received) <= maxRequestIDLength && isPrintable(received) {
+		return received
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func isPrintable(s string) bool {
+	for _, c := range s {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// apiKeys are the digests of the API keys identifying the callers, so the
+// lookups take the same time whatever key is sent
+type apiKeys map[[sha256.Size]byte]bool
+
+func newAPIKeys(keys []string) apiKeys {
+	digests := make(apiKeys, len(keys))
+	for _, key := range keys {
+		digests[sha256.Sum256([]byte(key))] = true
+	}
+	return digests
+}
+
+// verify tells whether the key is one of the configured ones
+func (k apiKeys) verify(key string) bool {
+	return key != "" && k[sha256.Sum256([]byte(key))]
+}
+
+// apiKeyPrincipal identifies an API key without leaking it to the logs
+func apiKeyPrincipal(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "apikey:" + hex.EncodeToString(sum[:4])
+}
+
+// clientIP returns the address of the peer, unless the peer is a trusted proxy.
+// In that case X-Forwarded-For is walked from right to left and the first hop
+// not belonging to a trusted proxy is returned.
+func (h handler) clientIP(remoteAddr, xForwardedFor string) string {
+	ip, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		ip = remoteAddr
+	}
+	if !h.isTrustedProxy(ip) || xForwardedFor == "" {
+		return ip
+	}
+
+	hops := strings.Split(xForwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !h.isTrustedProxy(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func (h handler) isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range h.trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+var routeVarPattern = regexp.MustCompile(`\{([^:}]+):[^}]+\}`)
+
+// routeTemplate identifies the matched route as "METHOD /path/{Var}"
+func routeTemplate(r *http.Request) string {
+	return r.Method + " " + routePath(r)
+}
+
+// routePath returns the path template of the matched route without the
+// variable patterns, or the request path when no route matched
+func routePath(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return r.URL.Path
+	}
+	tpl, err := route.GetPathTemplate()
+	if err != nil {
+		return r.URL.Path
+	}
+	return routeVarPattern.ReplaceAllString(tpl, "{$1}")
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sergiorra/sushi-api-go/pkg/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	policy, _ := ratelimit.ParsePolicy(ratelimit.Limit{}, "GET /sushi/{ID}=1:1")
+	s := buildServer(WithRateLimit(ratelimit.NewMemoryStore(), policy), WithAPIKeys([]string{"kiosk"}))
+
+	testData := []struct {
+		name   string
+		uri    string
+		apiKey string
+		status int
+	}{
+		{name: "first request", uri: "/sushi/01D3XZ38KDR", status: http.StatusOK},
+		{name: "bucket exhausted", uri: "/sushi/01D3XZ38KDR", status: http.StatusTooManyRequests},
+		{name: "route without limit", uri: "/sushi", status: http.StatusOK},
+		{name: "unknown api key", uri: "/sushi/01D3XZ38KDR", apiKey: "forged", status: http.StatusTooManyRequests},
+		{name: "other client", uri: "/sushi/01D3XZ38KDR", apiKey: "kiosk", status: http.StatusOK},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.uri, nil)
+			if tt.apiKey != "" {
+				req.Header.Set(apiKeyHeader, tt.apiKey)
+			}
+
+			resRecorder := httptest.NewRecorder()
+			s.Router().ServeHTTP(resRecorder, req)
+
+			res := resRecorder.Result()
+			defer res.Body.Close()
+			if tt.status != res.StatusCode {
+				t.Errorf("expected %d, got: %d", tt.status, res.StatusCode)
+			}
+			if tt.status == http.StatusTooManyRequests && res.Header.Get("Retry-After") != "1" {
+				t.Errorf("expected Retry-After 1, got: %q", res.Header.Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	h := handler{trustedProxies: []*net.IPNet{proxies}}
+
+	testData := []struct {
+		name          string
+		remoteAddr    string
+		xForwardedFor string
+		expected      string
+	}{
+		{name: "direct client", remoteAddr: "192.0.2.1:1234", expected: "192.0.2.1"},
+		{name: "spoofed header from untrusted peer", remoteAddr: "192.0.2.1:1234", xForwardedFor: "198.51.100.7", expected: "192.0.2.1"},
+		{name: "trusted proxy", remoteAddr: "10.0.0.1:1234", xForwardedFor: "198.51.100.7", expected: "198.51.100.7"},
+		{name: "chain of proxies", remoteAddr: "10.0.0.1:1234", xForwardedFor: "203.0.113.9, 198.51.100.7, 10.0.0.2", expected: "198.51.100.7"},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.clientIP(tt.remoteAddr, tt.xForwardedFor); got != tt.expected {
+				t.Errorf("expected %s, got: %s", tt.expected, got)
+			}
+		})
+	}
+}
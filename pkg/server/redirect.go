@@ -0,0 +1,24 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// RedirectToHTTPS answers plain HTTP requests with a permanent redirect to
+// the same URL served over HTTPS on the given port
+func RedirectToHTTPS(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
@@ -2,13 +2,33 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
 	"github.com/sergiorra/sushi-api-go/pkg/adding"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
 	"github.com/sergiorra/sushi-api-go/pkg/getting"
+	"github.com/sergiorra/sushi-api-go/pkg/graphql"
+	"github.com/sergiorra/sushi-api-go/pkg/idempotency"
+	"github.com/sergiorra/sushi-api-go/pkg/ingredients"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/metrics"
 	"github.com/sergiorra/sushi-api-go/pkg/modifying"
+	"github.com/sergiorra/sushi-api-go/pkg/ratelimit"
 	"github.com/sergiorra/sushi-api-go/pkg/removing"
+	"github.com/sergiorra/sushi-api-go/pkg/webhook"
 )
 
 type server struct {
@@ -20,6 +40,34 @@ type server struct {
 	modifying  modifying.Service
 	adding  adding.Service
 	removing  removing.Service
+
+	logger          log.Logger
+	trustedProxies  []*net.IPNet
+	apiKeys         apiKeys
+	rateLimitStore  ratelimit.Store
+	rateLimitPolicy ratelimit.Policy
+	metrics         *metrics.HTTP
+	metricsHandler  http.Handler
+	readinessChecks map[string]sushiapi.Pinger
+	draining        int32
+	compression     bool
+	compressionMin  int
+	cors            *CORSPolicy
+	cacheControl    CacheControlPolicy
+	idempotency     idempotency.Store
+	idempotencyTTL  time.Duration
+	idempotencyBody int64
+	webhooks        webhook.Service
+	eventBus        *events.Bus
+	eventHeartbeat  time.Duration
+	wsBus           *events.Bus
+	wsHub           *wsHub
+	upgrader        websocket.Upgrader
+	locker          locking.Locker
+	lockTTL         time.Duration
+	graphql         *graphql.Schema
+	graphqlUpgrader websocket.Upgrader
+	ingredients     ingredients.Service
 }
 
 type Server interface {
@@ -29,10 +77,160 @@ type Server interface {
 	AddSushi(w http.ResponseWriter, r *http.Request)
 	ModifySushi(w http.ResponseWriter, r *http.Request)
 	RemoveSushi(w http.ResponseWriter, r *http.Request)
+	Subscribe(w http.ResponseWriter, r *http.Request)
+	Subscriptions(w http.ResponseWriter, r *http.Request)
+	Subscription(w http.ResponseWriter, r *http.Request)
+	Unsubscribe(w http.ResponseWriter, r *http.Request)
+	Deliveries(w http.ResponseWriter, r *http.Request)
+	DeadLetters(w http.ResponseWriter, r *http.Request)
+	SushiEvents(w http.ResponseWriter, r *http.Request)
+	SushiSocket(w http.ResponseWriter, r *http.Request)
+	GraphQL(w http.ResponseWriter, r *http.Request)
+	GetIngredients(w http.ResponseWriter, r *http.Request)
+	GetIngredient(w http.ResponseWriter, r *http.Request)
+	AddIngredient(w http.ResponseWriter, r *http.Request)
+	ModifyIngredient(w http.ResponseWriter, r *http.Request)
+	RemoveIngredient(w http.ResponseWriter, r *http.Request)
+	Liveness(w http.ResponseWriter, r *http.Request)
+	Readiness(w http.ResponseWriter, r *http.Request)
+	SetReady(ready bool)
+}
+
+// Option configures optional features of the server
+type Option func(*server)
+
+// WithLogger sets the logger used for the access log
+func WithLogger(logger log.Logger) Option {
+	return func(s *server) {
+		s.logger = logger
+	}
+}
+
+// WithTrustedProxies makes the server honour X-Forwarded-For when the peer
+// belongs to one of the given networks
+func WithTrustedProxies(networks []*net.IPNet) Option {
+	return func(s *server) {
+		s.trustedProxies = networks
+	}
+}
+
+// WithAPIKeys identifies the callers sending one of the keys in X-API-Key,
+// the other keys are ignored
+func WithAPIKeys(keys []string) Option {
+	return func(s *server) {
+		s.apiKeys = newAPIKeys(keys)
+	}
+}
+
+// WithRateLimit limits the requests of every client per route using the given store
+func WithRateLimit(store ratelimit.Store, policy ratelimit.Policy) Option {
+	return func(s *server) {
+		s.rateLimitStore = store
+		s.rateLimitPolicy = policy
+	}
+}
+
+// WithMetrics records the HTTP metrics in the given registry and exposes
+// everything registered there on /metrics
+func WithMetrics(registry *prometheus.Registry) Option {
+	return func(s *server) {
+		s.metrics = metrics.NewHTTP(registry)
+		s.metricsHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+	}
+}
+
+// WithReadinessCheck makes /readyz fail while the given dependency can't be reached
+func WithReadinessCheck(name string, pinger sushiapi.Pinger) Option {
+	return func(s *server) {
+		if s.readinessChecks == nil {
+			s.readinessChecks = make(map[string]sushiapi.Pinger)
+		}
+		s.readinessChecks[name] = pinger
+	}
+}
+
+// WithCompression compresses with zstd, brotli or gzip, as negotiated with
+// Accept-Encoding, the responses of at least minSize bytes
+func WithCompression(minSize int) Option {
+	return func(s *server) {
+		s.compression = true
+		s.compressionMin = minSize
+	}
+}
+
+// WithCORS lets the browsers of the origins allowed by the policy call the API
+func WithCORS(policy CORSPolicy) Option {
+	return func(s *server) {
+		s.cors = &policy
+	}
+}
+
+// WithCacheControl sets the Cache-Control header of the successful responses
+// of the routes listed by the policy
+func WithCacheControl(policy CacheControlPolicy) Option {
+	return func(s *server) {
+		s.cacheControl = policy
+	}
+}
+
+// WithIdempotency replays for ttl the first response of the mutating requests
+// carrying an Idempotency-Key to the retries of the same client. Their bodies
+// are kept to tell the retries apart, larger ones than maxBody are refused.
+func WithIdempotency(store idempotency.Store, ttl time.Duration, maxBody int64) Option {
+	return func(s *server) {
+		s.idempotency = store
+		s.idempotencyTTL = ttl
+		s.idempotencyBody = maxBody
+	}
+}
+
+// WithWebhooks exposes the management of the webhooks under /webhooks
+func WithWebhooks(service webhook.Service) Option {
+	return func(s *server) {
+		s.webhooks = service
+	}
+}
+
+// WithEventStream streams the events of the bus on /sushi/events, sending a
+// heartbeat when nothing happened for the given interval
+func WithEventStream(bus *events.Bus, heartbeat time.Duration) Option {
+	return func(s *server) {
+		s.eventBus = bus
+		s.eventHeartbeat = heartbeat
+	}
+}
+
+// WithWebSocket lets the menu editors follow the events of the bus and lock
+// the sushis for at most lockTTL on /sushi/ws. The locker must be the one the
+// modifying service checks.
+func WithWebSocket(bus *events.Bus, locker locking.Locker, lockTTL time.Duration) Option {
+	return func(s *server) {
+		s.wsBus = bus
+		s.locker = locker
+		s.lockTTL = lockTTL
+	}
+}
+
+// WithGraphQL serves the schema on /graphql, the subscriptions over a
+// websocket speaking graphql-transport-ws
+func WithGraphQL(schema *graphql.Schema) Option {
+	return func(s *server) {
+		s.graphql = schema
+	}
+}
+
+// WithIngredients manages the ingredient catalogue under /ingredients
+func WithIngredients(service ingredients.Service) Option {
+	return func(s *server) {
+		s.ingredients = service
+	}
 }
 
-func New(serverID string, gS getting.Service, aS adding.Service, mS modifying.Service, rS removing.Service) Server {
-	a := &server{serverID: serverID, getting: gS, adding: aS, modifying: mS, removing: rS}
+func New(serverID string, gS getting.Service, aS adding.Service, mS modifying.Service, rS removing.Service, opts ...Option) Server {
+	a := &server{serverID: serverID, getting: gS, adding: aS, modifying: mS, removing: rS, logger: log.NewNoopLogger()}
+	for _, opt := range opts {
+		opt(a)
+	}
 	router(a)
 	return a
 }
@@ -40,25 +238,177 @@ func New(serverID string, gS getting.Service, aS adding.Service, mS modifying.Se
 func router(s *server) {
 	r := mux.NewRouter()
 
-	r.Use(newServerMiddleware(s.serverID))
+	// operational endpoints skip the API middlewares, probes and scrapes must
+	// never be throttled nor flood the access log
+	r.HandleFunc("/healthz", s.Liveness).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", s.Readiness).Methods(http.MethodGet)
+	if s.metricsHandler != nil {
+		r.Handle("/metrics", s.metricsHandler).Methods(http.MethodGet)
+	}
+
+	api := r.NewRoute().Subrouter()
+	api.Use(newServerMiddleware(s.serverID, s.trustedProxies, s.apiKeys))
+	api.Use(newTracingMiddleware())
+	api.Use(newAccessLogMiddleware(s.logger))
+	if s.metrics != nil {
+		api.Use(newMetricsMiddleware(s.metrics))
+	}
+	if s.rateLimitStore != nil {
+		api.Use(newRateLimitMiddleware(s.rateLimitStore, s.rateLimitPolicy))
+	}
+	if len(s.cacheControl) > 0 {
+		api.Use(newCacheControlMiddleware(s.cacheControl))
+	}
+	// innermost, so the access log and the metrics count the bytes on the wire
+	if s.compression {
+		api.Use(newCompressionMiddleware(s.compressionMin))
+	}
+	// inside the compression, the responses are stored as the handlers wrote them
+	if s.idempotency != nil {
+		api.Use(newIdempotencyMiddleware(s.idempotency, s.idempotencyTTL, s.idempotencyBody, s.logger))
+	}
+
+	// mux skips the middlewares for unmatched requests, they are logged anyway
+	r.NotFoundHandler = newServerMiddleware(s.serverID, s.trustedProxies, s.apiKeys)(
+		newAccessLogMiddleware(s.logger)(http.NotFoundHandler()))
+	r.MethodNotAllowedHandler = newServerMiddleware(s.serverID, s.trustedProxies, s.apiKeys)(
+		newAccessLogMiddleware(s.logger)(methodNotAllowedHandler()))
 
-	r.HandleFunc("/sushi", s.GetSushis).Methods(http.MethodGet)
-	r.HandleFunc("/sushi/{ID:[a-zA-Z0-9_]+}", s.GetSushi).Methods(http.MethodGet)
-	r.HandleFunc("/sushi", s.AddSushi).Methods(http.MethodPost)
-	r.HandleFunc("/sushi/{ID:[a-zA-Z0-9_]+}", s.ModifySushi).Methods(http.MethodPut)
-	r.HandleFunc("/sushi/{ID:[a-zA-Z0-9_]+}", s.RemoveSushi).Methods(http.MethodDelete)
+	api.HandleFunc("/sushi", s.GetSushis).Methods(http.MethodGet)
+	// before /sushi/{ID}, events and ws would be taken for sushi IDs
+	if s.eventBus != nil {
+		api.HandleFunc("/sushi/events", s.SushiEvents).Methods(http.MethodGet)
+	}
+	if s.wsBus != nil {
+		s.wsHub = &wsHub{conns: make(map[*wsConn]struct{})}
+		s.upgrader = websocket.Upgrader{CheckOrigin: s.checkOrigin}
+		api.HandleFunc("/sushi/ws", s.SushiSocket).Methods(http.MethodGet)
+	}
+	api.HandleFunc("/sushi/{ID:[a-zA-Z0-9_]+}", s.GetSushi).Methods(http.MethodGet)
+	api.HandleFunc("/sushi", s.AddSushi).Methods(http.MethodPost)
+	api.HandleFunc("/sushi/{ID:[a-zA-Z0-9_]+}", s.ModifySushi).Methods(http.MethodPut)
+	api.HandleFunc("/sushi/{ID:[a-zA-Z0-9_]+}", s.RemoveSushi).Methods(http.MethodDelete)
+	if s.webhooks != nil {
+		api.HandleFunc("/webhooks", s.Subscribe).Methods(http.MethodPost)
+		api.HandleFunc("/webhooks", s.Subscriptions).Methods(http.MethodGet)
+		api.HandleFunc("/webhooks/dead-letters", s.DeadLetters).Methods(http.MethodGet)
+		api.HandleFunc("/webhooks/{ID:[a-zA-Z0-9_]+}", s.Subscription).Methods(http.MethodGet)
+		api.HandleFunc("/webhooks/{ID:[a-zA-Z0-9_]+}", s.Unsubscribe).Methods(http.MethodDelete)
+		api.HandleFunc("/webhooks/{ID:[a-zA-Z0-9_]+}/deliveries", s.Deliveries).Methods(http.MethodGet)
+	}
+	if s.ingredients != nil {
+		api.HandleFunc("/ingredients", s.GetIngredients).Methods(http.MethodGet)
+		api.HandleFunc("/ingredients", s.AddIngredient).Methods(http.MethodPost)
+		api.HandleFunc("/ingredients/{ID:[a-zA-Z0-9_]+}", s.GetIngredient).Methods(http.MethodGet)
+		api.HandleFunc("/ingredients/{ID:[a-zA-Z0-9_]+}", s.ModifyIngredient).Methods(http.MethodPut)
+		api.HandleFunc("/ingredients/{ID:[a-zA-Z0-9_]+}", s.RemoveIngredient).Methods(http.MethodDelete)
+	}
+	if s.graphql != nil {
+		s.graphqlUpgrader = websocket.Upgrader{CheckOrigin: s.checkOrigin, Subprotocols: []string{graphqlWSProtocol}}
+		api.HandleFunc("/graphql", s.GraphQL).Methods(http.MethodGet, http.MethodPost)
+	}
 
 	s.router = r
+	if s.cors != nil {
+		s.router = newCORSHandler(*s.cors, r)
+	}
+}
+
+func methodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
 }
 
 func (s *server) Router() http.Handler {
 	return s.router
 }
 
+// GetSushis streams the sushis as a JSON array, or as NDJSON when the client
+// accepts application/x-ndjson. The catalogue isn't read when the client copy
+// is still fresh.
+//
+// The priceMin and priceMax query parameters bound the price in minor units of
+// the currency they require, currency keeps the sushis priced in a currency and
+// restaurant picks the prices of a restaurant. The sushis are labelled with
+// their allergens and diets, excludeAllergens leaves out those containing any
+// of the allergens and diet keeps those labelled with all the diets, both
+// separated by commas.
 func (s *server) GetSushis(w http.ResponseWriter, r *http.Request) {
-	sushis, _ := s.getting.GetSushis(r.Context())
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(sushis)
+	w.Header().Add("Vary", "Accept")
+	filter, err := parseFilter(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(err.Error())
+		return
+	}
+
+	if version, err := s.getting.Version(r.Context()); err == nil {
+		tag := version.Tag
+		if accepts(r, ndjsonContentType) {
+			tag += "-ndjson"
+		}
+		if notModified(w, r, weakETag(tag), version.Modified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	streamSushis(w, r, s.getting.StreamSushis(r.Context(), filter))
+}
+
+// parseFilter reads the filter of the sushis listed from the query
+func parseFilter(r *http.Request) (getting.Filter, error) {
+	query := r.URL.Query()
+	filter := getting.Filter{
+		Currency:   query.Get("currency"),
+		Restaurant: query.Get("restaurant"),
+	}
+	bounds := []struct {
+		name  string
+		bound **int64
+	}{{"priceMin", &filter.PriceMin}, {"priceMax", &filter.PriceMax}}
+	for _, b := range bounds {
+		value := query.Get(b.name)
+		if value == "" {
+			continue
+		}
+		amount, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("%w: %s must be an amount in minor units", getting.ErrInvalidFilter, b.name)
+		}
+		*b.bound = &amount
+	}
+	for _, name := range listParam(query, "excludeAllergens") {
+		allergen, err := sushiapi.ParseAllergen(name)
+		if err != nil {
+			return filter, fmt.Errorf("%w: %v", getting.ErrInvalidFilter, err)
+		}
+		filter.ExcludeAllergens = append(filter.ExcludeAllergens, allergen)
+	}
+	for _, name := range listParam(query, "diet") {
+		diet, err := sushiapi.ParseDiet(name)
+		if err != nil {
+			return filter, fmt.Errorf("%w: %v", getting.ErrInvalidFilter, err)
+		}
+		filter.Diets = append(filter.Diets, diet)
+	}
+	return filter, filter.Validate()
+}
+
+// listParam returns the values of the query parameter, repeated or separated
+// by commas
+func listParam(query url.Values, name string) []string {
+	var values []string
+	for _, value := range query[name] {
+		for v := range strings.SplitSeq(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
 }
 
 func (s *server) GetSushi(w http.ResponseWriter, r *http.Request) {
@@ -71,7 +421,16 @@ func (s *server) GetSushi(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	json.NewEncoder(w).Encode(sushi)
+	body, err := json.Marshal(sushi)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if notModified(w, r, contentETag(body), sushi.LastModified()) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	_, _ = w.Write(append(body, '\n'))
 }
 
 type addSushiRequest struct {
@@ -79,6 +438,7 @@ type addSushiRequest struct {
 	ImageNumber  	string 		`json:"imageNumber"`
 	Name 			string 		`json:"name"`
 	Ingredients   	[]string  	`json:"ingredients"`
+	sushiapi.Pricing
 }
 
 // AddSushi save a sushi
@@ -95,7 +455,12 @@ func (s *server) AddSushi(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.adding.AddSushi(r.Context(), sushi.ID, sushi.ImageNumber, sushi.Name, sushi.Ingredients); err != nil {
+	if err := s.adding.AddSushi(r.Context(), sushi.ID, sushi.ImageNumber, sushi.Name, sushi.Ingredients, sushi.Pricing); err != nil {
+		if errors.Is(err, sushiapi.ErrInvalidSushi) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(err.Error())
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode("Can't create a sushi")
 		return
@@ -108,6 +473,7 @@ type modifySushiRequest struct {
 	ImageNumber  	string 		`json:"imageNumber"`
 	Name 			string 		`json:"name"`
 	Ingredients   	[]string  	`json:"ingredients"`
+	sushiapi.Pricing
 }
 
 // ModifySushi modify sushi data
@@ -124,7 +490,18 @@ func (s *server) ModifySushi(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	vars := mux.Vars(r)
-	if err := s.modifying.ModifySushi(r.Context(), vars["ID"], sushi.ImageNumber, sushi.Name, sushi.Ingredients); err != nil {
+	ctx := locking.WithToken(r.Context(), r.Header.Get(lockTokenHeader))
+	if err := s.modifying.ModifySushi(ctx, vars["ID"], sushi.ImageNumber, sushi.Name, sushi.Ingredients, sushi.Pricing); err != nil {
+		if errors.Is(err, sushiapi.ErrInvalidSushi) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(err.Error())
+			return
+		}
+		if errors.Is(err, locking.ErrLocked) {
+			w.WriteHeader(http.StatusLocked)
+			json.NewEncoder(w).Encode(err.Error())
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode("Can't modify a sushi")
 		return
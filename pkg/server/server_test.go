@@ -11,7 +11,9 @@ import (
 	"testing"
 
 	"github.com/sergiorra/sushi-api-go/pkg/adding"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
 	"github.com/sergiorra/sushi-api-go/pkg/getting"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
 	"github.com/sergiorra/sushi-api-go/pkg/modifying"
 	"github.com/sergiorra/sushi-api-go/pkg/removing"
 
@@ -55,6 +57,91 @@ func TestGetSushis(t *testing.T) {
 	}
 }
 
+func TestGetSushisFilter(t *testing.T) {
+	testData := []struct {
+		name   string
+		query  string
+		status int
+		IDs    []string
+	}{
+		{name: "price range", query: "priceMin=900&priceMax=1100&currency=EUR", status: http.StatusOK, IDs: []string{"01D3XZ38KLE"}},
+		{name: "restaurant prices", query: "restaurant=tokyo&currency=JPY&priceMax=1200", status: http.StatusOK, IDs: []string{"01D3XZ38KDR"}},
+		{name: "restaurant falls back to the base price", query: "restaurant=tokyo&currency=EUR", status: http.StatusOK, IDs: []string{"01D3XZ38TRE"}},
+		{name: "unknown currency", query: "currency=USD", status: http.StatusOK, IDs: []string{}},
+		{name: "invalid amount", query: "priceMin=8.50", status: http.StatusBadRequest},
+		{name: "inverted range", query: "priceMin=1000&priceMax=900", status: http.StatusBadRequest},
+		{name: "price bounds without currency", query: "priceMax=1000", status: http.StatusBadRequest},
+		{name: "excluded allergen", query: "excludeAllergens=crustaceans", status: http.StatusOK, IDs: []string{"01D3XZ38KLE"}},
+		{name: "diet", query: "diet=gluten-free", status: http.StatusOK, IDs: []string{"01D3XZ38KDR"}},
+		{name: "repeated and comma separated", query: "excludeAllergens=mustard,sesame&diet=raw-fish", status: http.StatusOK, IDs: []string{"01D3XZ38TRE"}},
+		{name: "no sushi satisfies the diet", query: "diet=vegetarian", status: http.StatusOK, IDs: []string{}},
+		{name: "labels and prices", query: "excludeAllergens=crustaceans&priceMax=1000&currency=EUR", status: http.StatusOK, IDs: []string{}},
+		{name: "unknown allergen", query: "excludeAllergens=onions", status: http.StatusBadRequest},
+		{name: "unknown diet", query: "diet=pescatarian", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/sushi?"+tt.query, nil)
+			if err != nil {
+				t.Fatalf("could not created request: %v", err)
+			}
+			resRecorder := httptest.NewRecorder()
+			buildServer().Router().ServeHTTP(resRecorder, req)
+
+			res := resRecorder.Result()
+			defer res.Body.Close()
+			if res.StatusCode != tt.status {
+				t.Fatalf("expected %d, got: %d", tt.status, res.StatusCode)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var got []sushi.Sushi
+			if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+				t.Fatalf("could not unmarshall response %v", err)
+			}
+			IDs := []string{}
+			for _, s := range got {
+				IDs = append(IDs, s.ID)
+			}
+			if fmt.Sprint(IDs) != fmt.Sprint(tt.IDs) {
+				t.Errorf("expected %v, got: %v", tt.IDs, IDs)
+			}
+		})
+	}
+}
+
+func TestGetSushiLabels(t *testing.T) {
+	req, err := http.NewRequest("GET", "/sushi/01D3XZ38TRE", nil)
+	if err != nil {
+		t.Fatalf("could not created request: %v", err)
+	}
+	resRecorder := httptest.NewRecorder()
+	buildServer().Router().ServeHTTP(resRecorder, req)
+
+	res := resRecorder.Result()
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, res.StatusCode)
+	}
+
+	var got sushi.Sushi
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+		t.Fatalf("could not unmarshall response %v", err)
+	}
+	if got.Labels == nil {
+		t.Fatal("expected the sushi to be labelled")
+	}
+	if expected := "[gluten crustaceans eggs fish]"; fmt.Sprint(got.Labels.Allergens) != expected {
+		t.Errorf("expected allergens %s, got: %v", expected, got.Labels.Allergens)
+	}
+	if expected := "[raw-fish]"; fmt.Sprint(got.Labels.Diets) != expected {
+		t.Errorf("expected diets %s, got: %v", expected, got.Labels.Diets)
+	}
+}
+
 func TestGetSushi(t *testing.T) {
 
 	testData := []struct {
@@ -130,6 +217,43 @@ func TestAddSushi(t *testing.T) {
 	}
 }
 
+func TestAddSushiPrices(t *testing.T) {
+	bodyJSON := []byte(`{
+        "id": "01D3XZ38PRC",
+        "name": "Dragon Roll",
+        "price": {"amount": 1300, "currency": "EUR"},
+        "prices": {"tokyo": {"amount": 1800, "currency": "JPY"}}
+    }`)
+	s := buildServer()
+	// the sample menu is shared by the tests
+	defer s.Router().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/sushi/01D3XZ38PRC", nil))
+	resRecorder := httptest.NewRecorder()
+	s.Router().ServeHTTP(resRecorder, httptest.NewRequest("POST", "/sushi", bytes.NewBuffer(bodyJSON)))
+	if resRecorder.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got: %d", http.StatusCreated, resRecorder.Code)
+	}
+
+	resRecorder = httptest.NewRecorder()
+	s.Router().ServeHTTP(resRecorder, httptest.NewRequest("GET", "/sushi/01D3XZ38PRC", nil))
+	var got sushi.Sushi
+	if err := json.NewDecoder(resRecorder.Body).Decode(&got); err != nil {
+		t.Fatalf("could not unmarshall response %v", err)
+	}
+	if price := got.PriceAt("tokyo"); price == nil || *price != (sushi.Money{Amount: 1800, Currency: "JPY"}) {
+		t.Errorf("expected 1800 JPY in tokyo, got: %v", price)
+	}
+	if got.Price == nil || got.Price.String() != "13.00 EUR" {
+		t.Errorf("expected 13.00 EUR, got: %v", got.Price)
+	}
+
+	bodyJSON = []byte(`{"id": "01D3XZ38PRD", "name": "Rainbow Roll", "price": {"amount": 1300, "currency": "euro"}}`)
+	resRecorder = httptest.NewRecorder()
+	s.Router().ServeHTTP(resRecorder, httptest.NewRequest("POST", "/sushi", bytes.NewBuffer(bodyJSON)))
+	if resRecorder.Code != http.StatusBadRequest {
+		t.Errorf("expected %d, got: %d", http.StatusBadRequest, resRecorder.Code)
+	}
+}
+
 func TestModifySushi(t *testing.T) {
 	bodyJSON := []byte(`{
         "imageNumber": "4",
@@ -183,12 +307,21 @@ func sushiSample() *sushi.Sushi {
 	}
 }
 
-func buildServer() Server {
+func buildServer(opts ...Option) Server {
+	return buildServerWithPublisher(events.NewNoopPublisher(), opts...)
+}
+
+func buildServerWithPublisher(publisher events.Publisher, opts ...Option) Server {
+	return buildServerWithLocker(publisher, locking.NewMemoryLocker(), opts...)
+}
+
+func buildServerWithLocker(publisher events.Publisher, locker locking.Locker, opts ...Option) Server {
 	repo := inmem.NewRepository(sample.Sushis)
-	fetching := getting.NewService(repo, log.NewNoopLogger())
-	adding := adding.NewService(repo)
-	modifying := modifying.NewService(repo)
-	removing := removing.NewService(repo)
+	logger := log.NewNoopLogger()
+	fetching := getting.NewService(repo, logger)
+	adding := adding.NewService(repo, logger, publisher)
+	modifying := modifying.NewService(repo, logger, publisher, locker)
+	removing := removing.NewService(repo, logger, publisher)
 
-	return New("test", fetching, adding, modifying, removing)
+	return New("test", fetching, adding, modifying, removing, opts...)
 }
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+)
+
+// sseRetry is how long browsers wait before reconnecting, in milliseconds
+const sseRetry = 3000
+
+// SushiEvents streams the changes of the menu as Server-Sent Events. A client
+// reconnecting with Last-Event-ID gets the events it missed first, or a reset
+// event when they were forgotten so it reloads the whole menu.
+func (s *server) SushiEvents(w http.ResponseWriter, r *http.Request) {
+	subscription, missed, resumed := s.eventBus.Subscribe(r.Header.Get("Last-Event-ID"))
+	defer s.eventBus.Unsubscribe(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// proxies mustn't buffer the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &sseWriter{w: w, rc: http.NewResponseController(w), timeout: s.eventHeartbeat}
+	stream.printf("retry: %d\n\n", sseRetry)
+	if !resumed {
+		stream.printf("event: reset\ndata: {}\n\n")
+	}
+	for _, event := range missed {
+		stream.event(event)
+	}
+	if stream.flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(s.eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			// dropped for falling behind or shutting down, the client resumes
+			// from its last event
+			if !ok {
+				return
+			}
+			stream.event(event)
+		case <-heartbeat.C:
+			stream.printf(": heartbeat\n\n")
+		}
+		if stream.flush() != nil {
+			return
+		}
+	}
+}
+
+// sseWriter writes the events with a deadline, so a client that stopped
+// reading can't block the handler forever. The first error sticks.
+type sseWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	timeout time.Duration
+	err     error
+}
+
+func (s *sseWriter) printf(format string, args ...interface{}) {
+	if s.err != nil {
+		return
+	}
+	// the server write timeout would end the stream, each write gets its own
+	_ = s.rc.SetWriteDeadline(time.Now().Add(s.timeout))
+	_, s.err = fmt.Fprintf(s.w, format, args...)
+}
+
+func (s *sseWriter) event(event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.printf("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
+
+func (s *sseWriter) flush() error {
+	if s.err != nil {
+		return s.err
+	}
+	s.err = s.rc.Flush()
+	return s.err
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+)
+
+// sseClient reads the events of a stream, one field per line
+type sseClient struct {
+	res   *http.Response
+	lines chan string
+}
+
+func connectSSE(t *testing.T, url, lastEventID string) *sseClient {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url+"/sushi/events", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got: %s", ct)
+	}
+
+	c := &sseClient{res: res, lines: make(chan string, 100)}
+	go func() {
+		defer close(c.lines)
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			c.lines <- scanner.Text()
+		}
+	}()
+	t.Cleanup(func() { res.Body.Close() })
+	return c
+}
+
+// next returns the next line starting with prefix
+func (c *sseClient) next(t *testing.T, prefix string) string {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				t.Fatalf("the stream ended before %q", prefix)
+			}
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimPrefix(line, prefix)
+			}
+		case <-timeout:
+			t.Fatalf("expected a line starting with %q", prefix)
+		}
+	}
+}
+
+func TestSushiEvents(t *testing.T) {
+	bus := events.NewBus(10, 10)
+	s := buildServerWithPublisher(bus, WithEventStream(bus, time.Hour))
+	srv := httptest.NewServer(s.Router())
+	defer srv.Close()
+
+	client := connectSSE(t, srv.URL, "")
+	client.next(t, "retry: ")
+
+	post := httptest.NewRequest("POST", "/sushi", strings.NewReader(`{"id":"01D3XZ38SSE","imageNumber":"1","name":"Nigiri","ingredients":["rice"]}`))
+	s.Router().ServeHTTP(httptest.NewRecorder(), post)
+	s.Router().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/sushi/01D3XZ38SSE", nil))
+
+	createdID := client.next(t, "id: ")
+	if event := client.next(t, "event: "); event != "sushi.created" {
+		t.Errorf("expected sushi.created, got: %s", event)
+	}
+	if data := client.next(t, "data: "); !strings.Contains(data, `"sushiId":"01D3XZ38SSE"`) {
+		t.Errorf("expected the sushi in the data, got: %s", data)
+	}
+	client.next(t, "id: ")
+	if event := client.next(t, "event: "); event != "sushi.removed" {
+		t.Errorf("expected sushi.removed, got: %s", event)
+	}
+
+	resumed := connectSSE(t, srv.URL, createdID)
+	if event := resumed.next(t, "event: "); event != "sushi.removed" {
+		t.Errorf("expected the missed sushi.removed, got: %s", event)
+	}
+
+	forgotten := connectSSE(t, srv.URL, "unknown")
+	if event := forgotten.next(t, "event: "); event != "reset" {
+		t.Errorf("expected a reset, got: %s", event)
+	}
+
+	bus.Close()
+	for range client.lines {
+	}
+}
+
+func TestSushiEventsHeartbeat(t *testing.T) {
+	bus := events.NewBus(10, 10)
+	srv := httptest.NewServer(buildServer(WithEventStream(bus, 10*time.Millisecond)).Router())
+	defer srv.Close()
+	defer bus.Close()
+
+	client := connectSSE(t, srv.URL, "")
+	client.next(t, ": heartbeat")
+}
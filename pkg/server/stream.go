@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"iter"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// listEncoder writes the elements of a list as they arrive, either as a JSON
+// array or as newline delimited JSON
+type listEncoder struct {
+	w      http.ResponseWriter
+	ndjson bool
+	count  int
+}
+
+func newListEncoder(w http.ResponseWriter, r *http.Request) *listEncoder {
+	return &listEncoder{w: w, ndjson: accepts(r, ndjsonContentType)}
+}
+
+func (e *listEncoder) begin() error {
+	if e.ndjson {
+		e.w.Header().Set("Content-Type", ndjsonContentType)
+		e.w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	e.w.Header().Set("Content-Type", "application/json")
+	e.w.WriteHeader(http.StatusOK)
+	_, err := e.w.Write([]byte("["))
+	return err
+}
+
+func (e *listEncoder) encode(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	switch {
+	case e.ndjson:
+		b = append(b, '\n')
+	case e.count > 0:
+		b = append([]byte(","), b...)
+	}
+	e.count++
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *listEncoder) end() error {
+	if e.ndjson {
+		return nil
+	}
+	_, err := e.w.Write([]byte("]"))
+	return err
+}
+
+// streamSushis writes the sushis as they are yielded. The status is only sent
+// with the first sushi, so a repository failing straight away still gets a
+// 500; a failure after that aborts the response to leave it truncated.
+func streamSushis(w http.ResponseWriter, r *http.Request, sushis iter.Seq2[sushi.Sushi, error]) {
+	encoder := newListEncoder(w, r)
+	started := false
+	for s, err := range sushis {
+		if err != nil {
+			if started {
+				panic(http.ErrAbortHandler)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode("Can't list the sushis")
+			return
+		}
+		if !started {
+			started = true
+			if err := encoder.begin(); err != nil {
+				return
+			}
+		}
+		// the client is gone, stop reading the repository
+		if err := encoder.encode(s); err != nil {
+			return
+		}
+	}
+
+	if !started {
+		if err := encoder.begin(); err != nil {
+			return
+		}
+	}
+	_ = encoder.end()
+}
+
+// accepts tells whether the Accept header of the request lists the media type
+// with a non zero quality
+func accepts(r *http.Request, mediaType string) bool {
+	for _, header := range r.Header.Values("Accept") {
+		for _, accepted := range strings.Split(header, ",") {
+			accepted, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+			if err != nil || accepted != mediaType {
+				continue
+			}
+			if q, ok := params["q"]; ok {
+				if quality, err := strconv.ParseFloat(q, 64); err != nil || quality == 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sergiorra/sushi-api-go/cmd/sample-data"
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+func TestGetSushisNDJSON(t *testing.T) {
+	req := httptest.NewRequest("GET", "/sushi", nil)
+	req.Header.Set("Accept", "application/x-ndjson, application/json;q=0.5")
+	resRecorder := httptest.NewRecorder()
+
+	buildServer().Router().ServeHTTP(resRecorder, req)
+
+	if got := resRecorder.Header().Get("Content-Type"); got != ndjsonContentType {
+		t.Fatalf("expected %s, got: %s", ndjsonContentType, got)
+	}
+	lines := 0
+	scanner := bufio.NewScanner(resRecorder.Body)
+	for scanner.Scan() {
+		var s sushi.Sushi
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatalf("could not unmarshall line %q: %v", scanner.Text(), err)
+		}
+		lines++
+	}
+	if lines != len(sample.Sushis) {
+		t.Errorf("expected %d lines, got: %d", len(sample.Sushis), lines)
+	}
+}
+
+func TestStreamSushis(t *testing.T) {
+	failure := errors.New("repository unavailable")
+	testData := []struct {
+		name     string
+		sushis   iter.Seq2[sushi.Sushi, error]
+		status   int
+		expected string
+	}{
+		{
+			name:     "empty list",
+			sushis:   func(yield func(sushi.Sushi, error) bool) {},
+			status:   http.StatusOK,
+			expected: "[]",
+		},
+		{
+			name: "several sushis",
+			sushis: func(yield func(sushi.Sushi, error) bool) {
+				_ = yield(sushi.Sushi{ID: "a"}, nil) && yield(sushi.Sushi{ID: "b"}, nil)
+			},
+			status:   http.StatusOK,
+			expected: `[{"id":"a"},{"id":"b"}]`,
+		},
+		{
+			name:     "repository failure",
+			sushis:   func(yield func(sushi.Sushi, error) bool) { yield(sushi.Sushi{}, failure) },
+			status:   http.StatusInternalServerError,
+			expected: "\"Can't list the sushis\"\n",
+		},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			resRecorder := httptest.NewRecorder()
+
+			streamSushis(resRecorder, httptest.NewRequest("GET", "/sushi", nil), tt.sushis)
+
+			if resRecorder.Code != tt.status {
+				t.Errorf("expected %d, got: %d", tt.status, resRecorder.Code)
+			}
+			if got := resRecorder.Body.String(); got != tt.expected {
+				t.Errorf("expected %s, got: %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStreamSushisAbortsAfterFirstSushi(t *testing.T) {
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("expected the response to be aborted, got: %v", r)
+		}
+	}()
+
+	sushis := func(yield func(sushi.Sushi, error) bool) {
+		_ = yield(sushi.Sushi{ID: "a"}, nil) && yield(sushi.Sushi{}, errors.New("connection lost"))
+	}
+	streamSushis(httptest.NewRecorder(), httptest.NewRequest("GET", "/sushi", nil), sushis)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCertSubject(t *testing.T) {
+	var subject, principal string
+	h := newServerMiddleware("test", nil, newAPIKeys([]string{"kiosk"}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject, _ = ClientCertSubject(r.Context())
+		principal, _ = Principal(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "https://localhost/sushi", nil)
+	req.Header.Set(apiKeyHeader, "kiosk")
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "pos-terminal", Organization: []string{"Sushi"}}}
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if subject != "CN=pos-terminal,O=Sushi" {
+		t.Errorf("expected the certificate subject, got: %q", subject)
+	}
+	if principal != "cert:CN=pos-terminal,O=Sushi" {
+		t.Errorf("expected the certificate to be the principal, got: %q", principal)
+	}
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	testData := []struct {
+		name     string
+		port     int
+		target   string
+		expected string
+	}{
+		{name: "default port", port: 443, target: "http://menu.example.com/sushi?id=1", expected: "https://menu.example.com/sushi?id=1"},
+		{name: "custom port", port: 8443, target: "http://menu.example.com:8080/sushi", expected: "https://menu.example.com:8443/sushi"},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			resRecorder := httptest.NewRecorder()
+			RedirectToHTTPS(tt.port).ServeHTTP(resRecorder, httptest.NewRequest("GET", tt.target, nil))
+
+			if resRecorder.Code != http.StatusPermanentRedirect {
+				t.Errorf("expected %d, got: %d", http.StatusPermanentRedirect, resRecorder.Code)
+			}
+			if got := resRecorder.Header().Get("Location"); got != tt.expected {
+				t.Errorf("expected %s, got: %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// tracerProvider is the provider set globally by the tests, always the same
+// one since the tracers of the packages keep the first provider set
+var tracerProvider = sdktrace.NewTracerProvider()
+
+// recordSpans records the spans ended until the end of the test, when the
+// previous global provider is restored
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider.RegisterSpanProcessor(recorder)
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracerProvider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		tracerProvider.UnregisterSpanProcessor(recorder)
+	})
+	return recorder
+}
+
+func TestTracing(t *testing.T) {
+	recorder := recordSpans(t)
+
+	s := buildServer()
+	req := httptest.NewRequest("GET", "/sushi/01D3XZ38KDR", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	s.Router().ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	serverSpan, ok := spans["GET /sushi/{ID}"]
+	if !ok {
+		t.Fatalf("expected a server span, got: %v", spans)
+	}
+	if got := serverSpan.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected the trace received in traceparent, got: %s", got)
+	}
+	if got := serverSpan.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("expected the remote parent span, got: %s", got)
+	}
+
+	serviceSpan, ok := spans["getting.GetSushiByID"]
+	if !ok {
+		t.Fatalf("expected a service span, got: %v", spans)
+	}
+	if serviceSpan.Parent().SpanID() != serverSpan.SpanContext().SpanID() {
+		t.Errorf("expected the service span to be a child of the server span")
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/webhook"
+)
+
+type subscribeRequest struct {
+	URL    string        `json:"url"`
+	Events []events.Type `json:"events"`
+	Secret string        `json:"secret"`
+}
+
+// Subscribe registers a webhook, the response is the only one with its secret
+func (s *server) Subscribe(w http.ResponseWriter, r *http.Request) {
+	var req subscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, "Error unmarshalling request body")
+		return
+	}
+
+	subscription, err := s.webhooks.Subscribe(r.Context(), webhook.Subscription{URL: req.URL, Events: req.Events, Secret: req.Secret})
+	if err != nil {
+		if errors.Is(err, webhook.ErrInvalidSubscription) {
+			writeJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, "Can't register the webhook")
+		return
+	}
+
+	w.Header().Set("Location", "/webhooks/"+subscription.ID)
+	writeJSON(w, http.StatusCreated, subscription)
+}
+
+// Subscriptions lists the webhooks
+func (s *server) Subscriptions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.webhooks.Subscriptions(r.Context()))
+}
+
+// Subscription returns a webhook
+func (s *server) Subscription(w http.ResponseWriter, r *http.Request) {
+	subscription, err := s.webhooks.Subscription(r.Context(), mux.Vars(r)["ID"])
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, subscription)
+}
+
+// Unsubscribe removes a webhook
+func (s *server) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	if err := s.webhooks.Unsubscribe(r.Context(), mux.Vars(r)["ID"]); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries returns the latest delivery attempts of a webhook
+func (s *server) Deliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := s.webhooks.Deliveries(r.Context(), mux.Vars(r)["ID"])
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// DeadLetters returns the latest events the webhooks gave up delivering
+func (s *server) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.webhooks.DeadLetters(r.Context()))
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	if errors.Is(err, webhook.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, "Webhook Not found")
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, "Can't read the webhook")
+}
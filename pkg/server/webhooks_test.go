@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/webhook"
+)
+
+func TestWebhooks(t *testing.T) {
+	received := make(chan *http.Request, 10)
+	bodies := make(chan []byte, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer receiver.Close()
+
+	dispatcher := webhook.NewDispatcher(webhook.DefaultConfig(), receiver.Client(), log.NewNoopLogger())
+	defer dispatcher.Close(context.Background())
+	s := buildServerWithPublisher(dispatcher, WithWebhooks(dispatcher))
+
+	serve := func(method, uri, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, uri, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		s.Router().ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	res := serve("POST", "/webhooks", `{"url":"`+receiver.URL+`","events":["sushi.removed"]}`)
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got: %d", http.StatusCreated, res.Code)
+	}
+	var subscription webhook.Subscription
+	if err := json.Unmarshal(res.Body.Bytes(), &subscription); err != nil {
+		t.Fatalf("could not unmarshall response %v", err)
+	}
+	if subscription.Secret == "" {
+		t.Errorf("expected the secret in the registration response")
+	}
+	if location := res.Header().Get("Location"); location != "/webhooks/"+subscription.ID {
+		t.Errorf("expected location /webhooks/%s, got: %s", subscription.ID, location)
+	}
+
+	serve("POST", "/sushi", `{"id":"01D3XZ38HOOK","imageNumber":"1","name":"Nigiri","ingredients":["rice"]}`)
+	serve("DELETE", "/sushi/01D3XZ38HOOK", "")
+
+	select {
+	case req := <-received:
+		if event := req.Header.Get(webhook.EventHeader); event != "sushi.removed" {
+			t.Errorf("expected only the sushi.removed event, got: %s", event)
+		}
+		if err := webhook.Verify(subscription.Secret, req.Header.Get(webhook.SignatureHeader), <-bodies, time.Minute, time.Now()); err != nil {
+			t.Errorf("expected a valid signature, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a delivery")
+	}
+
+	testData := []struct {
+		name   string
+		method string
+		uri    string
+		body   string
+		status int
+	}{
+		{name: "list", method: "GET", uri: "/webhooks", status: http.StatusOK},
+		{name: "get", method: "GET", uri: "/webhooks/" + subscription.ID, status: http.StatusOK},
+		{name: "deliveries", method: "GET", uri: "/webhooks/" + subscription.ID + "/deliveries", status: http.StatusOK},
+		{name: "dead letters", method: "GET", uri: "/webhooks/dead-letters", status: http.StatusOK},
+		{name: "invalid url", method: "POST", uri: "/webhooks", body: `{"url":"menu-board"}`, status: http.StatusBadRequest},
+		{name: "invalid body", method: "POST", uri: "/webhooks", body: `{`, status: http.StatusBadRequest},
+		{name: "delete", method: "DELETE", uri: "/webhooks/" + subscription.ID, status: http.StatusNoContent},
+		{name: "unknown", method: "GET", uri: "/webhooks/" + subscription.ID, status: http.StatusNotFound},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			res := serve(tt.method, tt.uri, tt.body)
+			if res.Code != tt.status {
+				t.Errorf("expected %d, got: %d", tt.status, res.Code)
+			}
+			if strings.Contains(res.Body.String(), subscription.Secret) {
+				t.Errorf("expected the secret to stay hidden, got: %s", res.Body.String())
+			}
+		})
+	}
+}
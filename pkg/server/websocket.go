@@ -0,0 +1,307 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+)
+
+const (
+	// lockTokenHeader carries the lock token of the writes to a locked sushi
+	lockTokenHeader = "Lock-Token"
+
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	wsMaxMessage = 4096
+	wsSendBuffer = 64
+)
+
+// wsMessage is sent by the editors: subscribe and unsubscribe take ids, "*"
+// meaning every sushi, lock and unlock take an id and lock an optional ttl in
+// seconds. The ref is echoed in the reply.
+type wsMessage struct {
+	Type string   `json:"type"`
+	Ref  string   `json:"ref,omitempty"`
+	ID   string   `json:"id,omitempty"`
+	IDs  []string `json:"ids,omitempty"`
+	TTL  int      `json:"ttl,omitempty"`
+}
+
+// wsReply is sent to the editors, either as a reply or as a notification of
+// what happened to the sushis they subscribed to
+type wsReply struct {
+	Type  string        `json:"type"`
+	Ref   string        `json:"ref,omitempty"`
+	ID    string        `json:"id,omitempty"`
+	IDs   []string      `json:"ids,omitempty"`
+	Event *events.Event `json:"event,omitempty"`
+	Lock  *locking.Lock `json:"lock,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+// wsHub knows the connected editors to notify them of the locks
+type wsHub struct {
+	mtx   sync.Mutex
+	conns map[*wsConn]struct{}
+}
+
+func (h *wsHub) add(c *wsConn) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.conns[c] = struct{}{}
+}
+
+func (h *wsHub) remove(c *wsConn) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	delete(h.conns, c)
+}
+
+// notify sends the reply to the editors subscribed to the sushi but the sender
+func (h *wsHub) notify(sender *wsConn, ID string, reply wsReply) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	for c := range h.conns {
+		if c != sender && c.subscribed(ID) {
+			c.reply(reply)
+		}
+	}
+}
+
+type wsConn struct {
+	conn  *websocket.Conn
+	send  chan wsReply
+	owner string
+
+	mtx   sync.Mutex
+	all   bool
+	ids   map[string]bool
+	locks map[string]string
+}
+
+func (c *wsConn) subscribed(ID string) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.all || c.ids[ID]
+}
+
+// reply queues a message, an editor that doesn't read them is disconnected
+func (c *wsConn) reply(reply wsReply) {
+	select {
+	case c.send <- reply:
+	default:
+		_ = c.conn.Close()
+	}
+}
+
+func (c *wsConn) write(reply wsReply) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return c.conn.WriteJSON(reply)
+}
+
+// SushiSocket lets the menu editors follow the changes of the sushis and lock
+// the ones they are editing. The locks are released when the editor leaves.
+func (s *server) SushiSocket(w http.ResponseWriter, r *http.Request) {
+	// the upgrader replies to failed handshakes
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	owner, ok := Principal(r.Context())
+	if !ok {
+		ip, _ := ClientIP(r.Context())
+		owner = "ip:" + ip
+	}
+	c := &wsConn{
+		conn:  conn,
+		send:  make(chan wsReply, wsSendBuffer),
+		owner: owner,
+		ids:   make(map[string]bool),
+		locks: make(map[string]string),
+	}
+	subscription, _, _ := s.wsBus.Subscribe("")
+	s.wsHub.add(c)
+
+	done := make(chan struct{})
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		s.writeSocket(c, subscription, done)
+	}()
+	s.readSocket(r.Context(), c)
+
+	close(done)
+	<-written
+	s.wsHub.remove(c)
+	s.wsBus.Unsubscribe(subscription)
+	// the request context ends with the handler
+	ctx := context.WithoutCancel(r.Context())
+	for ID, token := range c.locks {
+		if s.locker.Release(ctx, ID, token) == nil {
+			s.wsHub.notify(c, ID, wsReply{Type: "unlocked", ID: ID})
+		}
+	}
+}
+
+// readSocket handles the messages of the editor until the connection fails
+func (s *server) readSocket(ctx context.Context, c *wsConn) {
+	c.conn.SetReadLimit(wsMaxMessage)
+	_ = c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.reply(wsReply{Type: "error", Error: "invalid message"})
+			continue
+		}
+		s.handleSocketMessage(ctx, c, msg)
+	}
+}
+
+func (s *server) handleSocketMessage(ctx context.Context, c *wsConn, msg wsMessage) {
+	switch msg.Type {
+	case "subscribe", "unsubscribe":
+		for _, ID := range msg.IDs {
+			if ID != "*" && !validSushiID(ID) {
+				c.reply(wsReply{Type: "error", Ref: msg.Ref, ID: ID, Error: "invalid sushi ID"})
+				return
+			}
+		}
+		c.mtx.Lock()
+		for _, ID := range msg.IDs {
+			switch {
+			case ID == "*":
+				c.all = msg.Type == "subscribe"
+			case msg.Type == "subscribe":
+				c.ids[ID] = true
+			default:
+				delete(c.ids, ID)
+			}
+		}
+		c.mtx.Unlock()
+		c.reply(wsReply{Type: msg.Type + "d", Ref: msg.Ref, IDs: msg.IDs})
+
+	case "lock":
+		if !validSushiID(msg.ID) {
+			c.reply(wsReply{Type: "error", Ref: msg.Ref, ID: msg.ID, Error: "invalid sushi ID"})
+			return
+		}
+		ttl := s.lockTTL
+		if requested := time.Duration(msg.TTL) * time.Second; requested > 0 && requested < ttl {
+			ttl = requested
+		}
+		lock, err := s.locker.Acquire(ctx, msg.ID, c.owner, c.locks[msg.ID], ttl)
+		if err != nil {
+			if !errors.Is(err, locking.ErrLocked) {
+				s.logger.UnexpectedError(ctx, err)
+			}
+			c.reply(wsReply{Type: "error", Ref: msg.Ref, ID: msg.ID, Error: err.Error()})
+			return
+		}
+		c.locks[msg.ID] = lock.Token
+		c.reply(wsReply{Type: "locked", Ref: msg.Ref, ID: msg.ID, Lock: &lock})
+		// only the holder learns the token
+		notified := lock
+		notified.Token = ""
+		s.wsHub.notify(c, msg.ID, wsReply{Type: "locked", ID: msg.ID, Lock: &notified})
+
+	case "unlock":
+		token, ok := c.locks[msg.ID]
+		if !ok {
+			c.reply(wsReply{Type: "error", Ref: msg.Ref, ID: msg.ID, Error: locking.ErrNotHeld.Error()})
+			return
+		}
+		delete(c.locks, msg.ID)
+		if err := s.locker.Release(ctx, msg.ID, token); err != nil {
+			c.reply(wsReply{Type: "error", Ref: msg.Ref, ID: msg.ID, Error: err.Error()})
+			return
+		}
+		c.reply(wsReply{Type: "unlocked", Ref: msg.Ref, ID: msg.ID})
+		s.wsHub.notify(c, msg.ID, wsReply{Type: "unlocked", ID: msg.ID})
+
+	default:
+		c.reply(wsReply{Type: "error", Ref: msg.Ref, Error: "unknown message type " + msg.Type})
+	}
+}
+
+// writeSocket is the only writer of the connection: replies, events and pings
+func (s *server) writeSocket(c *wsConn, subscription *events.Subscription, done <-chan struct{}) {
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+	// ends the reads when the writes fail
+	defer c.conn.Close()
+
+	for {
+		var err error
+		select {
+		case <-done:
+			return
+		case reply := <-c.send:
+			err = c.write(reply)
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// the editor fell behind or the server is shutting down
+				code := websocket.CloseGoingAway
+				if errors.Is(subscription.Err(), events.ErrOverflow) {
+					code = websocket.CloseTryAgainLater
+				}
+				message := websocket.FormatCloseMessage(code, "")
+				_ = c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsWriteWait))
+				return
+			}
+			if c.subscribed(event.SushiID) {
+				err = c.write(wsReply{Type: "event", ID: event.SushiID, Event: &event})
+			}
+		case <-ping.C:
+			err = c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// checkOrigin accepts the browsers of the same origin or of the origins
+// allowed by the CORS policy, clients outside browsers send no origin
+func (s *server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if s.cors != nil && s.cors.allowsOrigin(origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func validSushiID(ID string) bool {
+	if ID == "" {
+		return false
+	}
+	for _, c := range ID {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+)
+
+func dialSocket(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/sushi/ws", nil)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg wsMessage) {
+	t.Helper()
+	if err := conn.WriteJSON(msg); err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+}
+
+// receive returns the next message of the given type
+func receive(t *testing.T, conn *websocket.Conn, replyType string) wsReply {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var reply wsReply
+		if err := conn.ReadJSON(&reply); err != nil {
+			t.Fatalf("expected a %s message: %v", replyType, err)
+		}
+		if reply.Type == replyType {
+			return reply
+		}
+	}
+}
+
+func TestSushiSocket(t *testing.T) {
+	bus := events.NewBus(10, 10)
+	locker := locking.NewMemoryLocker()
+	s := buildServerWithLocker(bus, locker, WithWebSocket(bus, locker, time.Minute))
+	srv := httptest.NewServer(s.Router())
+	defer srv.Close()
+	defer bus.Close()
+
+	const ID = "01D3XZ38WS"
+	serve := func(method, body, token string) int {
+		uri := "/sushi"
+		if method != "POST" {
+			uri += "/" + ID
+		}
+		req := httptest.NewRequest(method, uri, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set(lockTokenHeader, token)
+		}
+		resRecorder := httptest.NewRecorder()
+		s.Router().ServeHTTP(resRecorder, req)
+		return resRecorder.Code
+	}
+	serve("POST", `{"id":"`+ID+`","imageNumber":"1","name":"Nigiri","ingredients":["rice"]}`, "")
+	defer serve("DELETE", "", "")
+
+	alice, bob := dialSocket(t, srv.URL), dialSocket(t, srv.URL)
+	send(t, alice, wsMessage{Type: "subscribe", Ref: "1", IDs: []string{ID}})
+	if reply := receive(t, alice, "subscribed"); reply.Ref != "1" {
+		t.Errorf("expected the ref to be echoed, got: %q", reply.Ref)
+	}
+	send(t, bob, wsMessage{Type: "subscribe", IDs: []string{"*"}})
+	receive(t, bob, "subscribed")
+
+	send(t, alice, wsMessage{Type: "lock", ID: ID, TTL: 30})
+	lock := receive(t, alice, "locked").Lock
+	if lock == nil || lock.Token == "" {
+		t.Fatalf("expected the holder to get the token, got: %+v", lock)
+	}
+	if notified := receive(t, bob, "locked").Lock; notified.Token != "" || notified.Owner != lock.Owner {
+		t.Errorf("expected the lock without its token, got: %+v", notified)
+	}
+
+	send(t, bob, wsMessage{Type: "lock", ID: ID})
+	if reply := receive(t, bob, "error"); !strings.Contains(reply.Error, "sushi locked") {
+		t.Errorf("expected the sushi to be locked, got: %s", reply.Error)
+	}
+
+	modification := `{"imageNumber":"2","name":"Nigiri","ingredients":["rice","salmon"]}`
+	if status := serve("PUT", modification, ""); status != http.StatusLocked {
+		t.Errorf("expected %d without the token, got: %d", http.StatusLocked, status)
+	}
+	if status := serve("PUT", modification, lock.Token); status != http.StatusNoContent {
+		t.Errorf("expected %d with the token, got: %d", http.StatusNoContent, status)
+	}
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		if event := receive(t, conn, "event").Event; event.Type != events.SushiModified {
+			t.Errorf("expected %s, got: %s", events.SushiModified, event.Type)
+		}
+	}
+
+	send(t, alice, wsMessage{Type: "unlock", ID: ID})
+	receive(t, alice, "unlocked")
+	receive(t, bob, "unlocked")
+
+	send(t, alice, wsMessage{Type: "lock", ID: ID})
+	receive(t, bob, "locked")
+	alice.Close()
+	if reply := receive(t, bob, "unlocked"); reply.ID != ID {
+		t.Errorf("expected the locks of a leaving editor to be released, got: %+v", reply)
+	}
+}
+
+func TestSushiSocketOrigin(t *testing.T) {
+	bus := events.NewBus(10, 10)
+	defer bus.Close()
+	srv := httptest.NewServer(buildServer(WithWebSocket(bus, locking.NewMemoryLocker(), time.Minute)).Router())
+	defer srv.Close()
+
+	header := http.Header{"Origin": {"https://evil.example.com"}}
+	_, res, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/sushi/ws", header)
+	if err == nil {
+		t.Fatal("expected the handshake to fail")
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("expected %d, got: %d", http.StatusForbidden, res.StatusCode)
+	}
+}
@@ -0,0 +1,85 @@
+// Package servicetest wires the services of the API over an in-memory
+// repository, for the tests of the servers exposing them and of their clients
+package servicetest
+
+import (
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/adding"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/getting"
+	"github.com/sergiorra/sushi-api-go/pkg/ingredients"
+	"github.com/sergiorra/sushi-api-go/pkg/locking"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+	"github.com/sergiorra/sushi-api-go/pkg/modifying"
+	"github.com/sergiorra/sushi-api-go/pkg/removing"
+	"github.com/sergiorra/sushi-api-go/pkg/storage/inmem"
+)
+
+// Services are the services of the API and their dependencies
+type Services struct {
+	Repository sushi.Repository
+	Logger     log.Logger
+	// Bus receives the events of the services
+	Bus    *events.Bus
+	Locker locking.Locker
+
+	Getting   getting.Service
+	Adding    adding.Service
+	Modifying modifying.Service
+	Removing  removing.Service
+	// Ingredients is nil unless WithIngredients is given
+	Ingredients ingredients.Service
+}
+
+// Option configures the services
+type Option func(*options)
+
+type options struct {
+	catalogue sushi.IngredientRepository
+}
+
+// WithIngredients checks and labels the sushis against the catalogue, which
+// the Ingredients service manages
+func WithIngredients(catalogue sushi.IngredientRepository) Option {
+	return func(o *options) {
+		o.catalogue = catalogue
+	}
+}
+
+// New wires the services over a copy of the sushis, so the tests don't
+// change each other's menu
+func New(sushis map[string]sushi.Sushi, opts ...Option) *Services {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	menu := make(map[string]sushi.Sushi, len(sushis))
+	for ID, s := range sushis {
+		menu[ID] = s
+	}
+	s := &Services{
+		Repository: inmem.NewRepository(menu),
+		Logger:     log.NewNoopLogger(),
+		Bus:        events.NewBus(10, 10),
+		Locker:     locking.NewMemoryLocker(),
+	}
+
+	var (
+		gettingOpts   []getting.Option
+		addingOpts    []adding.Option
+		modifyingOpts []modifying.Option
+	)
+	if o.catalogue != nil {
+		catalogue := ingredients.NewCatalogue(o.catalogue)
+		gettingOpts = append(gettingOpts, getting.WithIngredients(catalogue))
+		addingOpts = append(addingOpts, adding.WithIngredients(catalogue))
+		modifyingOpts = append(modifyingOpts, modifying.WithIngredients(catalogue))
+		s.Ingredients = ingredients.NewService(catalogue, s.Repository, s.Logger, s.Bus)
+	}
+	s.Getting = getting.NewService(s.Repository, s.Logger, gettingOpts...)
+	s.Adding = adding.NewService(s.Repository, s.Logger, s.Bus, addingOpts...)
+	s.Modifying = modifying.NewService(s.Repository, s.Logger, s.Bus, s.Locker, modifyingOpts...)
+	s.Removing = removing.NewService(s.Repository, s.Logger, s.Bus)
+	return s
+}
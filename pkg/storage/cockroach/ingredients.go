@@ -0,0 +1,175 @@
+package cockroach
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+/*
+	$ CREATE TABLE ingredients (
+			id STRING(32) PRIMARY KEY,
+			name STRING NOT NULL,
+			allergens JSONB NULL,
+			vegetarian BOOL NOT NULL,
+			vegan BOOL NOT NULL,
+			raw_fish BOOL NOT NULL,
+			created_at TIMESTAMPTZ NULL,
+			updated_at TIMESTAMPTZ NULL
+		);
+*/
+
+const ingredientsTable = "ingredients"
+
+type ingredientRepository struct {
+	db *sql.DB
+}
+
+// NewIngredientRepository creates a cockroach repository of the ingredient
+// catalogue, its writes count in the versions table too. It shares the
+// connection of the sushi repository, which closes it
+func NewIngredientRepository(db *sql.DB) sushi.IngredientRepository {
+	return ingredientRepository{db: db}
+}
+
+func (r ingredientRepository) CreateIngredient(ctx context.Context, i *sushi.Ingredient) error {
+	allergens, err := allergensColumn(i.Allergens)
+	if err != nil {
+		return err
+	}
+	sqlStm := `INSERT INTO ingredients (id, name, allergens, vegetarian, vegan, raw_fish, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err = exec(ctx, r.db, ingredientsTable, sqlStm, i.ID, i.Name, allergens, i.Vegetarian, i.Vegan, i.RawFish, i.CreatedAt, i.UpdatedAt)
+	return err
+}
+
+// GetIngredients returns the ingredients ordered by ID
+func (r ingredientRepository) GetIngredients(ctx context.Context) ([]sushi.Ingredient, error) {
+	sqlStm := `SELECT id, name, allergens, vegetarian, vegan, raw_fish, created_at, updated_at FROM ingredients ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, sqlStm)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ingredients := []sushi.Ingredient{}
+	for rows.Next() {
+		i, err := scanIngredient(rows)
+		if err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, i)
+	}
+	return ingredients, rows.Err()
+}
+
+func (r ingredientRepository) GetIngredientByID(ctx context.Context, ID string) (*sushi.Ingredient, error) {
+	sqlStm := `SELECT id, name, allergens, vegetarian, vegan, raw_fish, created_at, updated_at FROM ingredients WHERE id=$1`
+	i, err := scanIngredient(r.db.QueryRowContext(ctx, sqlStm, ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", sushi.ErrIngredientNotFound, ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r ingredientRepository) UpdateIngredient(ctx context.Context, ID string, i *sushi.Ingredient) error {
+	allergens, err := allergensColumn(i.Allergens)
+	if err != nil {
+		return err
+	}
+	sqlStm := `UPDATE ingredients SET name=$1, allergens=$2, vegetarian=$3, vegan=$4, raw_fish=$5, updated_at=$6 WHERE id=$7`
+	rowsAffected, err := exec(ctx, r.db, ingredientsTable, sqlStm, i.Name, allergens, i.Vegetarian, i.Vegan, i.RawFish, i.UpdatedAt, ID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", sushi.ErrIngredientNotFound, ID)
+	}
+	return nil
+}
+
+func (r ingredientRepository) DeleteIngredient(ctx context.Context, ID string) error {
+	sqlStm := `DELETE FROM ingredients WHERE id=$1`
+	_, err := exec(ctx, r.db, ingredientsTable, sqlStm, ID)
+	return err
+}
+
+// DeleteUnreferencedIngredient satisfies the sushi.UnreferencedDeleter
+// interface, reading the sushis made of the ingredient in the transaction
+// deleting it
+func (r ingredientRepository) DeleteUnreferencedIngredient(ctx context.Context, ID string) error {
+	sqlStm := `DELETE FROM ingredients WHERE id=$1`
+	_, err := execChecked(ctx, r.db, func(tx *sql.Tx) error {
+		return checkUnreferenced(ctx, tx, ID)
+	}, ingredientsTable, sqlStm, ID)
+	return err
+}
+
+// checkUnreferenced returns an InUse error while some sushis are made of the
+// ingredient
+func checkUnreferenced(ctx context.Context, tx *sql.Tx, ID string) error {
+	contained, err := jsonColumn([]string{ID})
+	if err != nil {
+		return err
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM sushis WHERE ingredients @> $1`, contained)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var sushiIDs []string
+	for rows.Next() {
+		var sushiID string
+		if err := rows.Scan(&sushiID); err != nil {
+			return err
+		}
+		sushiIDs = append(sushiIDs, sushiID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(sushiIDs) > 0 {
+		return sushi.InUse(ID, sushiIDs)
+	}
+	return nil
+}
+
+// Version satisfies the sushi.Versioner interface, the tag is the number of
+// writes of the ingredients
+func (r ingredientRepository) Version(ctx context.Context) (sushi.Version, error) {
+	return readVersion(ctx, r.db, ingredientsTable)
+}
+
+// allergensColumn returns the value of the allergens column, a JSON array or
+// NULL without allergens
+func allergensColumn(allergens []sushi.Allergen) (*string, error) {
+	if len(allergens) == 0 {
+		return nil, nil
+	}
+	return jsonColumn(allergens)
+}
+
+// scanIngredient reads a row selected with the columns of the table in order
+func scanIngredient(row interface{ Scan(...any) error }) (sushi.Ingredient, error) {
+	var (
+		i         sushi.Ingredient
+		allergens []byte
+	)
+	if err := row.Scan(&i.ID, &i.Name, &allergens, &i.Vegetarian, &i.Vegan, &i.RawFish, &i.CreatedAt, &i.UpdatedAt); err != nil {
+		return i, err
+	}
+	if len(allergens) > 0 {
+		if err := json.Unmarshal(allergens, &i.Allergens); err != nil {
+			return i, fmt.Errorf("invalid allergens of the ingredient %s: %w", i.ID, err)
+		}
+	}
+	return i, nil
+}
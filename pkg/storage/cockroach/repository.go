@@ -3,8 +3,13 @@ package cockroach
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
 	"log"
+	"strconv"
+	"time"
 
 	_ "github.com/lib/pq"
 
@@ -21,10 +26,19 @@ import (
 			id STRING(32),
 			image_number STRING(100) NOT NULL,
 			name STRING NULL,
+			ingredients JSONB NULL,
+			price_amount INT8 NULL,
+			price_currency STRING(3) NULL,
+			prices JSONB NULL,
 			created_at TIMESTAMPTZ NOT NULL,
 	   		updated_at TIMESTAMPTZ,
 	   		PRIMARY KEY ("id")
 		);
+	$ CREATE TABLE versions (
+			name STRING PRIMARY KEY,
+			version INT8 NOT NULL,
+			modified TIMESTAMPTZ NOT NULL
+		);
 	--- open new terminal tab ---
 	$ go run cmd/sushi-api/main.go -database cockroach
  */
@@ -40,9 +54,17 @@ func NewRepository(db *sql.DB) sushi.Repository {
 
 func (r sushiRepository) CreateSushi(ctx context.Context, s *sushi.Sushi) error {
 	fmt.Println("creating")
-	sqlStm := `INSERT INTO sushis (id, image_number, name, created_at) 
-				VALUES ($1, $2, $3, NOW())`
-	_, err := r.db.Exec(sqlStm, s.ID, s.ImageNumber, s.Name)
+	ingredients, err := ingredientsColumn(s.Ingredients)
+	if err != nil {
+		return err
+	}
+	amount, currency, prices, err := pricingColumns(s.Pricing)
+	if err != nil {
+		return err
+	}
+	sqlStm := `INSERT INTO sushis (id, image_number, name, ingredients, price_amount, price_currency, prices, created_at) 
+				VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`
+	_, err = exec(ctx, r.db, sushisTable, sqlStm, s.ID, s.ImageNumber, s.Name, ingredients, amount, currency, prices)
 	fmt.Println("err", err)
 	if err != nil {
 		return err
@@ -51,28 +73,46 @@ func (r sushiRepository) CreateSushi(ctx context.Context, s *sushi.Sushi) error
 }
 
 func (r sushiRepository) GetSushis(ctx context.Context) ([]sushi.Sushi, error) {
-	sqlStm := `SELECT id, image_number, name, created_at, updated_at FROM sushis`
-	rows, err := r.db.Query(sqlStm)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
 	var sushis []sushi.Sushi
-
-	for rows.Next() {
-		var s sushi.Sushi
-		if err := rows.Scan(&s.ID, &s.ImageNumber, &s.Name, &s.CreatedAt, &s.UpdatedAt); err != nil {
-			log.Println(err)
-			continue
+	for s, err := range r.StreamSushis(ctx) {
+		if err != nil {
+			return nil, err
 		}
 		sushis = append(sushis, s)
 	}
 	return sushis, nil
 }
 
+// StreamSushis satisfies the sushi.Streamer interface, scanning one row at a time
+func (r sushiRepository) StreamSushis(ctx context.Context) iter.Seq2[sushi.Sushi, error] {
+	return func(yield func(sushi.Sushi, error) bool) {
+		sqlStm := `SELECT id, image_number, name, ingredients, price_amount, price_currency, prices, created_at, updated_at FROM sushis`
+		rows, err := r.db.QueryContext(ctx, sqlStm)
+		if err != nil {
+			yield(sushi.Sushi{}, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			s, err := scanSushi(rows)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			if !yield(s, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(sushi.Sushi{}, err)
+		}
+	}
+}
+
 func (r sushiRepository) DeleteSushi(ctx context.Context, ID string) error {
 	sqlStm := `DELETE FROM sushis WHERE id=$1`
-	_, err := r.db.Exec(sqlStm, ID)
+	_, err := exec(ctx, r.db, sushisTable, sqlStm, ID)
 	if err != nil {
 		return err
 	}
@@ -80,8 +120,16 @@ func (r sushiRepository) DeleteSushi(ctx context.Context, ID string) error {
 }
 
 func (r sushiRepository) UpdateSushi(ctx context.Context, ID string, s *sushi.Sushi) error {
-	sqlStm := `UPDATE sushis SET image_number=$1, name=$2, updated_at=$3 WHERE id=$4`
-	_, err := r.db.Exec(sqlStm, s.ImageNumber, s.Name, s.UpdatedAt, ID)
+	ingredients, err := ingredientsColumn(s.Ingredients)
+	if err != nil {
+		return err
+	}
+	amount, currency, prices, err := pricingColumns(s.Pricing)
+	if err != nil {
+		return err
+	}
+	sqlStm := `UPDATE sushis SET image_number=$1, name=$2, ingredients=$3, price_amount=$4, price_currency=$5, prices=$6, updated_at=$7 WHERE id=$8`
+	_, err = exec(ctx, r.db, sushisTable, sqlStm, s.ImageNumber, s.Name, ingredients, amount, currency, prices, s.UpdatedAt, ID)
 	if err != nil {
 		return err
 	}
@@ -89,8 +137,8 @@ func (r sushiRepository) UpdateSushi(ctx context.Context, ID string, s *sushi.Su
 }
 
 func (r sushiRepository) GetSushiByID(ctx context.Context, ID string) (*sushi.Sushi, error) {
-	sqlStm := `SELECT id, image_number, name, created_at, updated_at FROM sushis WHERE id=$1`
-	rows, err := r.db.Query(sqlStm, ID)
+	sqlStm := `SELECT id, image_number, name, ingredients, price_amount, price_currency, prices, created_at, updated_at FROM sushis WHERE id=$1`
+	rows, err := r.db.QueryContext(ctx, sqlStm, ID)
 	if err != nil {
 		return nil, err
 	}
@@ -98,9 +146,139 @@ func (r sushiRepository) GetSushiByID(ctx context.Context, ID string) (*sushi.Su
 	var s sushi.Sushi
 
 	if rows.Next() {
-		if err := rows.Scan(&s.ID, &s.ImageNumber, &s.Name, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		if s, err = scanSushi(rows); err != nil {
 			log.Println(err)
 		}
 	}
 	return &s, nil
 }
+
+// pricingColumns returns the values of the price columns, the prices of the
+// restaurants being a JSON object
+func pricingColumns(p sushi.Pricing) (amount *int64, currency *string, prices *string, err error) {
+	if p.Price != nil {
+		amount, currency = &p.Price.Amount, &p.Price.Currency
+	}
+	if len(p.Prices) > 0 {
+		if prices, err = jsonColumn(p.Prices); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return amount, currency, prices, nil
+}
+
+// ingredientsColumn returns the value of the ingredients column, a JSON array
+// or NULL without ingredients
+func ingredientsColumn(ingredients []string) (*string, error) {
+	if len(ingredients) == 0 {
+		return nil, nil
+	}
+	return jsonColumn(ingredients)
+}
+
+// jsonColumn returns the value of a JSONB column. It's given as a string,
+// lib/pq would send bytes as BYTEA.
+func jsonColumn(v any) (*string, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	column := string(encoded)
+	return &column, nil
+}
+
+// scanSushi reads a row selected with the columns of the table in order
+func scanSushi(rows *sql.Rows) (sushi.Sushi, error) {
+	var (
+		s           sushi.Sushi
+		ingredients []byte
+		amount      *int64
+		currency    *string
+		prices      []byte
+	)
+	if err := rows.Scan(&s.ID, &s.ImageNumber, &s.Name, &ingredients, &amount, &currency, &prices, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return s, err
+	}
+	if len(ingredients) > 0 {
+		if err := json.Unmarshal(ingredients, &s.Ingredients); err != nil {
+			return s, fmt.Errorf("invalid ingredients of the sushi %s: %w", s.ID, err)
+		}
+	}
+	if amount != nil && currency != nil {
+		s.Price = &sushi.Money{Amount: *amount, Currency: *currency}
+	}
+	if len(prices) > 0 {
+		if err := json.Unmarshal(prices, &s.Prices); err != nil {
+			return s, fmt.Errorf("invalid prices of the sushi %s: %w", s.ID, err)
+		}
+	}
+	return s, nil
+}
+
+// Version satisfies the sushi.Versioner interface, the tag is the number of
+// writes of the sushis
+func (r sushiRepository) Version(ctx context.Context) (sushi.Version, error) {
+	return readVersion(ctx, r.db, sushisTable)
+}
+
+const sushisTable = "sushis"
+
+// exec runs the statement and counts it in the versions table in a single
+// transaction, unless it changed no row. It returns the rows affected.
+func exec(ctx context.Context, db *sql.DB, table, sqlStm string, args ...interface{}) (int64, error) {
+	return execChecked(ctx, db, nil, table, sqlStm, args...)
+}
+
+// execChecked runs exec once check passed in the same transaction
+func execChecked(ctx context.Context, db *sql.DB, check func(*sql.Tx) error, table, sqlStm string, args ...interface{}) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if check != nil {
+		if err := check(tx); err != nil {
+			return 0, err
+		}
+	}
+	result, err := tx.ExecContext(ctx, sqlStm, args...)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected > 0 {
+		_, err = tx.ExecContext(ctx, `INSERT INTO versions (name, version, modified) VALUES ($1, 1, NOW())
+				ON CONFLICT (name) DO UPDATE SET version = versions.version + 1, modified = excluded.modified`, table)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return rowsAffected, tx.Commit()
+}
+
+// readVersion returns the version of the table, it's 0 until a first write
+func readVersion(ctx context.Context, db *sql.DB, table string) (sushi.Version, error) {
+	var (
+		count    int64
+		modified time.Time
+	)
+	err := db.QueryRowContext(ctx, `SELECT version, modified FROM versions WHERE name=$1`, table).Scan(&count, &modified)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sushi.Version{Tag: "0"}, nil
+	}
+	if err != nil {
+		return sushi.Version{}, err
+	}
+	return sushi.Version{Tag: strconv.FormatInt(count, 10), Modified: modified}, nil
+}
+
+// Ping satisfies the sushi.Pinger interface
+func (r sushiRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Close releases the connection pool
+func (r sushiRepository) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,108 @@
+package inmem
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+type ingredientRepository struct {
+	mtx         sync.RWMutex
+	ingredients map[string]sushi.Ingredient
+
+	// epoch tells apart the versions of different processes
+	epoch    string
+	writes   uint64
+	modified time.Time
+}
+
+// NewIngredientRepository creates a catalogue of ingredients kept in memory
+func NewIngredientRepository(ingredients []sushi.Ingredient) sushi.IngredientRepository {
+	r := &ingredientRepository{
+		ingredients: make(map[string]sushi.Ingredient, len(ingredients)),
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		modified:    time.Now(),
+	}
+	for _, i := range ingredients {
+		r.ingredients[i.ID] = i
+	}
+	return r
+}
+
+func (r *ingredientRepository) CreateIngredient(ctx context.Context, i *sushi.Ingredient) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.ingredients[i.ID]; ok {
+		return fmt.Errorf("The ingredient %s already exists", i.ID)
+	}
+	r.ingredients[i.ID] = *i
+	r.touch()
+	return nil
+}
+
+// GetIngredients returns the ingredients ordered by ID
+func (r *ingredientRepository) GetIngredients(ctx context.Context) ([]sushi.Ingredient, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	values := make([]sushi.Ingredient, 0, len(r.ingredients))
+	for _, value := range r.ingredients {
+		values = append(values, value)
+	}
+	slices.SortFunc(values, func(a, b sushi.Ingredient) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return values, nil
+}
+
+func (r *ingredientRepository) GetIngredientByID(ctx context.Context, ID string) (*sushi.Ingredient, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	i, ok := r.ingredients[ID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", sushi.ErrIngredientNotFound, ID)
+	}
+	return &i, nil
+}
+
+func (r *ingredientRepository) UpdateIngredient(ctx context.Context, ID string, i *sushi.Ingredient) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.ingredients[ID]; !ok {
+		return fmt.Errorf("%w: %s", sushi.ErrIngredientNotFound, ID)
+	}
+	r.ingredients[ID] = *i
+	r.touch()
+	return nil
+}
+
+func (r *ingredientRepository) DeleteIngredient(ctx context.Context, ID string) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.ingredients[ID]; ok {
+		delete(r.ingredients, ID)
+		r.touch()
+	}
+	return nil
+}
+
+// touch records a write, the lock must be held
+func (r *ingredientRepository) touch() {
+	r.writes++
+	r.modified = time.Now()
+}
+
+// Version satisfies the sushi.Versioner interface, counting the writes
+func (r *ingredientRepository) Version(ctx context.Context) (sushi.Version, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return sushi.Version{
+		Tag:      r.epoch + "-" + strconv.FormatUint(r.writes, 36),
+		Modified: r.modified,
+	}, nil
+}
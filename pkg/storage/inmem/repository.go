@@ -3,7 +3,9 @@ package inmem
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
+	"time"
 
 	sushi "github.com/sergiorra/sushi-api-go/pkg"
 )
@@ -11,6 +13,11 @@ import (
 type sushiRepository struct {
 	mtx     sync.RWMutex
 	sushis 	map[string]sushi.Sushi
+
+	// epoch tells apart the versions of different processes
+	epoch    string
+	writes   uint64
+	modified time.Time
 }
 
 func NewRepository(sushis map[string]sushi.Sushi) sushi.Repository {
@@ -19,7 +26,9 @@ func NewRepository(sushis map[string]sushi.Sushi) sushi.Repository {
 	}
 
 	return &sushiRepository{
-		sushis: sushis,
+		sushis:   sushis,
+		epoch:    strconv.FormatInt(time.Now().UnixNano(), 36),
+		modified: time.Now(),
 	}
 }
 
@@ -30,6 +39,7 @@ func (r *sushiRepository) CreateSushi(ctx context.Context, s *sushi.Sushi) error
 		return err
 	}
 	r.sushis[s.ID] = *s
+	r.touch()
 	return nil
 }
 
@@ -59,7 +69,10 @@ func (r *sushiRepository) GetSushiByID(ctx context.Context, ID string) (*sushi.S
 func (r *sushiRepository) DeleteSushi(ctx context.Context, ID string) error {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
-	delete(r.sushis, ID)
+	if _, ok := r.sushis[ID]; ok {
+		delete(r.sushis, ID)
+		r.touch()
+	}
 
 	return nil
 }
@@ -68,6 +81,7 @@ func (r *sushiRepository) UpdateSushi(ctx context.Context, ID string, s *sushi.S
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 	r.sushis[ID] = *s
+	r.touch()
 	return nil
 }
 
@@ -79,4 +93,30 @@ func (r *sushiRepository) checkIfExists(ctx context.Context, ID string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// touch records a write, the lock must be held
+func (r *sushiRepository) touch() {
+	r.writes++
+	r.modified = time.Now()
+}
+
+// Version satisfies the sushi.Versioner interface, counting the writes
+func (r *sushiRepository) Version(ctx context.Context) (sushi.Version, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return sushi.Version{
+		Tag:      r.epoch + "-" + strconv.FormatUint(r.writes, 36),
+		Modified: r.modified,
+	}, nil
+}
+
+// Ping satisfies the sushi.Pinger interface, memory is always reachable
+func (r *sushiRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close satisfies the io.Closer interface, there is nothing to release
+func (r *sushiRepository) Close() error {
+	return nil
+}
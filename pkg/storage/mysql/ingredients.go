@@ -0,0 +1,229 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+/*
+	CREATE TABLE ingredients (
+		id VARCHAR(32) NOT NULL PRIMARY KEY,
+		name VARCHAR(100) NOT NULL,
+		allergens JSON NULL,
+		vegetarian BOOLEAN NOT NULL,
+		vegan BOOLEAN NOT NULL,
+		raw_fish BOOLEAN NOT NULL,
+		created_at DATETIME(6) NULL,
+		updated_at DATETIME(6) NULL
+	);
+*/
+
+type ingredientRepository struct {
+	table      string
+	sushiTable string
+	db         *sql.DB
+}
+
+// NewIngredientRepository instances a MySQL implementation of the
+// sushiapi.IngredientRepository, the sushis referencing the ingredients are
+// in sushiTable. Its writes count in the versions table too. It shares the
+// connection of the sushi repository, which closes it.
+func NewIngredientRepository(table, sushiTable string, db *sql.DB) sushiapi.IngredientRepository {
+	return ingredientRepository{table: table, sushiTable: sushiTable, db: db}
+}
+
+// CreateIngredient satisfies the sushiapi.IngredientRepository interface
+func (r ingredientRepository) CreateIngredient(ctx context.Context, i *sushiapi.Ingredient) error {
+	row, err := newSQLIngredient(i)
+	if err != nil {
+		return err
+	}
+	query, args := sqlbuilder.NewStruct(new(sqlIngredient)).InsertInto(r.table, row).Build()
+	_, err = exec(ctx, r.db, r.table, query, args...)
+	return err
+}
+
+// GetIngredients satisfies the sushiapi.IngredientRepository interface,
+// ordering the ingredients by ID
+func (r ingredientRepository) GetIngredients(ctx context.Context) ([]sushiapi.Ingredient, error) {
+	sqlIngredientStruct := sqlbuilder.NewStruct(new(sqlIngredient))
+	selectBuilder := sqlIngredientStruct.SelectFrom(r.table)
+	query, args := selectBuilder.OrderBy("id").Build()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	ingredients := []sushiapi.Ingredient{}
+	for rows.Next() {
+		row := sqlIngredient{}
+		if err := rows.Scan(sqlIngredientStruct.Addr(&row)...); err != nil {
+			return nil, err
+		}
+		ingredient, err := row.ingredient()
+		if err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, *ingredient)
+	}
+	return ingredients, rows.Err()
+}
+
+// GetIngredientByID satisfies the sushiapi.IngredientRepository interface
+func (r ingredientRepository) GetIngredientByID(ctx context.Context, ID string) (*sushiapi.Ingredient, error) {
+	sqlIngredientStruct := sqlbuilder.NewStruct(new(sqlIngredient))
+	selectBuilder := sqlIngredientStruct.SelectFrom(r.table)
+	query, args := selectBuilder.Where(
+		selectBuilder.Equal("id", ID),
+	).Build()
+
+	row := sqlIngredient{}
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(sqlIngredientStruct.Addr(&row)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", sushiapi.ErrIngredientNotFound, ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return row.ingredient()
+}
+
+// UpdateIngredient satisfies the sushiapi.IngredientRepository interface
+func (r ingredientRepository) UpdateIngredient(ctx context.Context, ID string, i *sushiapi.Ingredient) error {
+	row, err := newSQLIngredient(i)
+	if err != nil {
+		return err
+	}
+	updateBuilder := sqlbuilder.NewStruct(new(sqlIngredient)).Update(r.table, row)
+	query, args := updateBuilder.Where(
+		updateBuilder.Equal("id", ID),
+	).Build()
+
+	rowsAffected, err := exec(ctx, r.db, r.table, query, args...)
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", sushiapi.ErrIngredientNotFound, ID)
+	}
+	return nil
+}
+
+// DeleteIngredient satisfies the sushiapi.IngredientRepository interface
+func (r ingredientRepository) DeleteIngredient(ctx context.Context, ID string) error {
+	deleteBuilder := sqlbuilder.NewStruct(new(sqlIngredient)).DeleteFrom(r.table)
+	query, args := deleteBuilder.Where(
+		deleteBuilder.Equal("id", ID),
+	).Build()
+
+	_, err := exec(ctx, r.db, r.table, query, args...)
+	return err
+}
+
+// DeleteUnreferencedIngredient satisfies the sushiapi.UnreferencedDeleter
+// interface, reading the sushis made of the ingredient in the transaction
+// deleting it
+func (r ingredientRepository) DeleteUnreferencedIngredient(ctx context.Context, ID string) error {
+	deleteBuilder := sqlbuilder.NewStruct(new(sqlIngredient)).DeleteFrom(r.table)
+	query, args := deleteBuilder.Where(
+		deleteBuilder.Equal("id", ID),
+	).Build()
+
+	_, err := execChecked(ctx, r.db, func(tx *sql.Tx) error {
+		return r.checkUnreferenced(ctx, tx, ID)
+	}, r.table, query, args...)
+	return err
+}
+
+// checkUnreferenced returns an InUse error while some sushis are made of the
+// ingredient
+func (r ingredientRepository) checkUnreferenced(ctx context.Context, tx *sql.Tx, ID string) error {
+	selectBuilder := sqlbuilder.NewSelectBuilder().Select("id").From(r.sushiTable)
+	query, args := selectBuilder.Where(
+		"JSON_CONTAINS(ingredients, JSON_QUOTE("+selectBuilder.Var(ID)+"))",
+	).Build()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var sushiIDs []string
+	for rows.Next() {
+		var sushiID string
+		if err := rows.Scan(&sushiID); err != nil {
+			return err
+		}
+		sushiIDs = append(sushiIDs, sushiID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(sushiIDs) > 0 {
+		return sushiapi.InUse(ID, sushiIDs)
+	}
+	return nil
+}
+
+// Version satisfies the sushiapi.Versioner interface, the tag is the number
+// of writes of the table
+func (r ingredientRepository) Version(ctx context.Context) (sushiapi.Version, error) {
+	return readVersion(ctx, r.db, r.table)
+}
+
+// sqlIngredient is a row of the table, the allergens are a JSON array
+type sqlIngredient struct {
+	ID         string     `db:"id"`
+	Name       string     `db:"name"`
+	Allergens  []byte     `db:"allergens"`
+	Vegetarian bool       `db:"vegetarian"`
+	Vegan      bool       `db:"vegan"`
+	RawFish    bool       `db:"raw_fish"`
+	CreatedAt  *time.Time `db:"created_at"`
+	UpdatedAt  *time.Time `db:"updated_at"`
+}
+
+func newSQLIngredient(i *sushiapi.Ingredient) (sqlIngredient, error) {
+	row := sqlIngredient{
+		ID:         i.ID,
+		Name:       i.Name,
+		Vegetarian: i.Vegetarian,
+		Vegan:      i.Vegan,
+		RawFish:    i.RawFish,
+		CreatedAt:  i.CreatedAt,
+		UpdatedAt:  i.UpdatedAt,
+	}
+	if len(i.Allergens) > 0 {
+		allergens, err := json.Marshal(i.Allergens)
+		if err != nil {
+			return row, err
+		}
+		row.Allergens = allergens
+	}
+	return row, nil
+}
+
+func (s sqlIngredient) ingredient() (*sushiapi.Ingredient, error) {
+	i := sushiapi.NewIngredient(s.ID, s.Name, sushiapi.IngredientInfo{
+		Vegetarian: s.Vegetarian,
+		Vegan:      s.Vegan,
+		RawFish:    s.RawFish,
+	})
+	i.CreatedAt, i.UpdatedAt = s.CreatedAt, s.UpdatedAt
+	if len(s.Allergens) > 0 {
+		if err := json.Unmarshal(s.Allergens, &i.Allergens); err != nil {
+			return nil, fmt.Errorf("invalid allergens of the ingredient %s: %w", s.ID, err)
+		}
+	}
+	return i, nil
+}
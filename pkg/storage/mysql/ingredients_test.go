@@ -0,0 +1,89 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IngredientRepository_CreateIngredient(t *testing.T) {
+	db, sqlMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	salmon := sushiapi.NewIngredient("salmon", "Salmon", sushiapi.IngredientInfo{Allergens: []sushiapi.Allergen{sushiapi.Fish}, RawFish: true})
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(
+		"INSERT INTO ingredients (id, name, allergens, vegetarian, vegan, raw_fish, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)").
+		WithArgs("salmon", "Salmon", []byte(`["fish"]`), false, false, true, nil, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectExec(
+		"INSERT INTO versions (name, version, modified) VALUES (?, 1, ?) ON DUPLICATE KEY UPDATE version = version + 1, modified = VALUES(modified)").
+		WithArgs("ingredients", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectCommit()
+
+	repo := NewIngredientRepository("ingredients", "sushis", db)
+	assert.NoError(t, repo.CreateIngredient(context.Background(), salmon))
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func Test_IngredientRepository_GetIngredientByID(t *testing.T) {
+	db, sqlMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	query := "SELECT ingredients.id, ingredients.name, ingredients.allergens, ingredients.vegetarian, ingredients.vegan, ingredients.raw_fish, ingredients.created_at, ingredients.updated_at FROM ingredients WHERE id = ?"
+	columns := []string{"id", "name", "allergens", "vegetarian", "vegan", "raw_fish", "created_at", "updated_at"}
+
+	sqlMock.ExpectQuery(query).
+		WithArgs("salmon").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("salmon", "Salmon", []byte(`["fish"]`), false, false, true, nil, nil))
+	sqlMock.ExpectQuery(query).
+		WithArgs("tuna").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	repo := NewIngredientRepository("ingredients", "sushis", db)
+	salmon, err := repo.GetIngredientByID(context.Background(), "salmon")
+	require.NoError(t, err)
+	assert.Equal(t, sushiapi.NewIngredient("salmon", "Salmon", sushiapi.IngredientInfo{Allergens: []sushiapi.Allergen{sushiapi.Fish}, RawFish: true}), salmon)
+
+	_, err = repo.GetIngredientByID(context.Background(), "tuna")
+	assert.ErrorIs(t, err, sushiapi.ErrIngredientNotFound)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func Test_IngredientRepository_DeleteUnreferencedIngredient(t *testing.T) {
+	db, sqlMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	query := "SELECT id FROM sushis WHERE JSON_CONTAINS(ingredients, JSON_QUOTE(?))"
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(query).
+		WithArgs("salmon").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("01D3XZ7CN92").AddRow("01D3XZ38KDR"))
+	sqlMock.ExpectRollback()
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(query).
+		WithArgs("kombu").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	sqlMock.ExpectExec("DELETE FROM ingredients WHERE id = ?").
+		WithArgs("kombu").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectExec(
+		"INSERT INTO versions (name, version, modified) VALUES (?, 1, ?) ON DUPLICATE KEY UPDATE version = version + 1, modified = VALUES(modified)").
+		WithArgs("ingredients", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectCommit()
+
+	repo := NewIngredientRepository("ingredients", "sushis", db).(sushiapi.UnreferencedDeleter)
+	err = repo.DeleteUnreferencedIngredient(context.Background(), "salmon")
+	assert.ErrorIs(t, err, sushiapi.ErrIngredientInUse)
+	assert.ErrorContains(t, err, "salmon is an ingredient of 01D3XZ38KDR, 01D3XZ7CN92")
+
+	assert.NoError(t, repo.DeleteUnreferencedIngredient(context.Background(), "kombu"))
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
@@ -3,7 +3,11 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"iter"
+	"strconv"
 	"time"
 
 	// sqlbuilder builds SQL string automatically given some arguments (like table, object,...)
@@ -17,62 +21,77 @@ type sushiRepository struct {
 	db    *sql.DB
 }
 
-// NewRepository instances a MySQL implementation of the sushiapi.Repository
+// NewRepository instances a MySQL implementation of the sushiapi.Repository.
+// Its writes count in the versions table, see Version.
 func NewRepository(table string, db *sql.DB) sushiapi.Repository {
 	return sushiRepository{table: table, db: db}
 }
 
 // CreateSushi satisfies the sushiapi.Repository interface
 func (r sushiRepository) CreateSushi(ctx context.Context, g *sushiapi.Sushi) error {
-	insertBuilder := sqlbuilder.NewStruct(new(sqlSushi)).InsertInto(
-		r.table,
-		sqlSushi{
-			ID:        		g.ID,
-			ImageNumber:    g.ImageNumber,
-			Name:     		g.Name,
-			CreatedAt: 		g.CreatedAt,
-			UpdatedAt: 		g.UpdatedAt,
-		},
-	)
+	row, err := newSQLSushi(g)
+	if err != nil {
+		return err
+	}
+	insertBuilder := sqlbuilder.NewStruct(new(sqlSushi)).InsertInto(r.table, row)
 
 	query, args := insertBuilder.Build()
-	_, err := r.db.ExecContext(ctx, query, args...)
+	_, err = exec(ctx, r.db, r.table, query, args...)
 	return err
 }
 
 // GetSushis satisfies the sushiapi.Repository interface
 func (r sushiRepository) GetSushis(ctx context.Context) ([]sushiapi.Sushi, error) {
-	sqlSushiStruct := sqlbuilder.NewStruct(new(sqlSushi))
-
-	selectBuilder := sqlSushiStruct.SelectFrom(r.table)
-	query, args := selectBuilder.Build()
-
-	rows, err := r.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, err
+	var sushis []sushiapi.Sushi
+	for sushi, err := range r.StreamSushis(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		sushis = append(sushis, sushi)
 	}
 
-	defer func() { _ = rows.Close() }()
+	return sushis, nil
+}
+
+// StreamSushis satisfies the sushiapi.Streamer interface, scanning one row at a time
+func (r sushiRepository) StreamSushis(ctx context.Context) iter.Seq2[sushiapi.Sushi, error] {
+	return func(yield func(sushiapi.Sushi, error) bool) {
+		sqlSushiStruct := sqlbuilder.NewStruct(new(sqlSushi))
 
-	var sushis []sushiapi.Sushi
-	for rows.Next() {
-		sqlSushi := sqlSushi{}
+		selectBuilder := sqlSushiStruct.SelectFrom(r.table)
+		query, args := selectBuilder.Build()
 
-		err := rows.Scan(sqlSushiStruct.Addr(&sqlSushi)...)
+		rows, err := r.db.QueryContext(ctx, query, args...)
 		if err != nil {
-			return nil, err
+			yield(sushiapi.Sushi{}, err)
+			return
 		}
 
-		sushis = append(sushis, sushiapi.Sushi{
-			ID:        		sqlSushi.ID,
-			ImageNumber:    sqlSushi.ImageNumber,
-			Name:     		sqlSushi.Name,
-			CreatedAt: 		sqlSushi.CreatedAt,
-			UpdatedAt: 		sqlSushi.UpdatedAt,
-		})
-	}
+		defer func() { _ = rows.Close() }()
 
-	return sushis, nil
+		for rows.Next() {
+			sqlSushi := sqlSushi{}
+
+			err := rows.Scan(sqlSushiStruct.Addr(&sqlSushi)...)
+			if err != nil {
+				yield(sushiapi.Sushi{}, err)
+				return
+			}
+
+			sushi, err := sqlSushi.sushi()
+			if err != nil {
+				yield(sushiapi.Sushi{}, err)
+				return
+			}
+			if !yield(*sushi, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(sushiapi.Sushi{}, err)
+		}
+	}
 }
 
 // DeleteSushi satisfies the sushiapi.Repository interface
@@ -82,33 +101,27 @@ func (r sushiRepository) DeleteSushi(ctx context.Context, ID string) error {
 		deleteBuilder.Equal("id", ID),
 	).Build()
 
-	_, err := r.db.ExecContext(ctx, query, args...)
+	_, err := exec(ctx, r.db, r.table, query, args...)
 	return err
 }
 
 // UpdateSushi satisfies the sushiapi.Repository interface
 func (r sushiRepository) UpdateSushi(ctx context.Context, ID string, g *sushiapi.Sushi) error {
-	updateBuilder := sqlbuilder.NewStruct(new(sqlSushi)).Update(
-		r.table,
-		sqlSushi{
-			ID:        		g.ID,
-			ImageNumber:    g.ImageNumber,
-			Name:     		g.Name,
-			CreatedAt: 		g.CreatedAt,
-			UpdatedAt: 		g.UpdatedAt,
-		},
-	)
+	row, err := newSQLSushi(g)
+	if err != nil {
+		return err
+	}
+	updateBuilder := sqlbuilder.NewStruct(new(sqlSushi)).Update(r.table, row)
 
 	query, args := updateBuilder.Where(
 		updateBuilder.Equal("id", ID),
 	).Build()
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	rowsAffected, err := exec(ctx, r.db, r.table, query, args...)
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
 		return errors.New("not found")
 	}
@@ -135,19 +148,172 @@ func (r sushiRepository) GetSushiByID(ctx context.Context, ID string) (*sushiapi
 		return nil, err
 	}
 
-	return &sushiapi.Sushi{
-		ID:        		sqlSushi.ID,
-		ImageNumber:    sqlSushi.ImageNumber,
-		Name:     		sqlSushi.Name,
-		CreatedAt: 		sqlSushi.CreatedAt,
-		UpdatedAt: 		sqlSushi.UpdatedAt,
-	}, nil
+	return sqlSushi.sushi()
 }
 
+// sqlSushi is a row of the table, the ingredients are a JSON array and the
+// prices of the restaurants a JSON object of the restaurant IDs
 type sqlSushi struct {
 	ID        		string     `db:"id"`
 	ImageNumber     string     `db:"image_number"`
 	Name     		string     `db:"name"`
+	Ingredients		[]byte     `db:"ingredients"`
+	PriceAmount		*int64     `db:"price_amount"`
+	PriceCurrency	*string    `db:"price_currency"`
+	Prices			[]byte     `db:"prices"`
 	CreatedAt 		*time.Time `db:"created_at"`
 	UpdatedAt 		*time.Time `db:"updated_at"`
-}
\ No newline at end of file
+}
+
+func newSQLSushi(g *sushiapi.Sushi) (sqlSushi, error) {
+	row := sqlSushi{
+		ID:        		g.ID,
+		ImageNumber:    g.ImageNumber,
+		Name:     		g.Name,
+		CreatedAt: 		g.CreatedAt,
+		UpdatedAt: 		g.UpdatedAt,
+	}
+	if len(g.Ingredients) > 0 {
+		ingredients, err := json.Marshal(g.Ingredients)
+		if err != nil {
+			return row, err
+		}
+		row.Ingredients = ingredients
+	}
+	if g.Price != nil {
+		row.PriceAmount, row.PriceCurrency = &g.Price.Amount, &g.Price.Currency
+	}
+	if len(g.Prices) > 0 {
+		prices, err := json.Marshal(g.Prices)
+		if err != nil {
+			return row, err
+		}
+		row.Prices = prices
+	}
+	return row, nil
+}
+
+func (s sqlSushi) sushi() (*sushiapi.Sushi, error) {
+	g := &sushiapi.Sushi{
+		ID:        		s.ID,
+		ImageNumber:    s.ImageNumber,
+		Name:     		s.Name,
+		CreatedAt: 		s.CreatedAt,
+		UpdatedAt: 		s.UpdatedAt,
+	}
+	if len(s.Ingredients) > 0 {
+		if err := json.Unmarshal(s.Ingredients, &g.Ingredients); err != nil {
+			return nil, fmt.Errorf("invalid ingredients of the sushi %s: %w", s.ID, err)
+		}
+	}
+	if s.PriceAmount != nil && s.PriceCurrency != nil {
+		g.Price = &sushiapi.Money{Amount: *s.PriceAmount, Currency: *s.PriceCurrency}
+	}
+	if len(s.Prices) > 0 {
+		if err := json.Unmarshal(s.Prices, &g.Prices); err != nil {
+			return nil, fmt.Errorf("invalid prices of the sushi %s: %w", s.ID, err)
+		}
+	}
+	return g, nil
+}
+
+// Version satisfies the sushiapi.Versioner interface, the tag is the number
+// of writes of the table
+func (r sushiRepository) Version(ctx context.Context) (sushiapi.Version, error) {
+	return readVersion(ctx, r.db, r.table)
+}
+
+/*
+	The sushis table, named by the configuration:
+
+	CREATE TABLE sushis (
+		id VARCHAR(32) NOT NULL PRIMARY KEY,
+		image_number VARCHAR(100) NOT NULL,
+		name VARCHAR(255) NULL,
+		ingredients JSON NULL,
+		price_amount BIGINT NULL,
+		price_currency CHAR(3) NULL,
+		prices JSON NULL,
+		created_at DATETIME(6) NULL,
+		updated_at DATETIME(6) NULL
+	);
+
+	The tables created before the ingredients and the prices were stored
+	need the columns added:
+
+	ALTER TABLE sushis
+		ADD COLUMN ingredients JSON NULL AFTER name,
+		ADD COLUMN price_amount BIGINT NULL AFTER ingredients,
+		ADD COLUMN price_currency CHAR(3) NULL AFTER price_amount,
+		ADD COLUMN prices JSON NULL AFTER price_currency;
+
+	The versions table counts the writes of the other tables, a row by table:
+
+	CREATE TABLE versions (
+		name VARCHAR(64) NOT NULL PRIMARY KEY,
+		version BIGINT NOT NULL,
+		modified DATETIME(6) NOT NULL
+	);
+*/
+const versionsTable = "versions"
+
+// exec runs the statement and counts it in the versions table in a single
+// transaction, unless it changed no row. It returns the rows affected.
+func exec(ctx context.Context, db *sql.DB, table, query string, args ...interface{}) (int64, error) {
+	return execChecked(ctx, db, nil, table, query, args...)
+}
+
+// execChecked runs exec once check passed in the same transaction
+func execChecked(ctx context.Context, db *sql.DB, check func(*sql.Tx) error, table, query string, args ...interface{}) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if check != nil {
+		if err := check(tx); err != nil {
+			return 0, err
+		}
+	}
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected > 0 {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO "+versionsTable+" (name, version, modified) VALUES (?, 1, ?) ON DUPLICATE KEY UPDATE version = version + 1, modified = VALUES(modified)",
+			table, time.Now())
+		if err != nil {
+			return 0, err
+		}
+	}
+	return rowsAffected, tx.Commit()
+}
+
+// readVersion returns the version of the table, it's 0 until a first write
+func readVersion(ctx context.Context, db *sql.DB, table string) (sushiapi.Version, error) {
+	var (
+		count    int64
+		modified time.Time
+	)
+	err := db.QueryRowContext(ctx, "SELECT version, modified FROM "+versionsTable+" WHERE name = ?", table).Scan(&count, &modified)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sushiapi.Version{Tag: "0"}, nil
+	}
+	if err != nil {
+		return sushiapi.Version{}, err
+	}
+	return sushiapi.Version{Tag: strconv.FormatInt(count, 10), Modified: modified}, nil
+}
+
+// Ping satisfies the sushiapi.Pinger interface
+func (r sushiRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Close releases the connection pool
+func (r sushiRepository) Close() error {
+	return r.db.Close()
+}
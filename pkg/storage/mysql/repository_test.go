@@ -21,10 +21,12 @@ func Test_SushiRepository_CreateSushi_RepositoryError(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	sqlMock.ExpectBegin()
 	sqlMock.ExpectExec(
-		"INSERT INTO sushis (id, image_number, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)").
-		WithArgs(sushi.ID, sushi.ImageNumber, sushi.Name, sushi.CreatedAt, sushi.UpdatedAt).
+		"INSERT INTO sushis (id, image_number, name, ingredients, price_amount, price_currency, prices, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)").
+		WithArgs(sushi.ID, sushi.ImageNumber, sushi.Name, []byte(`["rice","salmon"]`), nil, nil, []byte(nil), sushi.CreatedAt, sushi.UpdatedAt).
 		WillReturnError(errors.New("database failed"))
+	sqlMock.ExpectRollback()
 
 	repo := NewRepository("sushis", db)
 	err = repo.CreateSushi(context.Background(), &sushi)
@@ -41,10 +43,16 @@ func Test_SushiRepository_CreateSushi_Success(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	sqlMock.ExpectBegin()
 	sqlMock.ExpectExec(
-		"INSERT INTO sushis (id, image_number, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)").
-		WithArgs(sushi.ID, sushi.ImageNumber, sushi.Name, sushi.CreatedAt, sushi.UpdatedAt).
+		"INSERT INTO sushis (id, image_number, name, ingredients, price_amount, price_currency, prices, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)").
+		WithArgs(sushi.ID, sushi.ImageNumber, sushi.Name, []byte(`["rice","salmon"]`), nil, nil, []byte(nil), sushi.CreatedAt, sushi.UpdatedAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectExec(
+		"INSERT INTO versions (name, version, modified) VALUES (?, 1, ?) ON DUPLICATE KEY UPDATE version = version + 1, modified = VALUES(modified)").
+		WithArgs("sushis", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectCommit()
 
 	repo := NewRepository("sushis", db)
 	err = repo.CreateSushi(context.Background(), &sushi)
@@ -60,7 +68,7 @@ func Test_SushiRepository_GetSushis_RepositoryError(t *testing.T) {
 	}
 
 	sqlMock.ExpectQuery(
-		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.created_at, sushis.updated_at FROM sushis").
+		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.ingredients, sushis.price_amount, sushis.price_currency, sushis.prices, sushis.created_at, sushis.updated_at FROM sushis").
 		WillReturnError(errors.New("something-failed"))
 
 	repo := NewRepository("sushis", db)
@@ -77,9 +85,9 @@ func Test_SushiRepository_GetSushis_NoRows(t *testing.T) {
 	}
 
 	sqlMock.ExpectQuery(
-		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.created_at, sushis.updated_at FROM sushis").
+		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.ingredients, sushis.price_amount, sushis.price_currency, sushis.prices, sushis.created_at, sushis.updated_at FROM sushis").
 		WillReturnRows(sqlmock.NewRows(
-			[]string{"id", "image_number", "name", "created_at", "updated_at"}),
+			[]string{"id", "image_number", "name", "ingredients", "price_amount", "price_currency", "prices", "created_at", "updated_at"}),
 		)
 
 	repo := NewRepository("sushis", db)
@@ -98,10 +106,10 @@ func Test_SushiRepository_GetSushis_RowWithInvalidData(t *testing.T) {
 	}
 
 	sqlMock.ExpectQuery(
-		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.created_at, sushis.updated_at FROM sushis").
+		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.ingredients, sushis.price_amount, sushis.price_currency, sushis.prices, sushis.created_at, sushis.updated_at FROM sushis").
 		WillReturnRows(sqlmock.NewRows(
-			[]string{"id", "image_number", "name", "created_at", "updated_at"}).
-			AddRow(nil, nil, nil, nil, nil), // This is a row failure as the data type is wrong
+			[]string{"id", "image_number", "name", "ingredients", "price_amount", "price_currency", "prices", "created_at", "updated_at"}).
+			AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil), // This is a row failure as the data type is wrong
 		)
 
 	repo := NewRepository("sushis", db)
@@ -123,11 +131,11 @@ func Test_SushiRepository_GetSushis_Succeeded(t *testing.T) {
 	}
 
 	sqlMock.ExpectQuery(
-		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.created_at, sushis.updated_at FROM sushis").
+		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.ingredients, sushis.price_amount, sushis.price_currency, sushis.prices, sushis.created_at, sushis.updated_at FROM sushis").
 		WillReturnRows(sqlmock.NewRows(
-			[]string{"id", "image_number", "name", "created_at", "updated_at"}).
-			AddRow(expectedSushis[0].ID, expectedSushis[0].ImageNumber, expectedSushis[0].Name, expectedSushis[0].CreatedAt, expectedSushis[0].UpdatedAt).
-			AddRow(expectedSushis[1].ID, expectedSushis[1].ImageNumber, expectedSushis[1].Name, expectedSushis[1].CreatedAt, expectedSushis[1].UpdatedAt),
+			[]string{"id", "image_number", "name", "ingredients", "price_amount", "price_currency", "prices", "created_at", "updated_at"}).
+			AddRow(expectedSushis[0].ID, expectedSushis[0].ImageNumber, expectedSushis[0].Name, []byte(`["rice","salmon"]`), nil, nil, nil, expectedSushis[0].CreatedAt, expectedSushis[0].UpdatedAt).
+			AddRow(expectedSushis[1].ID, expectedSushis[1].ImageNumber, expectedSushis[1].Name, []byte(`["rice","salmon"]`), nil, nil, nil, expectedSushis[1].CreatedAt, expectedSushis[1].UpdatedAt),
 		)
 
 	repo := NewRepository("sushis", db)
@@ -146,10 +154,12 @@ func Test_SushiRepository_DeleteSushi_RepositoryError(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	sqlMock.ExpectBegin()
 	sqlMock.ExpectExec(
 		"DELETE FROM sushis WHERE id = ?").
 		WithArgs(sushiID).
 		WillReturnError(errors.New("database failed"))
+	sqlMock.ExpectRollback()
 
 	repo := NewRepository("sushis", db)
 	err = repo.DeleteSushi(context.Background(), sushiID)
@@ -166,10 +176,16 @@ func Test_SushiRepository_DeleteSushi_Success(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	sqlMock.ExpectBegin()
 	sqlMock.ExpectExec(
 		"DELETE FROM sushis WHERE id = ?").
 		WithArgs(sushiID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectExec(
+		"INSERT INTO versions (name, version, modified) VALUES (?, 1, ?) ON DUPLICATE KEY UPDATE version = version + 1, modified = VALUES(modified)").
+		WithArgs("sushis", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectCommit()
 
 	repo := NewRepository("sushis", db)
 	err = repo.DeleteSushi(context.Background(), sushiID)
@@ -186,10 +202,12 @@ func Test_SushiRepository_UpdateSushi_RepositoryError(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	sqlMock.ExpectBegin()
 	sqlMock.ExpectExec(
-		"UPDATE sushis SET id = ?, image_number = ?, name = ?, created_at = ?, updated_at = ? WHERE id = ?").
-		WithArgs(sushi.ID, sushi.ImageNumber, sushi.Name, sushi.CreatedAt, sushi.UpdatedAt, sushi.ID).
+		"UPDATE sushis SET id = ?, image_number = ?, name = ?, ingredients = ?, price_amount = ?, price_currency = ?, prices = ?, created_at = ?, updated_at = ? WHERE id = ?").
+		WithArgs(sushi.ID, sushi.ImageNumber, sushi.Name, []byte(`["rice","salmon"]`), nil, nil, []byte(nil), sushi.CreatedAt, sushi.UpdatedAt, sushi.ID).
 		WillReturnError(errors.New("database failed"))
+	sqlMock.ExpectRollback()
 
 	repo := NewRepository("sushis", db)
 	err = repo.UpdateSushi(context.Background(), sushi.ID, &sushi)
@@ -206,10 +224,12 @@ func Test_SushiRepository_UpdateSushi_NotFound(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	sqlMock.ExpectBegin()
 	sqlMock.ExpectExec(
-		"UPDATE sushis SET id = ?, image_number = ?, name = ?, created_at = ?, updated_at = ? WHERE id = ?").
-		WithArgs(sushi.ID, sushi.ImageNumber, sushi.Name, sushi.CreatedAt, sushi.UpdatedAt, sushi.ID).
+		"UPDATE sushis SET id = ?, image_number = ?, name = ?, ingredients = ?, price_amount = ?, price_currency = ?, prices = ?, created_at = ?, updated_at = ? WHERE id = ?").
+		WithArgs(sushi.ID, sushi.ImageNumber, sushi.Name, []byte(`["rice","salmon"]`), nil, nil, []byte(nil), sushi.CreatedAt, sushi.UpdatedAt, sushi.ID).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectCommit()
 
 	repo := NewRepository("sushis", db)
 	err = repo.UpdateSushi(context.Background(), sushi.ID, &sushi)
@@ -226,10 +246,16 @@ func Test_SushiRepository_UpdateSushi_Success(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
+	sqlMock.ExpectBegin()
 	sqlMock.ExpectExec(
-		"UPDATE sushis SET id = ?, image_number = ?, name = ?, created_at = ?, updated_at = ? WHERE id = ?").
-		WithArgs(sushi.ID, sushi.ImageNumber, sushi.Name, sushi.CreatedAt, sushi.UpdatedAt, sushi.ID).
+		"UPDATE sushis SET id = ?, image_number = ?, name = ?, ingredients = ?, price_amount = ?, price_currency = ?, prices = ?, created_at = ?, updated_at = ? WHERE id = ?").
+		WithArgs(sushi.ID, sushi.ImageNumber, sushi.Name, []byte(`["rice","salmon"]`), nil, nil, []byte(nil), sushi.CreatedAt, sushi.UpdatedAt, sushi.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectExec(
+		"INSERT INTO versions (name, version, modified) VALUES (?, 1, ?) ON DUPLICATE KEY UPDATE version = version + 1, modified = VALUES(modified)").
+		WithArgs("sushis", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectCommit()
 
 	repo := NewRepository("sushis", db)
 	err = repo.UpdateSushi(context.Background(), sushi.ID, &sushi)
@@ -247,7 +273,7 @@ func Test_SushiRepository_GetSushiByID_RepositoryError(t *testing.T) {
 	}
 
 	sqlMock.ExpectQuery(
-		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.created_at, sushis.updated_at FROM sushis WHERE id = ?").
+		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.ingredients, sushis.price_amount, sushis.price_currency, sushis.prices, sushis.created_at, sushis.updated_at FROM sushis WHERE id = ?").
 		WithArgs(sushiID).
 		WillReturnError(errors.New("something-failed"))
 
@@ -267,10 +293,10 @@ func Test_SushiRepository_GetSushiByID_NoRows(t *testing.T) {
 	}
 
 	sqlMock.ExpectQuery(
-		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.created_at, sushis.updated_at FROM sushis WHERE id = ?").
+		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.ingredients, sushis.price_amount, sushis.price_currency, sushis.prices, sushis.created_at, sushis.updated_at FROM sushis WHERE id = ?").
 		WithArgs(sushiID).
 		WillReturnRows(sqlmock.NewRows(
-			[]string{"id", "image_number", "name", "created_at", "updated_at"}),
+			[]string{"id", "image_number", "name", "ingredients", "price_amount", "price_currency", "prices", "created_at", "updated_at"}),
 		)
 
 	repo := NewRepository("sushis", db)
@@ -289,10 +315,10 @@ func Test_SushiRepository_GetSushiByID_RowWithInvalidData(t *testing.T) {
 	}
 
 	sqlMock.ExpectQuery(
-		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.created_at, sushis.updated_at FROM sushis WHERE id = ?").
+		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.ingredients, sushis.price_amount, sushis.price_currency, sushis.prices, sushis.created_at, sushis.updated_at FROM sushis WHERE id = ?").
 		WillReturnRows(sqlmock.NewRows(
-			[]string{"id", "image_number", "name", "created_at", "updated_at"}).
-			AddRow(nil, nil, nil, nil, nil), // This is a row failure as the data type is wrong
+			[]string{"id", "image_number", "name", "ingredients", "price_amount", "price_currency", "prices", "created_at", "updated_at"}).
+			AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil), // This is a row failure as the data type is wrong
 		)
 
 	repo := NewRepository("sushis", db)
@@ -311,12 +337,12 @@ func Test_SushiRepository_GetSushiByID_Succeeded(t *testing.T) {
 	}
 
 	sqlMock.ExpectQuery(
-		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.created_at, sushis.updated_at FROM sushis WHERE id = ?",
+		"SELECT sushis.id, sushis.image_number, sushis.name, sushis.ingredients, sushis.price_amount, sushis.price_currency, sushis.prices, sushis.created_at, sushis.updated_at FROM sushis WHERE id = ?",
 	).
 		WithArgs(expectedSushi.ID).
 		WillReturnRows(sqlmock.NewRows(
-			[]string{"id", "image_number", "name", "created_at", "updated_at"}).
-			AddRow(expectedSushi.ID, expectedSushi.ImageNumber, expectedSushi.Name, expectedSushi.CreatedAt, expectedSushi.UpdatedAt),
+			[]string{"id", "image_number", "name", "ingredients", "price_amount", "price_currency", "prices", "created_at", "updated_at"}).
+			AddRow(expectedSushi.ID, expectedSushi.ImageNumber, expectedSushi.Name, []byte(`["rice","salmon"]`), nil, nil, nil, expectedSushi.CreatedAt, expectedSushi.UpdatedAt),
 		)
 
 	repo := NewRepository("sushis", db)
@@ -327,12 +353,51 @@ func Test_SushiRepository_GetSushiByID_Succeeded(t *testing.T) {
 	assert.Equal(t, &expectedSushi, sushi)
 }
 
+func Test_SushiRepository_Version_Succeeded(t *testing.T) {
+	modified := time.Now()
+
+	db, sqlMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	sqlMock.ExpectQuery("SELECT version, modified FROM versions WHERE name = ?").
+		WithArgs("sushis").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "modified"}).AddRow(42, modified))
+
+	repo := NewRepository("sushis", db).(sushiapi.Versioner)
+	version, err := repo.Version(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	assert.Equal(t, sushiapi.Version{Tag: "42", Modified: modified}, version)
+}
+
+func Test_SushiRepository_Version_NeverWritten(t *testing.T) {
+	db, sqlMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	sqlMock.ExpectQuery("SELECT version, modified FROM versions WHERE name = ?").
+		WithArgs("sushis").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "modified"}))
+
+	repo := NewRepository("sushis", db).(sushiapi.Versioner)
+	version, err := repo.Version(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	assert.Equal(t, sushiapi.Version{Tag: "0"}, version)
+}
+
 func buildSushi() sushiapi.Sushi {
 	now := time.Now()
 	return sushiapi.Sushi{
 		ID:        		"123ABC",
 		ImageNumber: 	"Test_image",
 		Name:      		"Test_name",
+		Ingredients: 	[]string{"rice", "salmon"},
 		CreatedAt: 		&now,
 		UpdatedAt: 		&now,
 	}
@@ -38,8 +38,19 @@ func Test_GopherRepository_Example(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, sushiB, *result)
 
-	// AND they can be fetched in batch
+	// AND they can be fetched in batch, ignoring the keys of the other stores
+	s.HSet("ratelimit:ip:127.0.0.1", "tokens", "9")
+	assert.NoError(t, s.Set("idempotency:principal:chef:1", "{}"))
+	assert.NoError(t, s.Set("cache:sushi:123ABC", "{}"))
+	assert.NoError(t, s.Set("ingredient:salmon", "{}"))
 	results, err := repo.GetSushis(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, []sushi.Sushi{sushiA, sushiB}, results)
+
+	var streamed []sushi.Sushi
+	for g, err := range repo.(sushi.Streamer).StreamSushis(context.Background()) {
+		assert.NoError(t, err)
+		streamed = append(streamed, g)
+	}
+	assert.ElementsMatch(t, []sushi.Sushi{sushiA, sushiB}, streamed)
 }
\ No newline at end of file
@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	onlyIfMissing = "NX"
+
+	// ingredientKeyPrefix namespaces the keys of the ingredient catalogue,
+	// apart from the sushis
+	ingredientKeyPrefix = "ingredient:"
+)
+
+type ingredientRepository struct {
+	pool *redis.Pool
+}
+
+// NewIngredientRepository instances a Redis implementation of the
+// sushiapi.IngredientRepository. Like the sushis, it isn't versioned. It
+// shares the pool of the sushi repository, which closes it.
+func NewIngredientRepository(pool *redis.Pool) sushiapi.IngredientRepository {
+	return ingredientRepository{pool: pool}
+}
+
+func ingredientKey(ID string) string {
+	return ingredientKeyPrefix + ID
+}
+
+// redisIngredient is the stored value of an ingredient, with the times the
+// API leaves out
+type redisIngredient struct {
+	sushiapi.Ingredient
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+func marshalIngredient(i *sushiapi.Ingredient) (string, error) {
+	bytes, err := json.Marshal(redisIngredient{Ingredient: *i, CreatedAt: i.CreatedAt, UpdatedAt: i.UpdatedAt})
+	return string(bytes), err
+}
+
+func unmarshalIngredient(data []byte) (sushiapi.Ingredient, error) {
+	var stored redisIngredient
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return sushiapi.Ingredient{}, err
+	}
+	i := stored.Ingredient
+	i.CreatedAt, i.UpdatedAt = stored.CreatedAt, stored.UpdatedAt
+	return i, nil
+}
+
+// CreateIngredient satisfies the sushiapi.IngredientRepository interface
+func (r ingredientRepository) CreateIngredient(ctx context.Context, i *sushiapi.Ingredient) error {
+	value, err := marshalIngredient(i)
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.Do("SET", ingredientKey(i.ID), value, onlyIfMissing)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return fmt.Errorf("The ingredient %s already exists", i.ID)
+	}
+	return nil
+}
+
+// GetIngredients satisfies the sushiapi.IngredientRepository interface,
+// ordering the ingredients by ID
+func (r ingredientRepository) GetIngredients(ctx context.Context) ([]sushiapi.Ingredient, error) {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	keys, err := redis.Strings(conn.Do("KEYS", ingredientKeyPrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return []sushiapi.Ingredient{}, nil
+	}
+
+	args := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	results, err := redis.ByteSlices(conn.Do("MGET", args...))
+	if err != nil {
+		return nil, err
+	}
+
+	ingredients := make([]sushiapi.Ingredient, 0, len(results))
+	for _, result := range results {
+		// the key was removed since it was listed
+		if result == nil {
+			continue
+		}
+		i, err := unmarshalIngredient(result)
+		if err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, i)
+	}
+	slices.SortFunc(ingredients, func(a, b sushiapi.Ingredient) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return ingredients, nil
+}
+
+// GetIngredientByID satisfies the sushiapi.IngredientRepository interface
+func (r ingredientRepository) GetIngredientByID(ctx context.Context, ID string) (*sushiapi.Ingredient, error) {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	result, err := redis.Bytes(conn.Do("GET", ingredientKey(ID)))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, fmt.Errorf("%w: %s", sushiapi.ErrIngredientNotFound, ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := unmarshalIngredient(result)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// UpdateIngredient satisfies the sushiapi.IngredientRepository interface
+func (r ingredientRepository) UpdateIngredient(ctx context.Context, ID string, i *sushiapi.Ingredient) error {
+	value, err := marshalIngredient(i)
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.Do("SET", ingredientKey(ID), value, onlyIfExists)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return fmt.Errorf("%w: %s", sushiapi.ErrIngredientNotFound, ID)
+	}
+	return nil
+}
+
+// DeleteIngredient satisfies the sushiapi.IngredientRepository interface
+func (r ingredientRepository) DeleteIngredient(ctx context.Context, ID string) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("DEL", ingredientKey(ID))
+	return err
+}
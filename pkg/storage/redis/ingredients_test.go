@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IngredientRepository_CreateIngredient(t *testing.T) {
+	salmon := buildIngredient()
+	value, err := marshalIngredient(&salmon)
+	require.NoError(t, err)
+
+	conn := redigomock.NewConn()
+	conn.Command("SET", "ingredient:salmon", value, "NX").Expect("OK").Expect(nil)
+
+	repo := NewIngredientRepository(wrapRedisConn(conn))
+	assert.NoError(t, repo.CreateIngredient(context.Background(), &salmon))
+	assert.ErrorContains(t, repo.CreateIngredient(context.Background(), &salmon), "already exists")
+	assert.NoError(t, conn.ExpectationsWereMet())
+}
+
+func Test_IngredientRepository_GetIngredientByID(t *testing.T) {
+	salmon := buildIngredient()
+	value, err := marshalIngredient(&salmon)
+	require.NoError(t, err)
+
+	conn := redigomock.NewConn()
+	conn.Command("GET", "ingredient:salmon").Expect(value)
+	conn.Command("GET", "ingredient:tuna").Expect(nil)
+
+	repo := NewIngredientRepository(wrapRedisConn(conn))
+	ingredient, err := repo.GetIngredientByID(context.Background(), "salmon")
+	require.NoError(t, err)
+	assert.Equal(t, &salmon, ingredient)
+
+	_, err = repo.GetIngredientByID(context.Background(), "tuna")
+	assert.ErrorIs(t, err, sushiapi.ErrIngredientNotFound)
+	assert.NoError(t, conn.ExpectationsWereMet())
+}
+
+func Test_IngredientRepository_UpdateIngredient_NotFound(t *testing.T) {
+	salmon := buildIngredient()
+	value, err := marshalIngredient(&salmon)
+	require.NoError(t, err)
+
+	conn := redigomock.NewConn()
+	conn.Command("SET", "ingredient:salmon", value, "XX").Expect(nil)
+
+	repo := NewIngredientRepository(wrapRedisConn(conn))
+	err = repo.UpdateIngredient(context.Background(), "salmon", &salmon)
+
+	assert.ErrorIs(t, err, sushiapi.ErrIngredientNotFound)
+	assert.NoError(t, conn.ExpectationsWereMet())
+}
+
+func Test_IngredientRepository_GetIngredients(t *testing.T) {
+	salmon, rice := buildIngredient(), *sushiapi.NewIngredient("rice", "Rice", sushiapi.IngredientInfo{Vegetarian: true, Vegan: true})
+	salmonValue, err := marshalIngredient(&salmon)
+	require.NoError(t, err)
+	riceValue, err := marshalIngredient(&rice)
+	require.NoError(t, err)
+
+	conn := redigomock.NewConn()
+	conn.Command("KEYS", "ingredient:*").Expect([]interface{}{"ingredient:salmon", "ingredient:rice"})
+	conn.Command("MGET", "ingredient:salmon", "ingredient:rice").Expect([]interface{}{[]byte(salmonValue), []byte(riceValue)})
+
+	repo := NewIngredientRepository(wrapRedisConn(conn))
+	ingredients, err := repo.GetIngredients(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoError(t, conn.ExpectationsWereMet())
+	assert.Equal(t, []sushiapi.Ingredient{rice, salmon}, ingredients)
+}
+
+func buildIngredient() sushiapi.Ingredient {
+	created := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	salmon := sushiapi.NewIngredient("salmon", "Salmon", sushiapi.IngredientInfo{Allergens: []sushiapi.Allergen{sushiapi.Fish}, RawFish: true})
+	salmon.CreatedAt = &created
+	return *salmon
+}
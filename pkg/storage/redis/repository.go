@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"iter"
+	"strings"
 
 	sushiapi "github.com/sergiorra/sushi-api-go/pkg"
 
@@ -13,8 +15,29 @@ import (
 
 const (
 	onlyIfExists = "XX"
+
+	// scanCount is the number of keys fetched per SCAN while streaming
+	scanCount = 100
+)
+
+// The key prefixes of the stores sharing Redis with the sushis, whose keys
+// are their IDs
+const (
+	CacheKeyPrefix       = "cache:"
+	RateLimitKeyPrefix   = "ratelimit:"
+	IdempotencyKeyPrefix = "idempotency:"
 )
 
+// isSushiKey tells apart the keys of the sushis from those of the other stores
+func isSushiKey(key string) bool {
+	for _, prefix := range []string{CacheKeyPrefix, RateLimitKeyPrefix, IdempotencyKeyPrefix, ingredientKeyPrefix} {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
 type sushiRepository struct {
 	pool *redis.Pool
 }
@@ -54,13 +77,15 @@ func (s sushiRepository) GetSushis(ctx context.Context) ([]sushiapi.Sushi, error
 		return nil, err
 	}
 
-	if len(keys) == 0 {
-		return []sushiapi.Sushi{}, nil
-	}
-
 	args := make([]interface{}, 0, len(keys))
 	for _, key := range keys {
-		args = append(args, key)
+		if isSushiKey(key) {
+			args = append(args, key)
+		}
+	}
+
+	if len(args) == 0 {
+		return []sushiapi.Sushi{}, nil
 	}
 
 	results, err := redis.Strings(conn.Do("MGET", args...))
@@ -82,6 +107,70 @@ func (s sushiRepository) GetSushis(ctx context.Context) ([]sushiapi.Sushi, error
 	return sushis, nil
 }
 
+// StreamSushis satisfies the sushiapi.Streamer interface, walking the keys
+// with SCAN so only a batch is held at a time. As SCAN guarantees, a sushi
+// modified during the iteration may be yielded twice.
+func (s sushiRepository) StreamSushis(ctx context.Context) iter.Seq2[sushiapi.Sushi, error] {
+	return func(yield func(sushiapi.Sushi, error) bool) {
+		conn, err := s.pool.GetContext(ctx)
+		if err != nil {
+			yield(sushiapi.Sushi{}, err)
+			return
+		}
+		defer conn.Close()
+
+		cursor := 0
+		for {
+			reply, err := redis.Values(conn.Do("SCAN", cursor, "COUNT", scanCount))
+			if err != nil {
+				yield(sushiapi.Sushi{}, err)
+				return
+			}
+			var scanned [][]byte
+			if _, err := redis.Scan(reply, &cursor, &scanned); err != nil {
+				yield(sushiapi.Sushi{}, err)
+				return
+			}
+			var keys []interface{}
+			for _, key := range scanned {
+				if isSushiKey(string(key)) {
+					keys = append(keys, key)
+				}
+			}
+
+			if len(keys) > 0 {
+				results, err := redis.ByteSlices(conn.Do("MGET", keys...))
+				if err != nil {
+					yield(sushiapi.Sushi{}, err)
+					return
+				}
+				for _, result := range results {
+					// the key was removed since it was scanned
+					if result == nil {
+						continue
+					}
+					sushi := sushiapi.Sushi{}
+					if err := json.Unmarshal(result, &sushi); err != nil {
+						yield(sushiapi.Sushi{}, err)
+						return
+					}
+					if !yield(sushi, nil) {
+						return
+					}
+				}
+			}
+
+			if err := ctx.Err(); err != nil {
+				yield(sushiapi.Sushi{}, err)
+				return
+			}
+			if cursor == 0 {
+				return
+			}
+		}
+	}
+}
+
 // GetSushiByID satisfies the sushiapi.Repository interface
 func (s sushiRepository) GetSushiByID(ctx context.Context, ID string) (*sushiapi.Sushi, error) {
 	conn, err := s.pool.GetContext(ctx)
@@ -133,3 +222,20 @@ func (s sushiRepository) UpdateSushi(ctx context.Context, ID string, sushi *sush
 	}
 	return err
 }
+
+// Ping satisfies the sushiapi.Pinger interface
+func (s sushiRepository) Ping(ctx context.Context) error {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("PING")
+	return err
+}
+
+// Close releases the connection pool
+func (s sushiRepository) Close() error {
+	return s.pool.Close()
+}
@@ -214,6 +214,63 @@ func Test_SushiRepository_GetSushiByID_Succeeded(t *testing.T) {
 	assert.Equal(t, &expectedSushi, sushi)
 }
 
+func Test_SushiRepository_Ping_RepositoryError(t *testing.T) {
+	conn := redigomock.NewConn()
+	conn.Command("PING").ExpectError(errors.New("something failed"))
+
+	repo := NewRepository(wrapRedisConn(conn)).(sushiapi.Pinger)
+	err := repo.Ping(context.Background())
+
+	assert.Error(t, err)
+	assert.NoError(t, conn.ExpectationsWereMet())
+}
+
+func Test_SushiRepository_Ping_Success(t *testing.T) {
+	conn := redigomock.NewConn()
+	conn.Command("PING").Expect("PONG")
+
+	repo := NewRepository(wrapRedisConn(conn)).(sushiapi.Pinger)
+	err := repo.Ping(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoError(t, conn.ExpectationsWereMet())
+}
+
+func Test_SushiRepository_StreamSushis_Succeeded(t *testing.T) {
+	sushiA, sushiB := buildSushi("01D3XZ38KDR"), buildSushi("01D3XZ38TRE")
+
+	conn := redigomock.NewConn()
+	conn.Command("SCAN", 0, "COUNT", scanCount).Expect([]interface{}{[]byte("7"), []interface{}{[]byte(sushiA.ID)}})
+	conn.Command("MGET", []byte(sushiA.ID)).Expect([]interface{}{[]byte(sushiToJSONString(sushiA))})
+	conn.Command("SCAN", 7, "COUNT", scanCount).Expect([]interface{}{[]byte("0"), []interface{}{[]byte(sushiB.ID), []byte("removed")}})
+	conn.Command("MGET", []byte(sushiB.ID), []byte("removed")).Expect([]interface{}{[]byte(sushiToJSONString(sushiB)), nil})
+
+	repo := NewRepository(wrapRedisConn(conn)).(sushiapi.Streamer)
+	var sushis []sushiapi.Sushi
+	for sushi, err := range repo.StreamSushis(context.Background()) {
+		assert.NoError(t, err)
+		sushis = append(sushis, sushi)
+	}
+
+	assert.NoError(t, conn.ExpectationsWereMet())
+	assert.Equal(t, []sushiapi.Sushi{sushiA, sushiB}, sushis)
+}
+
+func Test_SushiRepository_StreamSushis_RepositoryError(t *testing.T) {
+	conn := redigomock.NewConn()
+	conn.Command("SCAN", 0, "COUNT", scanCount).ExpectError(errors.New("something failed"))
+
+	repo := NewRepository(wrapRedisConn(conn)).(sushiapi.Streamer)
+	var errs []error
+	for _, err := range repo.StreamSushis(context.Background()) {
+		errs = append(errs, err)
+	}
+
+	assert.Len(t, errs, 1)
+	assert.Error(t, errs[0])
+	assert.NoError(t, conn.ExpectationsWereMet())
+}
+
 func buildSushi(ID string) sushiapi.Sushi {
 	return sushiapi.Sushi{
 		ID:    ID,
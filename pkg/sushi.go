@@ -2,6 +2,9 @@ package sushi
 
 import (
 	"context"
+	"errors"
+	"iter"
+	"regexp"
 	"time"
 )
 
@@ -11,6 +14,9 @@ type Sushi struct {
 	ImageNumber string 		`json:"imageNumber,omitempty"`
 	Name        string 		`json:"name,omitempty"`
 	Ingredients []string 	`json:"ingredients,omitempty"`
+	Pricing
+	// Labels are computed by the getting service, nil otherwise
+	Labels		*Labels		`json:"labels,omitempty"`
 	CreatedAt 	*time.Time `json:"-"`
 	UpdatedAt 	*time.Time `json:"-"`
 }
@@ -25,6 +31,16 @@ func New(ID, ImageNumber, Name string, Ingredients []string) *Sushi {
 	}
 }
 
+// ErrInvalidSushi is returned when a sushi doesn't satisfy the menu rules
+var ErrInvalidSushi = errors.New("invalid sushi")
+
+var idPattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// Validate checks the sushi can be listed in the menu
+func (s *Sushi) Validate() error {
+	return s.Pricing.Validate()
+}
+
 // Repository provides access to the sushi storage
 type Repository interface {
 	CreateSushi(ctx context.Context, s *Sushi) error
@@ -33,3 +49,74 @@ type Repository interface {
 	UpdateSushi(ctx context.Context, ID string, s *Sushi) error
 	GetSushiByID(ctx context.Context, ID string) (*Sushi, error)
 }
+
+// Pinger is implemented by the repositories able to check their connection
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Streamer is implemented by the repositories able to yield the sushis one at
+// a time, keeping memory flat however large the catalogue is
+type Streamer interface {
+	StreamSushis(ctx context.Context) iter.Seq2[Sushi, error]
+}
+
+// Stream yields the sushis of the repository, one at a time when it's a
+// Streamer and from GetSushis otherwise. The iteration stops after the first
+// error.
+func Stream(ctx context.Context, repository Repository) iter.Seq2[Sushi, error] {
+	if streamer, ok := repository.(Streamer); ok {
+		return streamer.StreamSushis(ctx)
+	}
+	return func(yield func(Sushi, error) bool) {
+		sushis, err := repository.GetSushis(ctx)
+		if err != nil {
+			yield(Sushi{}, err)
+			return
+		}
+		for _, s := range sushis {
+			if !yield(s, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Version identifies a state of the catalogue
+type Version struct {
+	// Tag is opaque and changes with every write
+	Tag string
+	// Modified is the time of the latest write, zero when unknown
+	Modified time.Time
+}
+
+// ErrUnversioned is returned when the repository can't tell its version
+var ErrUnversioned = errors.New("the repository isn't versioned")
+
+// Versioner is implemented by the repositories able to tell the version of
+// the catalogue without reading it
+type Versioner interface {
+	Version(ctx context.Context) (Version, error)
+}
+
+// CurrentVersion returns the version of the repository, or ErrUnversioned
+// when it isn't a Versioner
+func CurrentVersion(ctx context.Context, repository Repository) (Version, error) {
+	if versioner, ok := repository.(Versioner); ok {
+		return versioner.Version(ctx)
+	}
+	return Version{}, ErrUnversioned
+}
+
+// LastModified returns the latest of the creation and update times of the
+// sushi, zero when it has none
+func (s *Sushi) LastModified() time.Time {
+	var modified time.Time
+	if s.CreatedAt != nil {
+		modified = *s.CreatedAt
+	}
+	if s.UpdatedAt != nil && s.UpdatedAt.After(modified) {
+		modified = *s.UpdatedAt
+	}
+	return modified
+}
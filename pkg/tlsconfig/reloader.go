@@ -0,0 +1,161 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves the certificate and client CAs found in the given files,
+// loading them again whenever they change on disk so certificates can be
+// rotated without restarting the server
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mtx       sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// NewReloader loads the certificate, key and optional client CA bundle
+func NewReloader(certFile, keyFile, clientCAFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		modTimes:     make(map[string]time.Time),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the files again when any of them changed since the last load
+func (r *Reloader) Reload() error {
+	changed, err := r.changed()
+	if err != nil || !changed {
+		return err
+	}
+	return r.load()
+}
+
+// Watch checks the files every interval until ctx is done, errors are
+// reported to onError and the previous certificate keeps being served
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Reload(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Config returns a TLS configuration that always uses the last loaded files.
+// Client certificates are verified only when a client CA bundle was given.
+// HTTP/2 and HTTP/1.1 are offered through ALPN, gRPC requiring HTTP/2.
+func (r *Reloader) Config(clientAuth tls.ClientAuthType) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.certificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mtx.RLock()
+		defer r.mtx.RUnlock()
+
+		// the configuration of the handshake replaces cfg, it must keep its
+		// protocols
+		clientCfg := &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{*r.cert},
+			NextProtos:   cfg.NextProtos,
+		}
+		if r.clientCAs != nil {
+			clientCfg.ClientAuth = clientAuth
+			clientCfg.ClientCAs = r.clientCAs
+		}
+		return clientCfg, nil
+	}
+	return cfg
+}
+
+func (r *Reloader) certificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.cert, nil
+}
+
+func (r *Reloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.New("no certificate found in the client CA bundle")
+		}
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
+
+func (r *Reloader) changed() (bool, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
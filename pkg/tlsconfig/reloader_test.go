@@ -0,0 +1,174 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type keyPair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newKeyPair(t *testing.T, cn string, parent *keyPair, isCA bool) *keyPair {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn, Organization: []string{"Sushi"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := tpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return &keyPair{cert: cert, key: key, der: der}
+}
+
+func (k *keyPair) write(t *testing.T, certFile, keyFile string) {
+	keyDER, err := x509.MarshalECPrivateKey(k.key)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: k.der}), 0600))
+	if keyFile != "" {
+		assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	}
+}
+
+func (k *keyPair) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{k.der}, PrivateKey: k.key}
+}
+
+func Test_Reloader_MutualTLSAndRotation(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+
+	ca := newKeyPair(t, "Sushi CA", nil, true)
+	ca.write(t, caFile, "")
+	first := newKeyPair(t, "first", ca, false)
+	first.write(t, certFile, keyFile)
+	client := newKeyPair(t, "kiosk-42", ca, false)
+
+	reloader, err := NewReloader(certFile, keyFile, caFile)
+	assert.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.CommonName))
+	}))
+	srv.TLS = reloader.Config(tls.RequireAndVerifyClientCert)
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(clientCerts ...tls.Certificate) (*http.Response, error) {
+		c := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: clientCerts},
+			DisableKeepAlives: true,
+		}}
+		return c.Get(srv.URL)
+	}
+
+	res, err := get(client.tlsCertificate())
+	assert.NoError(t, err)
+	assert.Equal(t, "first", res.TLS.PeerCertificates[0].Subject.CommonName)
+	res.Body.Close()
+
+	_, err = get()
+	assert.Error(t, err, "a client certificate is required")
+
+	// rotate the server certificate
+	second := newKeyPair(t, "second", ca, false)
+	second.write(t, certFile, keyFile)
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(certFile, future, future))
+	assert.NoError(t, reloader.Reload())
+
+	res, err = get(client.tlsCertificate())
+	assert.NoError(t, err)
+	assert.Equal(t, "second", res.TLS.PeerCertificates[0].Subject.CommonName)
+	res.Body.Close()
+}
+
+func Test_Reloader_NegotiatesHTTP2(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+
+	ca := newKeyPair(t, "Sushi CA", nil, true)
+	ca.write(t, caFile, "")
+	newKeyPair(t, "server", ca, false).write(t, certFile, keyFile)
+	client := newKeyPair(t, "kiosk-42", ca, false)
+
+	reloader, err := NewReloader(certFile, keyFile, caFile)
+	assert.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.CommonName))
+	}))
+	srv.EnableHTTP2 = true
+	srv.TLS = reloader.Config(tls.RequireAndVerifyClientCert)
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	c := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{client.tlsCertificate()}},
+		ForceAttemptHTTP2: true,
+	}}
+	res, err := c.Get(srv.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer res.Body.Close()
+	assert.Equal(t, 2, res.ProtoMajor)
+	assert.Equal(t, "h2", res.TLS.NegotiatedProtocol)
+}
+
+func Test_Reloader_KeepsServingOnInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	ca := newKeyPair(t, "Sushi CA", nil, true)
+	ca.write(t, certFile, keyFile)
+	reloader, err := NewReloader(certFile, keyFile, "")
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0600))
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(certFile, future, future))
+
+	assert.Error(t, reloader.Reload())
+	cert, err := reloader.certificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ca.der, cert.Certificate[0])
+}
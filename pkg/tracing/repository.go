@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+)
+
+type repository struct {
+	next    sushi.Repository
+	backend string
+	tracer  trace.Tracer
+}
+
+// NewRepository decorates a sushi.Repository creating a client span for
+// every operation, labelled with the given backend name
+func NewRepository(next sushi.Repository, backend string) sushi.Repository {
+	return &repository{
+		next:    next,
+		backend: backend,
+		tracer:  otel.Tracer("github.com/sergiorra/sushi-api-go/pkg/tracing"),
+	}
+}
+
+// CreateSushi satisfies the sushi.Repository interface
+func (r *repository) CreateSushi(ctx context.Context, s *sushi.Sushi) error {
+	ctx, span := r.start(ctx, "CreateSushi")
+	defer span.End()
+	err := r.next.CreateSushi(ctx, s)
+	fail(span, err)
+	return err
+}
+
+// GetSushis satisfies the sushi.Repository interface
+func (r *repository) GetSushis(ctx context.Context) ([]sushi.Sushi, error) {
+	ctx, span := r.start(ctx, "GetSushis")
+	defer span.End()
+	sushis, err := r.next.GetSushis(ctx)
+	fail(span, err)
+	return sushis, err
+}
+
+// StreamSushis satisfies the sushi.Streamer interface, the span lasts the
+// whole iteration
+func (r *repository) StreamSushis(ctx context.Context) iter.Seq2[sushi.Sushi, error] {
+	return func(yield func(sushi.Sushi, error) bool) {
+		ctx, span := r.start(ctx, "StreamSushis")
+		defer span.End()
+		for s, err := range sushi.Stream(ctx, r.next) {
+			fail(span, err)
+			if !yield(s, err) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteSushi satisfies the sushi.Repository interface
+func (r *repository) DeleteSushi(ctx context.Context, ID string) error {
+	ctx, span := r.start(ctx, "DeleteSushi")
+	defer span.End()
+	err := r.next.DeleteSushi(ctx, ID)
+	fail(span, err)
+	return err
+}
+
+// UpdateSushi satisfies the sushi.Repository interface
+func (r *repository) UpdateSushi(ctx context.Context, ID string, s *sushi.Sushi) error {
+	ctx, span := r.start(ctx, "UpdateSushi")
+	defer span.End()
+	err := r.next.UpdateSushi(ctx, ID, s)
+	fail(span, err)
+	return err
+}
+
+// GetSushiByID satisfies the sushi.Repository interface
+func (r *repository) GetSushiByID(ctx context.Context, ID string) (*sushi.Sushi, error) {
+	ctx, span := r.start(ctx, "GetSushiByID")
+	defer span.End()
+	s, err := r.next.GetSushiByID(ctx, ID)
+	fail(span, err)
+	return s, err
+}
+
+// Version satisfies the sushi.Versioner interface when the decorated
+// repository does, returning sushi.ErrUnversioned otherwise
+func (r *repository) Version(ctx context.Context) (sushi.Version, error) {
+	if _, ok := r.next.(sushi.Versioner); !ok {
+		return sushi.Version{}, sushi.ErrUnversioned
+	}
+	ctx, span := r.start(ctx, "Version")
+	defer span.End()
+	version, err := sushi.CurrentVersion(ctx, r.next)
+	if !errors.Is(err, sushi.ErrUnversioned) {
+		fail(span, err)
+	}
+	return version, err
+}
+
+func (r *repository) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return r.tracer.Start(ctx, "repository."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameKey.String(r.backend),
+			semconv.DBOperationName(operation),
+		),
+	)
+}
+
+// fail marks the span as failed when err is not nil
+func fail(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// Fail marks the span found in ctx as failed when err is not nil
+func Fail(ctx context.Context, err error) {
+	fail(trace.SpanFromContext(ctx), err)
+}
+
+// Ping satisfies the sushi.Pinger interface when the decorated repository does
+func (r *repository) Ping(ctx context.Context) error {
+	if pinger, ok := r.next.(sushi.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Close releases the decorated repository when it holds resources
+func (r *repository) Close() error {
+	if closer, ok := r.next.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
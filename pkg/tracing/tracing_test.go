@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/storage/inmem"
+)
+
+// collector is a minimal in-process OTLP/HTTP collector
+type collector struct {
+	mtx   sync.Mutex
+	spans []string
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := &coltracepb.ExportTraceServiceRequest{}
+	if r.URL.Path != "/v1/traces" || proto.Unmarshal(body, req) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, span := range ss.Spans {
+				c.spans = append(c.spans, span.Name)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	out, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	_, _ = w.Write(out)
+}
+
+func Test_NewProvider_OTLP(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	tp, err := NewProvider(context.Background(), Config{
+		ServiceName: "test",
+		Exporter:    ExporterOTLP,
+		Endpoint:    strings.TrimPrefix(srv.URL, "http://"),
+		Insecure:    true,
+	})
+	assert.NoError(t, err)
+
+	_, span := tp.Tracer("test").Start(context.Background(), "GET /sushi")
+	span.End()
+	assert.NoError(t, tp.Shutdown(context.Background()))
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	assert.Equal(t, []string{"GET /sushi"}, c.spans)
+}
+
+func Test_NewProvider_UnknownExporter(t *testing.T) {
+	_, err := NewProvider(context.Background(), Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
+
+func Test_Repository_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	repo := &repository{next: inmem.NewRepository(nil), backend: "inmem", tracer: tp.Tracer("test")}
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	s := sushi.New("01D3XZ38KDR", "1", "California Roll", nil)
+	assert.NoError(t, repo.CreateSushi(ctx, s))
+	assert.Error(t, repo.CreateSushi(ctx, s))
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, "repository.CreateSushi", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	assert.Equal(t, "Unset", spans[0].Status().Code.String())
+	assert.Equal(t, "Error", spans[1].Status().Code.String())
+}
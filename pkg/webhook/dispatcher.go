@@ -0,0 +1,414 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+)
+
+// Config tunes the deliveries
+type Config struct {
+	// Workers is the number of deliveries sent concurrently
+	Workers int
+	// QueueSize is the number of deliveries waiting for a worker, the events
+	// overflowing it are dead-lettered
+	QueueSize int
+	// Timeout bounds every delivery attempt
+	Timeout time.Duration
+	// Attempts is the number of attempts before dead-lettering an event, the
+	// wait between them doubles from InitialBackoff up to MaxBackoff
+	Attempts       int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// HistorySize is the number of attempts kept per subscription
+	HistorySize int
+	// DeadLetterSize is the number of dead letters kept
+	DeadLetterSize int
+}
+
+// DefaultConfig retries for about half an hour
+func DefaultConfig() Config {
+	return Config{
+		Workers:        4,
+		QueueSize:      1000,
+		Timeout:        5 * time.Second,
+		Attempts:       8,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Minute,
+		HistorySize:    100,
+		DeadLetterSize: 1000,
+	}
+}
+
+type job struct {
+	// ctx carries the values of the request that produced the event
+	ctx          context.Context
+	subscription Subscription
+	event        events.Event
+	body         []byte
+	attempt      int
+}
+
+// Dispatcher delivers the published events to the subscriptions matching
+// them. The subscriptions, the history and the dead letters are kept in
+// memory, so they are lost on restart.
+type Dispatcher struct {
+	cfg    Config
+	client *http.Client
+	logger log.Logger
+	now    func() time.Time
+
+	mtx           sync.RWMutex
+	subscriptions map[string]Subscription
+	history       map[string][]Delivery
+	deadLetters   []DeadLetter
+	closed        bool
+
+	jobs    chan job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	pending sync.WaitGroup
+	workers sync.WaitGroup
+}
+
+// NewDispatcher creates a Dispatcher and starts its workers, Close stops them
+func NewDispatcher(cfg Config, client *http.Client, logger log.Logger) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		cfg:           cfg,
+		client:        client,
+		logger:        logger,
+		now:           time.Now,
+		subscriptions: make(map[string]Subscription),
+		history:       make(map[string][]Delivery),
+		jobs:          make(chan job, cfg.QueueSize),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		d.workers.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// Publish satisfies the events.Publisher interface, the event is queued for
+// every subscription matching it
+func (d *Dispatcher) Publish(ctx context.Context, event events.Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.logger.UnexpectedError(ctx, err)
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	var overflow []job
+	d.mtx.RLock()
+	if d.closed {
+		d.mtx.RUnlock()
+		return
+	}
+	for _, s := range d.subscriptions {
+		if !s.Matches(event.Type) {
+			continue
+		}
+		j := job{ctx: ctx, subscription: s, event: event, body: body, attempt: 1}
+		d.pending.Add(1)
+		select {
+		case d.jobs <- j:
+		default:
+			overflow = append(overflow, j)
+		}
+	}
+	d.mtx.RUnlock()
+
+	for _, j := range overflow {
+		d.deadLetter(j, 0, "the delivery queue is full")
+	}
+}
+
+// Subscribe satisfies the Service interface
+func (d *Dispatcher) Subscribe(ctx context.Context, s Subscription) (Subscription, error) {
+	if err := s.Validate(); err != nil {
+		return Subscription{}, err
+	}
+	s.ID = randomHex(16)
+	if s.Secret == "" {
+		s.Secret = randomHex(32)
+	}
+	s.CreatedAt = d.now().UTC()
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.subscriptions[s.ID] = s
+	return s, nil
+}
+
+// Subscriptions satisfies the Service interface
+func (d *Dispatcher) Subscriptions(ctx context.Context) []Subscription {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	subscriptions := make([]Subscription, 0, len(d.subscriptions))
+	for _, s := range d.subscriptions {
+		s.Secret = ""
+		subscriptions = append(subscriptions, s)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].ID < subscriptions[j].ID
+		}
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions
+}
+
+// Subscription satisfies the Service interface
+func (d *Dispatcher) Subscription(ctx context.Context, ID string) (Subscription, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	s, ok := d.subscriptions[ID]
+	if !ok {
+		return Subscription{}, ErrNotFound
+	}
+	s.Secret = ""
+	return s, nil
+}
+
+// Unsubscribe satisfies the Service interface
+func (d *Dispatcher) Unsubscribe(ctx context.Context, ID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.subscriptions[ID]; !ok {
+		return ErrNotFound
+	}
+	delete(d.subscriptions, ID)
+	delete(d.history, ID)
+	return nil
+}
+
+// Deliveries satisfies the Service interface
+func (d *Dispatcher) Deliveries(ctx context.Context, ID string) ([]Delivery, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	if _, ok := d.subscriptions[ID]; !ok {
+		return nil, ErrNotFound
+	}
+	return append([]Delivery{}, d.history[ID]...), nil
+}
+
+// DeadLetters satisfies the Service interface
+func (d *Dispatcher) DeadLetters(ctx context.Context) []DeadLetter {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	return append([]DeadLetter{}, d.deadLetters...)
+}
+
+// Close stops taking events and waits for the pending deliveries, including
+// their retries, until ctx is done. The deliveries still pending then are
+// dead-lettered.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mtx.Lock()
+	d.closed = true
+	d.mtx.Unlock()
+
+	delivered := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(delivered)
+	}()
+
+	var err error
+	select {
+	case <-delivered:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	d.cancel()
+	d.workers.Wait()
+	d.drain()
+	<-delivered
+	return err
+}
+
+func (d *Dispatcher) work() {
+	defer d.workers.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case j := <-d.jobs:
+			d.deliver(j)
+		}
+	}
+}
+
+// drain dead-letters the jobs left in the queue once the workers are gone
+func (d *Dispatcher) drain() {
+	for {
+		select {
+		case j := <-d.jobs:
+			d.deadLetter(j, j.attempt-1, "the dispatcher was closed")
+		default:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(j job) {
+	if !d.subscribed(j.subscription.ID) {
+		d.pending.Done()
+		return
+	}
+
+	start := d.now()
+	status, err := d.send(j)
+	delivery := Delivery{
+		SubscriptionID: j.subscription.ID,
+		EventID:        j.event.ID,
+		EventType:      j.event.Type,
+		Attempt:        j.attempt,
+		Status:         status,
+		Time:           start.UTC(),
+		Duration:       d.now().Sub(start),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	d.record(delivery)
+
+	switch {
+	case err == nil:
+		d.pending.Done()
+	case j.attempt >= d.cfg.Attempts:
+		d.deadLetter(j, j.attempt, delivery.Error)
+	default:
+		go d.retry(j)
+	}
+}
+
+// retry queues the job again once its backoff has elapsed
+func (d *Dispatcher) retry(j job) {
+	timer := time.NewTimer(d.backoff(j.attempt))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-d.ctx.Done():
+		d.deadLetter(j, j.attempt, "the dispatcher was closed")
+		return
+	}
+
+	attempts := j.attempt
+	j.attempt++
+	select {
+	case d.jobs <- j:
+	case <-d.ctx.Done():
+		d.deadLetter(j, attempts, "the dispatcher was closed")
+	}
+}
+
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempt && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.MaxBackoff {
+		delay = d.cfg.MaxBackoff
+	}
+	return delay
+}
+
+// send posts the event, any status but 2xx is a failure
+func (d *Dispatcher) send(j job) (int, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.subscription.URL, bytes.NewReader(j.body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sushi-api-webhooks")
+	req.Header.Set(EventHeader, string(j.event.Type))
+	// the ID of the event is kept across attempts so receivers can discard duplicates
+	req.Header.Set(DeliveryHeader, j.event.ID)
+	req.Header.Set(SignatureHeader, Sign(j.subscription.Secret, d.now(), j.body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+func (d *Dispatcher) subscribed(ID string) bool {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	_, ok := d.subscriptions[ID]
+	return ok
+}
+
+func (d *Dispatcher) record(delivery Delivery) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	// the subscription may have been removed while delivering
+	if _, ok := d.subscriptions[delivery.SubscriptionID]; !ok {
+		return
+	}
+	history := append(d.history[delivery.SubscriptionID], delivery)
+	if len(history) > d.cfg.HistorySize {
+		history = history[len(history)-d.cfg.HistorySize:]
+	}
+	d.history[delivery.SubscriptionID] = history
+}
+
+// deadLetter gives up on the job after the given number of attempts
+func (d *Dispatcher) deadLetter(j job, attempts int, reason string) {
+	defer d.pending.Done()
+	d.logger.WebhookFailed(j.ctx, j.subscription.ID, fmt.Errorf("event %s dead-lettered after %d attempts: %s", j.event.ID, attempts, reason))
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.deadLetters = append(d.deadLetters, DeadLetter{
+		SubscriptionID: j.subscription.ID,
+		URL:            j.subscription.URL,
+		Event:          j.event,
+		Attempts:       attempts,
+		Error:          reason,
+		Time:           d.now().UTC(),
+	})
+	if len(d.deadLetters) > d.cfg.DeadLetterSize {
+		d.deadLetters = d.deadLetters[len(d.deadLetters)-d.cfg.DeadLetterSize:]
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,182 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sushi "github.com/sergiorra/sushi-api-go/pkg"
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+	"github.com/sergiorra/sushi-api-go/pkg/log"
+)
+
+// receiver is a webhook endpoint failing its first requests
+type receiver struct {
+	mtx      sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mtx.Lock()
+	defer rc.mtx.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	if rc.failures > 0 {
+		rc.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rc *receiver) count() int {
+	rc.mtx.Lock()
+	defer rc.mtx.Unlock()
+	return len(rc.requests)
+}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = 4 * time.Millisecond
+	cfg.Attempts = 3
+	return cfg
+}
+
+func Test_Dispatcher_Deliver(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	d := NewDispatcher(testConfig(), srv.Client(), log.NewNoopLogger())
+	ctx := context.Background()
+	s, err := d.Subscribe(ctx, Subscription{URL: srv.URL, Events: []events.Type{events.SushiCreated}})
+	require.NoError(t, err)
+	assert.Len(t, s.Secret, 64, "a secret is generated")
+
+	event := events.New(events.SushiCreated, "01D3XZ38KDR", &sushi.Sushi{ID: "01D3XZ38KDR", Name: "Nigiri"})
+	d.Publish(ctx, event)
+	d.Publish(ctx, events.New(events.SushiRemoved, "01D3XZ38KDR", nil))
+	require.NoError(t, d.Close(ctx))
+
+	require.Equal(t, 1, rc.count(), "only the subscribed events are delivered")
+	req := rc.requests[0]
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, string(events.SushiCreated), req.Header.Get(EventHeader))
+	assert.Equal(t, event.ID, req.Header.Get(DeliveryHeader))
+	assert.NoError(t, Verify(s.Secret, req.Header.Get(SignatureHeader), rc.bodies[0], time.Minute, time.Now()))
+	assert.Contains(t, string(rc.bodies[0]), `"type":"sushi.created"`)
+
+	deliveries, err := d.Deliveries(ctx, s.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.True(t, deliveries[0].Succeeded())
+	assert.Equal(t, http.StatusNoContent, deliveries[0].Status)
+}
+
+func Test_Dispatcher_Retry(t *testing.T) {
+	rc := &receiver{failures: 2}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	d := NewDispatcher(testConfig(), srv.Client(), log.NewNoopLogger())
+	ctx := context.Background()
+	s, _ := d.Subscribe(ctx, Subscription{URL: srv.URL})
+
+	d.Publish(ctx, events.New(events.SushiModified, "01D3XZ38KDR", nil))
+	require.NoError(t, d.Close(ctx))
+
+	assert.Equal(t, 3, rc.count())
+	deliveries, _ := d.Deliveries(ctx, s.ID)
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, []int{1, 2, 3}, []int{deliveries[0].Attempt, deliveries[1].Attempt, deliveries[2].Attempt})
+	assert.Equal(t, "unexpected status 503", deliveries[0].Error)
+	assert.True(t, deliveries[2].Succeeded())
+	assert.Empty(t, d.DeadLetters(ctx))
+}
+
+func Test_Dispatcher_DeadLetter(t *testing.T) {
+	rc := &receiver{failures: 10}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	d := NewDispatcher(testConfig(), srv.Client(), log.NewNoopLogger())
+	ctx := context.Background()
+	s, _ := d.Subscribe(ctx, Subscription{URL: srv.URL})
+
+	event := events.New(events.SushiRemoved, "01D3XZ38KDR", nil)
+	d.Publish(ctx, event)
+	require.NoError(t, d.Close(ctx))
+
+	assert.Equal(t, 3, rc.count(), "the attempts are exhausted")
+	deadLetters := d.DeadLetters(ctx)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, s.ID, deadLetters[0].SubscriptionID)
+	assert.Equal(t, event.ID, deadLetters[0].Event.ID)
+	assert.Equal(t, 3, deadLetters[0].Attempts)
+	assert.Equal(t, "unexpected status 503", deadLetters[0].Error)
+}
+
+func Test_Dispatcher_CloseDeadLettersPending(t *testing.T) {
+	rc := &receiver{failures: 10}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	cfg := testConfig()
+	cfg.InitialBackoff, cfg.MaxBackoff = time.Hour, time.Hour
+	d := NewDispatcher(cfg, srv.Client(), log.NewNoopLogger())
+	ctx := context.Background()
+	_, _ = d.Subscribe(ctx, Subscription{URL: srv.URL})
+
+	d.Publish(ctx, events.New(events.SushiCreated, "01D3XZ38KDR", nil))
+	assert.Eventually(t, func() bool { return rc.count() == 1 }, time.Second, time.Millisecond)
+
+	closeCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.Close(closeCtx), context.DeadlineExceeded)
+
+	deadLetters := d.DeadLetters(ctx)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, 1, deadLetters[0].Attempts)
+	assert.Equal(t, "the dispatcher was closed", deadLetters[0].Error)
+
+	d.Publish(ctx, events.New(events.SushiCreated, "01D3XZ38KDR", nil))
+	assert.Equal(t, 1, rc.count(), "a closed dispatcher drops the events")
+}
+
+func Test_Dispatcher_Subscriptions(t *testing.T) {
+	d := NewDispatcher(testConfig(), http.DefaultClient, log.NewNoopLogger())
+	defer d.Close(context.Background())
+	ctx := context.Background()
+
+	_, err := d.Subscribe(ctx, Subscription{URL: "menu-board"})
+	assert.ErrorIs(t, err, ErrInvalidSubscription)
+
+	s, err := d.Subscribe(ctx, Subscription{URL: "https://pos.example.com/hooks", Secret: "s3cr3t"})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", s.Secret)
+
+	listed := d.Subscriptions(ctx)
+	require.Len(t, listed, 1)
+	assert.Empty(t, listed[0].Secret, "secrets aren't listed")
+
+	got, err := d.Subscription(ctx, s.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Secret)
+
+	require.NoError(t, d.Unsubscribe(ctx, s.ID))
+	assert.ErrorIs(t, d.Unsubscribe(ctx, s.ID), ErrNotFound)
+	_, err = d.Deliveries(ctx, s.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
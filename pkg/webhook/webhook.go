@@ -0,0 +1,154 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+)
+
+// Headers of the deliveries
+const (
+	EventHeader     = "X-Sushi-Event"
+	DeliveryHeader  = "X-Sushi-Delivery"
+	SignatureHeader = "X-Sushi-Signature"
+)
+
+var (
+	// ErrInvalidSubscription is returned for subscriptions that can't be registered
+	ErrInvalidSubscription = errors.New("invalid subscription")
+	// ErrNotFound is returned for unknown subscriptions
+	ErrNotFound = errors.New("subscription not found")
+	// ErrInvalidSignature is returned by Verify for deliveries not signed with the secret
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Subscription registers a URL receiving the events of the given types, or
+// every event when no type is given
+type Subscription struct {
+	ID     string        `json:"id"`
+	URL    string        `json:"url"`
+	Events []events.Type `json:"events,omitempty"`
+	// Secret signs the deliveries, it is only returned on registration
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate checks the subscription can be registered
+func (s Subscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url %q must be an absolute http or https URL", ErrInvalidSubscription, s.URL)
+	}
+	for _, t := range s.Events {
+		if !t.Valid() {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidSubscription, t)
+		}
+	}
+	return nil
+}
+
+// Matches tells whether the subscription receives the events of the type
+func (s Subscription) Matches(t events.Type) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range s.Events {
+		if subscribed == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is an attempt to deliver an event to a subscription
+type Delivery struct {
+	SubscriptionID string        `json:"subscriptionId"`
+	EventID        string        `json:"eventId"`
+	EventType      events.Type   `json:"eventType"`
+	Attempt        int           `json:"attempt"`
+	Status         int           `json:"status,omitempty"`
+	Error          string        `json:"error,omitempty"`
+	Time           time.Time     `json:"time"`
+	Duration       time.Duration `json:"durationNs"`
+}
+
+// Succeeded tells whether the receiver acknowledged the event
+func (d Delivery) Succeeded() bool {
+	return d.Error == ""
+}
+
+// DeadLetter is an event that couldn't be delivered to a subscription
+type DeadLetter struct {
+	SubscriptionID string       `json:"subscriptionId"`
+	URL            string       `json:"url"`
+	Event          events.Event `json:"event"`
+	Attempts       int          `json:"attempts"`
+	Error          string       `json:"error"`
+	Time           time.Time    `json:"time"`
+}
+
+// Service manages the subscriptions and reports how their deliveries went
+type Service interface {
+	// Subscribe registers the subscription, generating its secret when missing
+	Subscribe(ctx context.Context, s Subscription) (Subscription, error)
+	// Subscriptions lists the subscriptions, without their secrets
+	Subscriptions(ctx context.Context) []Subscription
+	// Subscription returns the subscription, without its secret
+	Subscription(ctx context.Context, ID string) (Subscription, error)
+	// Unsubscribe removes the subscription, pending deliveries are dropped
+	Unsubscribe(ctx context.Context, ID string) error
+	// Deliveries returns the latest delivery attempts of the subscription, oldest first
+	Deliveries(ctx context.Context, ID string) ([]Delivery, error)
+	// DeadLetters returns the latest events that couldn't be delivered, oldest first
+	DeadLetters(ctx context.Context) []DeadLetter
+}
+
+// Sign computes the signature header of a delivery: the timestamp and the
+// HMAC-SHA256 of "timestamp.body" with the secret of the subscription
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + signature(secret, t, body)
+}
+
+// Verify checks the signature header of a delivery, rejecting the ones signed
+// more than tolerance ago to prevent replays
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || v1 == "" {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(v1), []byte(signature(secret, t, body))) {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: signed %v ago", ErrInvalidSignature, age)
+	}
+	return nil
+}
+
+func signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergiorra/sushi-api-go/pkg/events"
+)
+
+func Test_Subscription_Validate(t *testing.T) {
+	assert.NoError(t, Subscription{URL: "https://pos.example.com/hooks", Events: []events.Type{events.SushiCreated}}.Validate())
+
+	for _, s := range []Subscription{
+		{URL: "pos.example.com/hooks"},
+		{URL: "ftp://pos.example.com/hooks"},
+		{URL: "https://pos.example.com/hooks", Events: []events.Type{"sushi.eaten"}},
+	} {
+		err := s.Validate()
+		assert.True(t, errors.Is(err, ErrInvalidSubscription), "%+v: %v", s, err)
+	}
+}
+
+func Test_Subscription_Matches(t *testing.T) {
+	assert.True(t, Subscription{}.Matches(events.SushiRemoved), "no filter matches everything")
+
+	s := Subscription{Events: []events.Type{events.SushiCreated, events.SushiModified}}
+	assert.True(t, s.Matches(events.SushiModified))
+	assert.False(t, s.Matches(events.SushiRemoved))
+}
+
+func Test_SignVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1"}`)
+	header := Sign("s3cr3t", now, body)
+
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+	assert.NoError(t, Verify("s3cr3t", header, body, time.Minute, now.Add(30*time.Second)))
+	assert.ErrorIs(t, Verify("other", header, body, time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cr3t", header, []byte(`{"id":"2"}`), time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cr3t", header, body, time.Minute, now.Add(2*time.Minute)), ErrInvalidSignature, "replayed too late")
+	assert.ErrorIs(t, Verify("s3cr3t", "v1=abc", body, time.Minute, now), ErrInvalidSignature)
+}